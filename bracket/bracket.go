@@ -10,16 +10,32 @@ import (
 	"sort"
 	"time"
 
+	"github.com/BotDogs4645/da/game"
 	"github.com/BotDogs4645/da/model"
 )
 
 type Bracket struct {
 	FinalsMatchup *Matchup
-	matchupMap    map[matchupKey]*Matchup
+	// Minimum amount of time an alliance is given between the end of one match and the start of its next one.
+	MinTurnaroundSec int
+	matchupMap       map[matchupKey]*Matchup
 }
 
 const ElimMatchSpacingSec = 600
 
+// NewPlayoffBracket Creates an unpopulated bracket of the given playoff type ("single" or "double" elimination) for the
+// given number of alliances.
+func NewPlayoffBracket(elimType string, numAlliances int) (*Bracket, error) {
+	switch elimType {
+	case "single":
+		return NewSingleEliminationBracket(numAlliances)
+	case "double":
+		return NewDoubleEliminationBracket(numAlliances)
+	default:
+		return nil, fmt.Errorf("invalid playoff type: %v", elimType)
+	}
+}
+
 // Creates an unpopulated bracket with a format that is defined by the given matchup templates and number of alliances.
 func newBracket(matchupTemplates []matchupTemplate, finalsMatchupKey matchupKey, numAlliances int) (*Bracket, error) {
 	// Create a map of matchup templates by key for easy lookup while creating the bracket.
@@ -215,22 +231,90 @@ func (bracket *Bracket) Update(database *model.Database, startTime *time.Time) e
 		if err != nil {
 			return err
 		}
-		matchIndex := 0
+		availableTimes := bracket.getAllianceAvailableTimes(matches)
+		nextMatchTime := *startTime
 		for _, match := range matches {
 			if match.IsComplete() {
 				continue
 			}
-			match.Time = startTime.Add(time.Duration(matchIndex*ElimMatchSpacingSec) * time.Second)
+
+			// Push the match back if either alliance wouldn't otherwise get its minimum turnaround.
+			match.Time = nextMatchTime
+			for _, allianceId := range []int{match.ElimRedAlliance, match.ElimBlueAlliance} {
+				if availableTime, ok := availableTimes[allianceId]; ok && availableTime.After(match.Time) {
+					match.Time = availableTime
+				}
+			}
 			if err = database.UpdateMatch(&match); err != nil {
 				return err
 			}
-			matchIndex++
+			bracket.markAlliancesBusy(availableTimes, &match, match.Time)
+			nextMatchTime = match.Time.Add(ElimMatchSpacingSec * time.Second)
 		}
 	}
 
 	return nil
 }
 
+// EarliestStartTime Returns the earliest time at which the given match may start while giving both of its alliances the
+// minimum turnaround since the end of their previous completed match, or the zero time if there is no constraint.
+func (bracket *Bracket) EarliestStartTime(database *model.Database, match *model.Match) (time.Time, error) {
+	if bracket.MinTurnaroundSec <= 0 || match.Type != "elimination" {
+		return time.Time{}, nil
+	}
+	matches, err := database.GetMatchesByType("elimination")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var completedMatches []model.Match
+	for _, otherMatch := range matches {
+		if otherMatch.IsComplete() && otherMatch.Id != match.Id {
+			completedMatches = append(completedMatches, otherMatch)
+		}
+	}
+	availableTimes := bracket.getAllianceAvailableTimes(completedMatches)
+	var earliestStartTime time.Time
+	for _, allianceId := range []int{match.ElimRedAlliance, match.ElimBlueAlliance} {
+		if availableTime, ok := availableTimes[allianceId]; ok && availableTime.After(earliestStartTime) {
+			earliestStartTime = availableTime
+		}
+	}
+	return earliestStartTime, nil
+}
+
+// Returns a map of alliance ID to the earliest time that the alliance may play again given its completed matches.
+func (bracket *Bracket) getAllianceAvailableTimes(matches []model.Match) map[int]time.Time {
+	availableTimes := make(map[int]time.Time)
+	for _, match := range matches {
+		if !match.IsComplete() {
+			continue
+		}
+		startTime := match.StartedAt
+		if startTime.IsZero() {
+			startTime = match.Time
+		}
+		bracket.markAlliancesBusy(availableTimes, &match, startTime)
+	}
+	return availableTimes
+}
+
+// Records that both alliances of the given match will not be available until the minimum turnaround has elapsed after
+// the match, if it were to start at the given time.
+func (bracket *Bracket) markAlliancesBusy(availableTimes map[int]time.Time, match *model.Match, startTime time.Time) {
+	if bracket.MinTurnaroundSec <= 0 {
+		return
+	}
+	availableTime := startTime.Add(game.GetDurationToTeleopEnd()).Add(
+		time.Duration(bracket.MinTurnaroundSec) * time.Second,
+	)
+	for _, allianceId := range []int{match.ElimRedAlliance, match.ElimBlueAlliance} {
+		if allianceId > 0 && availableTime.After(availableTimes[allianceId]) {
+			availableTimes[allianceId] = availableTime
+		}
+	}
+}
+
 // ReverseRoundOrderTraversal Performs a traversal of the bracket in reverse order of rounds and invokes the given function for each visited
 // matchup.
 func (bracket *Bracket) ReverseRoundOrderTraversal(visitFunction func(*Matchup)) {
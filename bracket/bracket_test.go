@@ -27,6 +27,11 @@ func TestNewBracketErrors(t *testing.T) {
 	if assert.NotNil(t, err) {
 		assert.Equal(t, "both alliances must be populated either from selection or a lower round", err.Error())
 	}
+
+	_, err = NewPlayoffBracket("triple", 8)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "invalid playoff type: triple", err.Error())
+	}
 }
 
 func TestNewBracketInverseSeeding(t *testing.T) {
@@ -112,6 +117,43 @@ func TestBracketUpdateTiming(t *testing.T) {
 	}
 }
 
+func TestBracketUpdateTimingWithTurnaround(t *testing.T) {
+	database := setupTestDb(t)
+
+	tournament.CreateTestAlliances(database, 2)
+	bracket, err := NewSingleEliminationBracket(2)
+	assert.Nil(t, err)
+	bracket.MinTurnaroundSec = 900
+	matchDurationSec := int64(game.GetDurationToTeleopEnd().Seconds())
+	startTime := time.Unix(1000, 0)
+	assert.Nil(t, bracket.Update(database, &startTime))
+	matches, err := database.GetMatchesByType("elimination")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Equal(t, int64(1000), matches[0].Time.Unix())
+		assert.Equal(t, 1000+matchDurationSec+900, matches[1].Time.Unix())
+	}
+
+	// Check that the turnaround is measured from when the previous match actually started.
+	scoreMatch(database, "F-1", game.RedWonMatch)
+	match, _ := database.GetMatchByName("elimination", "F-1")
+	match.StartedAt = time.Unix(1100, 0)
+	assert.Nil(t, database.UpdateMatch(match))
+	startTime = time.Unix(1300, 0)
+	assert.Nil(t, bracket.Update(database, &startTime))
+	match, _ = database.GetMatchByName("elimination", "F-2")
+	assert.Equal(t, 1100+matchDurationSec+900, match.Time.Unix())
+	earliestStartTime, err := bracket.EarliestStartTime(database, match)
+	assert.Nil(t, err)
+	assert.Equal(t, 1100+matchDurationSec+900, earliestStartTime.Unix())
+
+	// Check that there is no constraint when the turnaround is disabled.
+	bracket.MinTurnaroundSec = 0
+	earliestStartTime, err = bracket.EarliestStartTime(database, match)
+	assert.Nil(t, err)
+	assert.True(t, earliestStartTime.IsZero())
+}
+
 func TestBracketUpdateTeamPositions(t *testing.T) {
 	database := setupTestDb(t)
 
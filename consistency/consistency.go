@@ -0,0 +1,404 @@
+// Functions for finding and repairing inconsistencies between the records of an event database.
+
+package consistency
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BotDogs4645/da/bracket"
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/tournament"
+)
+
+// Problem An inconsistency between the records of an event database, along with a description of how it can be
+// repaired if it can be done automatically.
+type Problem struct {
+	Key            string
+	Description    string
+	FixDescription string
+	fix            func(database *model.Database) error
+}
+
+// Check Returns the inconsistencies found between the matches, results, teams, alliances and rankings of the given
+// database, in the order in which they are best fixed. Doesn't change the database, which mustn't be in a transaction.
+func Check(database *model.Database) ([]Problem, error) {
+	matches, err := database.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, check := range []func(database *model.Database, matches []model.Match) ([]Problem, error){
+		checkMatchResults,
+		checkMatchTeams,
+		checkElimAlliances,
+		checkPlayoffBracket,
+		checkRankings,
+	} {
+		checkProblems, err := check(database, matches)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, checkProblems...)
+	}
+	return problems, nil
+}
+
+// Fix Applies the automatic fix for the problem having the given key, if it still exists.
+func Fix(database *model.Database, key string) error {
+	problems, err := Check(database)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		if problem.Key == key {
+			if problem.fix == nil {
+				return fmt.Errorf("problem '%s' can't be fixed automatically", key)
+			}
+			return database.RunInTransaction(problem.fix)
+		}
+	}
+	return fmt.Errorf("problem '%s' no longer exists", key)
+}
+
+// FixAll Applies the automatic fixes for all problems that have them, checking again after each since one fix may
+// resolve or reveal others. Returns the number of fixes applied.
+func FixAll(database *model.Database) (int, error) {
+	problems, err := Check(database)
+	if err != nil {
+		return 0, err
+	}
+
+	// Bound the number of passes in case a fix doesn't resolve its problem.
+	maxFixes := len(problems)
+	numFixes := 0
+	for numFixes < maxFixes {
+		var fixableProblem *Problem
+		for i := range problems {
+			if problems[i].fix != nil {
+				fixableProblem = &problems[i]
+				break
+			}
+		}
+		if fixableProblem == nil {
+			break
+		}
+		if err = database.RunInTransaction(fixableProblem.fix); err != nil {
+			return numFixes, err
+		}
+		numFixes++
+		if problems, err = Check(database); err != nil {
+			return numFixes, err
+		}
+	}
+	return numFixes, nil
+}
+
+// Finds results that belong to no match, and completed matches that have no result.
+func checkMatchResults(database *model.Database, matches []model.Match) ([]Problem, error) {
+	matchesById := make(map[int]model.Match, len(matches))
+	for _, match := range matches {
+		matchesById[match.Id] = match
+	}
+	matchResults, err := database.GetAllMatchResults()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	matchIdsWithResults := make(map[int]bool)
+	for _, matchResult := range matchResults {
+		if _, ok := matchesById[matchResult.MatchId]; ok {
+			matchIdsWithResults[matchResult.MatchId] = true
+			continue
+		}
+		matchResultId := matchResult.Id
+		problems = append(problems, Problem{
+			Key: fmt.Sprintf("orphaned_result_%d", matchResultId),
+			Description: fmt.Sprintf(
+				"Result %d (play %d) belongs to match %d, which doesn't exist.",
+				matchResultId, matchResult.PlayNumber, matchResult.MatchId,
+			),
+			FixDescription: "Delete the result.",
+			fix: func(database *model.Database) error {
+				return database.DeleteMatchResult(matchResultId)
+			},
+		})
+	}
+
+	for _, match := range matches {
+		if !match.IsComplete() || matchIdsWithResults[match.Id] {
+			continue
+		}
+		matchId := match.Id
+		problems = append(problems, Problem{
+			Key:            fmt.Sprintf("missing_result_%d", matchId),
+			Description:    fmt.Sprintf("%s is marked as played but has no result.", matchName(&match)),
+			FixDescription: "Mark the match as unplayed so that it can be played again.",
+			fix: func(database *model.Database) error {
+				match, err := database.GetMatchById(matchId)
+				if err != nil || match == nil {
+					return err
+				}
+				match.Status = game.MatchNotPlayed
+				match.StartedAt = time.Time{}
+				match.ScoreCommittedAt = time.Time{}
+				return database.UpdateMatch(match)
+			},
+		})
+	}
+	return problems, nil
+}
+
+// Finds teams that are assigned to matches but don't exist.
+func checkMatchTeams(database *model.Database, matches []model.Match) ([]Problem, error) {
+	teams, err := database.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	teamIds := make(map[int]bool, len(teams))
+	for _, team := range teams {
+		teamIds[team.Id] = true
+	}
+
+	missingTeamMatches := make(map[int][]string)
+	for _, match := range matches {
+		for _, teamId := range []int{match.Red1, match.Red2, match.Red3, match.Blue1, match.Blue2, match.Blue3} {
+			if teamId != 0 && !teamIds[teamId] {
+				missingTeamMatches[teamId] = append(missingTeamMatches[teamId], matchName(&match))
+			}
+		}
+	}
+	var missingTeamIds []int
+	for teamId := range missingTeamMatches {
+		missingTeamIds = append(missingTeamIds, teamId)
+	}
+	sort.Ints(missingTeamIds)
+
+	var problems []Problem
+	for _, teamId := range missingTeamIds {
+		teamId := teamId
+		problems = append(problems, Problem{
+			Key: fmt.Sprintf("missing_team_%d", teamId),
+			Description: fmt.Sprintf(
+				"Team %d is assigned to %s but doesn't exist.",
+				teamId, strings.Join(missingTeamMatches[teamId], ", "),
+			),
+			FixDescription: fmt.Sprintf(
+				"Add team %d with no details, which can then be filled in on the Teams page.", teamId,
+			),
+			fix: func(database *model.Database) error {
+				return database.CreateTeam(&model.Team{Id: teamId})
+			},
+		})
+	}
+	return problems, nil
+}
+
+// Finds elimination matches between alliances that don't exist.
+func checkElimAlliances(database *model.Database, matches []model.Match) ([]Problem, error) {
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return nil, err
+	}
+	allianceIds := make(map[int]bool, len(alliances))
+	for _, alliance := range alliances {
+		allianceIds[alliance.Id] = true
+	}
+
+	var problems []Problem
+	for _, match := range matches {
+		if match.Type != "elimination" {
+			continue
+		}
+		var missingAllianceIds []string
+		for _, allianceId := range []int{match.ElimRedAlliance, match.ElimBlueAlliance} {
+			if allianceId != 0 && !allianceIds[allianceId] {
+				missingAllianceIds = append(missingAllianceIds, fmt.Sprintf("%d", allianceId))
+			}
+		}
+		if len(missingAllianceIds) == 0 {
+			continue
+		}
+
+		problem := Problem{
+			Key: fmt.Sprintf("missing_alliance_%d", match.Id),
+			Description: fmt.Sprintf(
+				"%s is between alliances that don't exist: %s.", matchName(&match),
+				strings.Join(missingAllianceIds, ", "),
+			),
+		}
+		if !match.IsComplete() {
+			matchId := match.Id
+			problem.FixDescription = "Delete the unplayed match, which the playoff bracket recreates if still needed."
+			problem.fix = func(database *model.Database) error {
+				return database.DeleteMatch(matchId)
+			}
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// Finds differences between the elimination matches and those the playoff bracket calls for, by updating the bracket
+// in a transaction that is discarded.
+func checkPlayoffBracket(database *model.Database, _ []model.Match) ([]Problem, error) {
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return nil, err
+	}
+	if len(alliances) == 0 {
+		return nil, nil
+	}
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	err = database.RunInDiscardedTransaction(func(database *model.Database) error {
+		matchesBefore, err := database.GetMatchesByType("elimination")
+		if err != nil {
+			return err
+		}
+		playoffBracket, err := bracket.NewPlayoffBracket(eventSettings.ElimType, eventSettings.NumElimAlliances)
+		if err == nil {
+			err = playoffBracket.Update(database, nil)
+		}
+		if err != nil {
+			problems = append(problems, Problem{
+				Key:         "playoff_bracket",
+				Description: fmt.Sprintf("The playoff bracket can't be updated from the elimination matches: %v.", err),
+			})
+			return nil
+		}
+		matchesAfter, err := database.GetMatchesByType("elimination")
+		if err != nil {
+			return err
+		}
+
+		matchesBeforeById := make(map[int]model.Match, len(matchesBefore))
+		for _, match := range matchesBefore {
+			matchesBeforeById[match.Id] = match
+		}
+		var numCreated, numChanged int
+		for _, match := range matchesAfter {
+			if matchBefore, ok := matchesBeforeById[match.Id]; !ok {
+				numCreated++
+			} else if !reflect.DeepEqual(match, matchBefore) {
+				numChanged++
+			}
+			delete(matchesBeforeById, match.Id)
+		}
+		numDeleted := len(matchesBeforeById)
+		if numCreated+numChanged+numDeleted > 0 {
+			problems = append(problems, Problem{
+				Key: "playoff_bracket",
+				Description: fmt.Sprintf(
+					"The elimination matches don't match the playoff bracket, which calls for %d to be created, %d to be "+
+						"changed and %d to be deleted.",
+					numCreated, numChanged, numDeleted,
+				),
+				FixDescription: "Update the elimination matches from the playoff bracket.",
+				fix: func(database *model.Database) error {
+					playoffBracket, err := bracket.NewPlayoffBracket(
+						eventSettings.ElimType, eventSettings.NumElimAlliances,
+					)
+					if err != nil {
+						return err
+					}
+					return playoffBracket.Update(database, nil)
+				},
+			})
+		}
+		return nil
+	})
+	return problems, err
+}
+
+// Finds differences between the stored rankings and those calculated afresh from the qualification match results.
+func checkRankings(database *model.Database, _ []model.Match) ([]Problem, error) {
+	var calculatedRankings game.Rankings
+	err := database.RunInDiscardedTransaction(func(database *model.Database) error {
+		var err error
+		calculatedRankings, err = tournament.CalculateRankings(database, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	storedRankings, err := database.GetAllRankings()
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare everything but the random tiebreaker, which differs with every calculation.
+	rankingFields := make(map[int]*[2]game.RankingFields)
+	for i, rankings := range []game.Rankings{calculatedRankings, storedRankings} {
+		for _, ranking := range rankings {
+			if rankingFields[ranking.TeamId] == nil {
+				rankingFields[ranking.TeamId] = new([2]game.RankingFields)
+			}
+			fields := ranking.RankingFields
+			fields.Random = 0
+			rankingFields[ranking.TeamId][i] = fields
+		}
+	}
+	var differingTeamIds []int
+	for teamId, fields := range rankingFields {
+		if fields[0] != fields[1] {
+			differingTeamIds = append(differingTeamIds, teamId)
+		}
+	}
+	sort.Ints(differingTeamIds)
+
+	var description string
+	if len(differingTeamIds) > 0 {
+		var teams []string
+		for _, teamId := range differingTeamIds {
+			teams = append(teams, fmt.Sprintf("%d", teamId))
+		}
+		description = fmt.Sprintf(
+			"The stored rankings differ from those calculated from the qualification results for teams %s.",
+			strings.Join(teams, ", "),
+		)
+	} else if !rankingsAreOrdered(storedRankings) {
+		description = "The stored rankings are out of order."
+	} else {
+		return nil, nil
+	}
+	return []Problem{{
+		Key:            "rankings",
+		Description:    description,
+		FixDescription: "Recalculate the rankings.",
+		fix: func(database *model.Database) error {
+			_, err := tournament.CalculateRankings(database, true)
+			return err
+		},
+	}}, nil
+}
+
+// Returns whether the given rankings, sorted by rank, are numbered consecutively and in an order consistent with their
+// ranking fields, leaving aside the random tiebreaker.
+func rankingsAreOrdered(rankings game.Rankings) bool {
+	rankings = append(game.Rankings{}, rankings...)
+	for i := range rankings {
+		if rankings[i].Rank != i+1 {
+			return false
+		}
+		rankings[i].Random = 0
+		if i > 0 && rankings.Less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchName(match *model.Match) string {
+	return fmt.Sprintf("%s match %s", match.CapitalizedType(), match.DisplayName)
+}
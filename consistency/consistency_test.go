@@ -0,0 +1,126 @@
+package consistency
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/tournament"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	database := model.SetupTestDb(t, "consistency")
+	defer database.Close()
+
+	problems, err := Check(database)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+
+	for teamId := 1; teamId <= 6; teamId++ {
+		database.CreateTeam(&model.Team{Id: teamId})
+	}
+	match1 := model.Match{Type: "qualification", DisplayName: "1", Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5,
+		Blue3: 6, Status: game.RedWonMatch}
+	database.CreateMatch(&match1)
+	database.CreateMatchResult(model.BuildTestMatchResult(match1.Id, 1))
+	_, err = tournament.CalculateRankings(database, false)
+	assert.Nil(t, err)
+	problems, err = Check(database)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+
+	// Introduce one of each kind of problem outside the playoffs.
+	database.CreateMatchResult(model.BuildTestMatchResult(99, 1))
+	match2 := model.Match{Type: "qualification", DisplayName: "2", Red1: 9999, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5,
+		Blue3: 6, Status: game.BlueWonMatch}
+	database.CreateMatch(&match2)
+	rankings, _ := database.GetAllRankings()
+	rankings[0].Wins = 5
+	rankingTeamId := rankings[0].TeamId
+	database.ReplaceAllRankings(rankings)
+
+	problems, err = Check(database)
+	assert.Nil(t, err)
+	var keys []string
+	for _, problem := range problems {
+		keys = append(keys, problem.Key)
+		assert.NotEqual(t, "", problem.FixDescription)
+	}
+	assert.Equal(t, []string{"orphaned_result_2", "missing_result_2", "missing_team_9999", "rankings"}, keys)
+	if assert.Equal(t, 4, len(problems)) {
+		assert.Equal(t, "Team 9999 is assigned to Qualification match 2 but doesn't exist.", problems[2].Description)
+		assert.Equal(
+			t,
+			fmt.Sprintf(
+				"The stored rankings differ from those calculated from the qualification results for teams %d.",
+				rankingTeamId,
+			),
+			problems[3].Description,
+		)
+	}
+
+	assert.Nil(t, Fix(database, "missing_result_2"))
+	match, _ := database.GetMatchById(match2.Id)
+	assert.False(t, match.IsComplete())
+	assert.EqualError(
+		t, Fix(database, "missing_result_2"), "problem 'missing_result_2' no longer exists",
+	)
+
+	numFixes, err := FixAll(database)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, numFixes)
+	problems, err = Check(database)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+	team, _ := database.GetTeamById(9999)
+	assert.NotNil(t, team)
+	ranking, _ := database.GetRankingForTeam(rankingTeamId)
+	assert.Equal(t, 1, ranking.Wins)
+}
+
+func TestCheckPlayoffs(t *testing.T) {
+	database := model.SetupTestDb(t, "consistency")
+	defer database.Close()
+	eventSettings, _ := database.GetEventSettings()
+	eventSettings.NumElimAlliances = 2
+	database.UpdateEventSettings(eventSettings)
+	tournament.CreateTestAlliances(database, 2)
+	for _, teamId := range []int{101, 102, 103, 104, 201, 202, 203, 204} {
+		database.CreateTeam(&model.Team{Id: teamId})
+	}
+
+	// Check that the bracket is compared without creating any matches.
+	problems, err := Check(database)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(problems)) {
+		assert.Equal(t, "playoff_bracket", problems[0].Key)
+		assert.Contains(t, problems[0].Description, "calls for 2 to be created, 0 to be changed and 0 to be deleted")
+	}
+	matches, _ := database.GetMatchesByType("elimination")
+	assert.Empty(t, matches)
+
+	assert.Nil(t, Fix(database, "playoff_bracket"))
+	matches, _ = database.GetMatchesByType("elimination")
+	assert.Equal(t, 2, len(matches))
+	problems, err = Check(database)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+
+	database.DeleteAlliance(2)
+	problems, err = Check(database)
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(problems)) {
+		assert.Equal(t, "missing_alliance_1", problems[0].Key)
+		assert.Equal(t, "Playoff match F-1 is between alliances that don't exist: 2.", problems[0].Description)
+		assert.Equal(t, "missing_alliance_2", problems[1].Key)
+		assert.Equal(t, "playoff_bracket", problems[2].Key)
+		assert.Equal(t, "", problems[2].FixDescription)
+	}
+	assert.EqualError(
+		t,
+		Fix(database, "playoff_bracket"),
+		"problem 'playoff_bracket' can't be fixed automatically",
+	)
+}
@@ -8,6 +8,9 @@ package field
 import (
 	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/BotDogs4645/da/bracket"
@@ -45,19 +48,24 @@ const (
 )
 
 type Arena struct {
+	FieldId          int
 	Database         *model.Database
 	EventSettings    *model.EventSettings
+	fieldSettings    *model.FieldSettings
+	fieldArenas      []*Arena
 	accessPoint      network.AccessPoint
 	accessPoint2     network.AccessPoint
 	networkSwitch    *network.Switch
 	Plc              plc.Plc
 	TbaClient        *partner.TbaClient
+	TbaCache         *partner.TbaCache
 	AllianceStations map[string]*AllianceStation
 	Displays         map[string]*Display
 	ArenaNotifiers
 	MatchState
 	lastMatchState             MatchState
 	CurrentMatch               *model.Match
+	EarliestMatchStartTime     time.Time
 	MatchStartTime             time.Time
 	LastMatchTimeSec           float64
 	RedScore                   *game.Score
@@ -79,6 +87,9 @@ type Arena struct {
 	MuteMatchSounds            bool
 	matchAborted               bool
 	soundsPlayed               map[*game.MatchSound]struct{}
+	publishOutboxMutex         sync.Mutex
+	publishOutboxRunMutex      sync.Mutex
+	notifiedTeamMatches        *notifiedTeamMatches
 }
 
 type AllianceStation struct {
@@ -90,17 +101,66 @@ type AllianceStation struct {
 	Team     *model.Team
 }
 
-// NewArena Creates the arena and sets it to its initial state.
+// NewArena Creates the arena for the first field and sets it to its initial state.
 func NewArena(dbPath string) (*Arena, error) {
-	arena := new(Arena)
-	arena.configureNotifiers()
+	database, err := model.OpenDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	arena, err := newArena(database, partner.NewTbaCache(TbaCacheDir(dbPath)), 1)
+	if err != nil {
+		return nil, err
+	}
+	arena.notifiedTeamMatches = newNotifiedTeamMatches()
+	arena.fieldArenas = []*Arena{arena}
 
-	var err error
-	arena.Database, err = model.OpenDatabase(dbPath)
+	// Displays only ever register with the arena for the first field.
+	if err = arena.loadSavedDisplays(); err != nil {
+		return nil, err
+	}
+	return arena, nil
+}
+
+// NewArenas Creates one arena for each of the fields configured in the event settings, all sharing the database at
+// the given path and hence the event's schedule. The arena for the first field is always first. Stops short of any field
+// that doesn't have a driver station listen address of its own.
+func NewArenas(dbPath string) ([]*Arena, error) {
+	arena, err := NewArena(dbPath)
 	if err != nil {
 		return nil, err
 	}
-	err = arena.LoadSettings()
+	for fieldId := 2; fieldId <= arena.EventSettings.NumFields; fieldId++ {
+		fieldSettings, err := arena.Database.GetFieldSettings(fieldId)
+		if err != nil {
+			return nil, err
+		}
+		if fieldSettings.DsListenAddress == "" {
+			// Every field but the first needs an address of its own, or its driver station listeners would collide.
+			log.Printf("Not starting field %d or any after it since it has no driver station listen address.", fieldId)
+			break
+		}
+		fieldArena, err := newArena(arena.Database, arena.TbaCache, fieldId)
+		if err != nil {
+			return nil, err
+		}
+		fieldArena.notifiedTeamMatches = arena.notifiedTeamMatches
+		arena.fieldArenas = append(arena.fieldArenas, fieldArena)
+	}
+	for _, fieldArena := range arena.fieldArenas {
+		fieldArena.fieldArenas = arena.fieldArenas
+	}
+	return arena.fieldArenas, nil
+}
+
+// Creates the arena for the given field using the given shared database and sets it to its initial state.
+func newArena(database *model.Database, tbaCache *partner.TbaCache, fieldId int) (*Arena, error) {
+	arena := new(Arena)
+	arena.FieldId = fieldId
+	arena.configureNotifiers()
+
+	arena.Database = database
+	arena.TbaCache = tbaCache
+	err := arena.LoadSettings()
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +190,26 @@ func NewArena(dbPath string) (*Arena, error) {
 	return arena, nil
 }
 
+// FieldArenas Returns the arenas for all the fields run by this server, in field order.
+func (arena *Arena) FieldArenas() []*Arena {
+	return arena.fieldArenas
+}
+
+// FieldArena Returns the arena for the given field, or nil if there is no such field.
+func (arena *Arena) FieldArena(fieldId int) *Arena {
+	for _, fieldArena := range arena.fieldArenas {
+		if fieldArena.FieldId == fieldId {
+			return fieldArena
+		}
+	}
+	return nil
+}
+
+// TbaCacheDir Returns the directory in which TBA data is cached for the event database at the given path.
+func TbaCacheDir(dbPath string) string {
+	return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + "_tba_cache"
+}
+
 // LoadSettings Loads or reloads the event settings upon initial setup or change.
 func (arena *Arena) LoadSettings() error {
 	settings, err := arena.Database.GetEventSettings()
@@ -137,15 +217,24 @@ func (arena *Arena) LoadSettings() error {
 		return err
 	}
 	arena.EventSettings = settings
+	if arena.fieldSettings, err = arena.getFieldSettings(); err != nil {
+		return err
+	}
 
-	// Initialize the components that depend on settings.
-	arena.accessPoint.SetSettings(settings.ApAddress, settings.ApUsername, settings.ApPassword,
-		settings.ApTeamChannel, settings.ApAdminChannel, settings.ApAdminWpaKey, settings.NetworkSecurityEnabled)
-	arena.accessPoint2.SetSettings(settings.Ap2Address, settings.Ap2Username, settings.Ap2Password,
-		settings.Ap2TeamChannel, 0, "", settings.NetworkSecurityEnabled)
-	arena.networkSwitch = network.NewSwitch(settings.SwitchAddress, settings.SwitchPassword)
-	arena.Plc.SetAddress(settings.PlcAddress)
+	// Initialize the components that depend on settings. Only the first field's AP carries the admin network.
+	fieldSettings := arena.fieldSettings
+	apAdminChannel, apAdminWpaKey := 0, ""
+	if arena.FieldId == 1 {
+		apAdminChannel, apAdminWpaKey = settings.ApAdminChannel, settings.ApAdminWpaKey
+	}
+	arena.accessPoint.SetSettings(fieldSettings.ApAddress, fieldSettings.ApUsername, fieldSettings.ApPassword,
+		fieldSettings.ApTeamChannel, apAdminChannel, apAdminWpaKey, settings.NetworkSecurityEnabled)
+	arena.accessPoint2.SetSettings(fieldSettings.Ap2Address, fieldSettings.Ap2Username, fieldSettings.Ap2Password,
+		fieldSettings.Ap2TeamChannel, 0, "", settings.NetworkSecurityEnabled)
+	arena.networkSwitch = network.NewSwitch(fieldSettings.SwitchAddress, fieldSettings.SwitchPassword)
+	arena.Plc.SetAddress(fieldSettings.PlcAddress)
 	arena.TbaClient = partner.NewTbaClient(settings.TbaEventCode, settings.TbaSecretId, settings.TbaSecret)
+	arena.TbaClient.Cache = arena.TbaCache
 
 	if arena.EventSettings.NetworkSecurityEnabled && arena.MatchState == PreMatch {
 		if err = arena.accessPoint.ConfigureAdminWifi(); err != nil {
@@ -175,29 +264,144 @@ func (arena *Arena) LoadSettings() error {
 	return nil
 }
 
+// SwitchDatabase Closes the event database and opens the one at the given path in its place for every field, reloading
+// the settings, returning each field to its initial state and carrying over the saved display configurations and the
+// user accounts and sessions, as well as the admin password if the other event doesn't have one. Fails without changing
+// anything if any field has a match in progress or with results pending.
+func (arena *Arena) SwitchDatabase(dbPath string) error {
+	for _, fieldArena := range arena.fieldArenas {
+		if fieldArena.MatchState != PreMatch {
+			return fmt.Errorf(
+				"cannot switch events while field %d has a match still in progress or with results pending",
+				fieldArena.FieldId,
+			)
+		}
+	}
+	arena.LockPublishOutbox()
+	defer arena.UnlockPublishOutbox()
+
+	// Hold on to the saved display configurations so that they can be carried over, since they aren't event-specific.
+	savedDisplays, err := arena.Database.GetAllDisplays()
+	if err != nil {
+		return err
+	}
+
+	// Likewise for the user accounts and their sessions, which belong to the installation rather than to the event.
+	users, err := arena.Database.GetAllUsers()
+	if err != nil {
+		return err
+	}
+	userSessions, err := arena.Database.GetAllUserSessions()
+	if err != nil {
+		return err
+	}
+
+	database, err := model.OpenDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+	if err = database.ImportUsers(users); err == nil {
+		err = database.ImportUserSessions(userSessions)
+	}
+	if err != nil {
+		database.Close()
+		return err
+	}
+
+	// Newly created and imported events have no admin password, so keep the current one rather than switching
+	// authentication off.
+	eventSettings, err := database.GetEventSettings()
+	if err == nil && eventSettings.AdminPassword == "" && arena.EventSettings.AdminPassword != "" {
+		eventSettings.AdminPassword = arena.EventSettings.AdminPassword
+		err = database.UpdateEventSettings(eventSettings)
+	}
+	if err != nil {
+		database.Close()
+		return err
+	}
+
+	if err = arena.Database.Close(); err != nil {
+		database.Close()
+		return err
+	}
+
+	// Match IDs are only unique within an event, so forget which teams have been notified.
+	arena.notifiedTeamMatches.clear()
+	tbaCache := partner.NewTbaCache(TbaCacheDir(dbPath))
+	for _, fieldArena := range arena.fieldArenas {
+		fieldArena.Database = database
+		fieldArena.TbaCache = tbaCache
+		if err = fieldArena.LoadSettings(); err != nil {
+			return err
+		}
+		if err = fieldArena.LoadTestMatch(); err != nil {
+			return err
+		}
+		fieldArena.SavedMatch = &model.Match{}
+		fieldArena.SavedMatchResult = model.NewMatchResult()
+		fieldArena.SavedRankings = game.Rankings{}
+		fieldArena.AllianceSelectionAlliances = []model.Alliance{}
+		fieldArena.ScorePostedNotifier.Notify()
+		fieldArena.AllianceSelectionNotifier.Notify()
+		fieldArena.ReloadDisplaysNotifier.Notify()
+	}
+
+	// Displays only ever register with the arena for the first field.
+	return arena.fieldArenas[0].RestoreDisplays(savedDisplays)
+}
+
+// Returns the networking and PLC configuration of this arena's field. The first field's configuration is part of the
+// event settings, while that of any additional fields is stored separately.
+func (arena *Arena) getFieldSettings() (*model.FieldSettings, error) {
+	if arena.FieldId != 1 {
+		return arena.Database.GetFieldSettings(arena.FieldId)
+	}
+	settings := arena.EventSettings
+	return &model.FieldSettings{
+		Id:              1,
+		DsListenAddress: network.ServerIpAddress,
+		ApAddress:       settings.ApAddress,
+		ApUsername:      settings.ApUsername,
+		ApPassword:      settings.ApPassword,
+		ApTeamChannel:   settings.ApTeamChannel,
+		Ap2Address:      settings.Ap2Address,
+		Ap2Username:     settings.Ap2Username,
+		Ap2Password:     settings.Ap2Password,
+		Ap2TeamChannel:  settings.Ap2TeamChannel,
+		SwitchAddress:   settings.SwitchAddress,
+		SwitchPassword:  settings.SwitchPassword,
+		PlcAddress:      settings.PlcAddress,
+	}, nil
+}
+
 // CreatePlayoffBracket Constructs an empty playoff bracket in memory, based only on the number of alliances.
 func (arena *Arena) CreatePlayoffBracket() error {
 	var err error
-	switch arena.EventSettings.ElimType {
-	case "single":
-		arena.PlayoffBracket, err = bracket.NewSingleEliminationBracket(arena.EventSettings.NumElimAlliances)
-	case "double":
-		arena.PlayoffBracket, err = bracket.NewDoubleEliminationBracket(arena.EventSettings.NumElimAlliances)
-	default:
-		err = fmt.Errorf("invalid playoff type: %v", arena.EventSettings.ElimType)
+	arena.PlayoffBracket, err = bracket.NewPlayoffBracket(
+		arena.EventSettings.ElimType, arena.EventSettings.NumElimAlliances,
+	)
+	if err != nil {
+		return err
 	}
-	return err
+	arena.PlayoffBracket.MinTurnaroundSec = arena.EventSettings.ElimTurnaroundMin * 60
+	return nil
 }
 
 // UpdatePlayoffBracket Traverses the in-memory playoff bracket to populate alliances, create matches, and assess winners. Does nothing if
 // the bracket has not been created.are
 func (arena *Arena) UpdatePlayoffBracket(startTime *time.Time) error {
-	alliances, err := arena.Database.GetAllAlliances()
+	return arena.UpdatePlayoffBracketInTransaction(arena.Database, startTime)
+}
+
+// UpdatePlayoffBracketInTransaction Same as UpdatePlayoffBracket, but reading and writing through the given database,
+// which is in a transaction. The caller is responsible for rebuilding the in-memory bracket if the transaction fails.
+func (arena *Arena) UpdatePlayoffBracketInTransaction(database *model.Database, startTime *time.Time) error {
+	alliances, err := database.GetAllAlliances()
 	if err != nil {
 		return err
 	}
 	if len(alliances) > 0 {
-		return arena.PlayoffBracket.Update(arena.Database, startTime)
+		return arena.PlayoffBracket.Update(database, startTime)
 	}
 	return nil
 }
@@ -207,6 +411,10 @@ func (arena *Arena) LoadMatch(match *model.Match) error {
 	if arena.MatchState != PreMatch {
 		return fmt.Errorf("cannot load match while there is a match still in progress or with results pending")
 	}
+	if otherArena := arena.getArenaWithMatchLoaded(match); otherArena != nil {
+		return fmt.Errorf("cannot load match %s because it is already loaded on field %d", match.DisplayName,
+			otherArena.FieldId)
+	}
 
 	arena.CurrentMatch = match
 	err := arena.assignTeam(match.Red1, "R1")
@@ -246,11 +454,19 @@ func (arena *Arena) LoadMatch(match *model.Match) error {
 	arena.FieldReset = false
 	arena.Plc.ResetMatch()
 
+	// Determine how soon the match may start while still giving the playoff alliances their minimum turnaround.
+	arena.EarliestMatchStartTime, err = arena.PlayoffBracket.EarliestStartTime(arena.Database, match)
+	if err != nil {
+		return err
+	}
+
 	// Notify any listeners about the new match.
 	arena.MatchLoadNotifier.Notify()
+	arena.PublicMatchLoadNotifier.Notify()
 	arena.RealtimeScoreNotifier.Notify()
 	arena.AllianceStationDisplayMode = "match"
 	arena.AllianceStationDisplayModeNotifier.Notify()
+	arena.notifyUpcomingTeams()
 
 	return nil
 }
@@ -299,6 +515,7 @@ func (arena *Arena) SubstituteTeam(teamId int, station string) error {
 		arena.AllianceStations["R3"].Team, arena.AllianceStations["B1"].Team, arena.AllianceStations["B2"].Team,
 		arena.AllianceStations["B3"].Team})
 	arena.MatchLoadNotifier.Notify()
+	arena.PublicMatchLoadNotifier.Notify()
 
 	if arena.CurrentMatch.Type != "test" {
 		arena.Database.UpdateMatch(arena.CurrentMatch)
@@ -306,12 +523,70 @@ func (arena *Arena) SubstituteTeam(teamId int, station string) error {
 	return nil
 }
 
+// InvokeBackupTeam Calls the next available backup team into the given alliance of the current playoff match, in place
+// of the given team, and regenerates the alliance's unplayed matches with the new lineup.
+func (arena *Arena) InvokeBackupTeam(alliance string, replacedTeamId int) error {
+	if !arena.CurrentMatch.ShouldUpdateEliminationMatches() {
+		return fmt.Errorf("can only call in a backup team for playoff matches")
+	}
+	if arena.MatchState != PreMatch {
+		return fmt.Errorf("cannot call in a backup team while there is a match still in progress or with results " +
+			"pending")
+	}
+
+	allianceId, err := arena.getElimAllianceId(alliance)
+	if err != nil {
+		return err
+	}
+
+	backupTeamId, err := arena.Database.GetNextBackupTeamId()
+	if err != nil {
+		return err
+	}
+	if backupTeamId == 0 {
+		return fmt.Errorf("there are no backup teams available")
+	}
+	if err = arena.Database.InvokeBackupTeam(allianceId, replacedTeamId, backupTeamId,
+		arena.CurrentMatch); err != nil {
+		return err
+	}
+
+	// Regenerate the unplayed matches and reload the current one to pick up the new lineup.
+	if err = arena.UpdatePlayoffBracket(nil); err != nil {
+		return err
+	}
+	match, err := arena.Database.GetMatchById(arena.CurrentMatch.Id)
+	if err != nil {
+		return err
+	}
+	if match == nil {
+		return fmt.Errorf("match %d no longer exists", arena.CurrentMatch.Id)
+	}
+	return arena.LoadMatch(match)
+}
+
+// RefreshTeam Replaces the in-memory record of the given team in whichever alliance station it is assigned to, so that
+// changes made outside of match play (e.g. at inspection) take effect without reloading the match.
+func (arena *Arena) RefreshTeam(team *model.Team) {
+	if station := arena.getAssignedAllianceStation(team.Id); station != "" {
+		arena.AllianceStations[station].Team = team
+		arena.ArenaStatusNotifier.Notify()
+	}
+}
+
 // StartMatch Starts the match if all conditions are met.
 func (arena *Arena) StartMatch() error {
 	err := arena.checkCanStartMatch()
 	if err == nil {
 		// Save the match start time and game-specific data to the database for posterity.
 		arena.CurrentMatch.StartedAt = time.Now()
+		if arena.CurrentMatch.StartedAt.Before(arena.EarliestMatchStartTime) {
+			log.Printf(
+				"Warning: match %s started %d seconds before the minimum alliance turnaround had elapsed.",
+				arena.CurrentMatch.DisplayName,
+				int(arena.EarliestMatchStartTime.Sub(arena.CurrentMatch.StartedAt).Seconds()),
+			)
+		}
 		if arena.CurrentMatch.Type != "test" {
 			arena.Database.UpdateMatch(arena.CurrentMatch)
 		}
@@ -380,12 +655,28 @@ func (arena *Arena) ResetMatch() error {
 	return nil
 }
 
-// StartTimeout Starts a timeout of the given duration.
-func (arena *Arena) StartTimeout(durationSec int) error {
+// StartTimeout Starts a timeout of the given duration, charging it to the given alliance ("red" or "blue") of the
+// current playoff match, or to no alliance if blank.
+func (arena *Arena) StartTimeout(durationSec int, alliance string) error {
 	if arena.MatchState != PreMatch {
 		return fmt.Errorf("cannot start timeout while there is a match still in progress or with results pending")
 	}
 
+	// A timeout called by an alliance uses up its coupon for the current playoff round; a field timeout is free.
+	if alliance != "" {
+		if !arena.CurrentMatch.ShouldUpdateEliminationMatches() {
+			return fmt.Errorf("alliance timeouts can only be called during playoff matches")
+		}
+		allianceId, err := arena.getElimAllianceId(alliance)
+		if err != nil {
+			return err
+		}
+		if err = arena.Database.UseAllianceTimeout(allianceId, arena.CurrentMatch); err != nil {
+			return err
+		}
+		arena.ScorePostedNotifier.Notify()
+	}
+
 	game.MatchTiming.TimeoutDurationSec = durationSec
 	game.UpdateMatchSounds()
 	arena.soundsPlayed = make(map[*game.MatchSound]struct{})
@@ -559,11 +850,19 @@ func (arena *Arena) Update() {
 // Run Loops indefinitely to track and update the arena components.
 func (arena *Arena) Run() {
 	// Start other loops in goroutines.
-	go arena.listenForDriverStations()
-	go arena.listenForDsUdpPackets()
+	if arena.fieldSettings.DsListenAddress == "" {
+		log.Printf("Not listening for field %d driver stations since no address is configured.", arena.FieldId)
+	} else {
+		go arena.listenForDriverStations()
+		go arena.listenForDsUdpPackets()
+	}
 	go arena.accessPoint.Run()
 	go arena.accessPoint2.Run()
 	go arena.Plc.Run()
+	if arena.FieldId == 1 {
+		// The outbox is shared by all fields, so only one of them needs to drain it.
+		go arena.runPublishOutbox()
+	}
 
 	for {
 		arena.Update()
@@ -622,7 +921,8 @@ func (arena *Arena) assignTeam(teamId int, station string) error {
 	return nil
 }
 
-// Returns the next match of the same type that is currently loaded, or nil if there are no more matches.
+// Returns the next match of the same type that is currently loaded and that may be played on this field, or nil if
+// there are no more matches. Matches that are loaded on another field are skipped.
 func (arena *Arena) getNextMatch(excludeCurrent bool) (*model.Match, error) {
 	if arena.CurrentMatch.Type == "test" {
 		return nil, nil
@@ -633,7 +933,8 @@ func (arena *Arena) getNextMatch(excludeCurrent bool) (*model.Match, error) {
 		return nil, err
 	}
 	for _, match := range matches {
-		if !match.IsComplete() && !(excludeCurrent && match.Id == arena.CurrentMatch.Id) {
+		if !match.IsComplete() && !(excludeCurrent && match.Id == arena.CurrentMatch.Id) &&
+			match.IsOnField(arena.FieldId) && arena.getArenaWithMatchLoaded(&match) == nil {
 			return &match, nil
 		}
 	}
@@ -642,6 +943,19 @@ func (arena *Arena) getNextMatch(excludeCurrent bool) (*model.Match, error) {
 	return nil, nil
 }
 
+// Returns the arena for another field that currently has the given match loaded, or nil if there is none.
+func (arena *Arena) getArenaWithMatchLoaded(match *model.Match) *Arena {
+	if match.Id == 0 {
+		return nil
+	}
+	for _, fieldArena := range arena.fieldArenas {
+		if fieldArena != arena && fieldArena.CurrentMatch != nil && fieldArena.CurrentMatch.Id == match.Id {
+			return fieldArena
+		}
+	}
+	return nil
+}
+
 // Configures the field network for the next match in advance of the current match being scored and committed.
 func (arena *Arena) preLoadNextMatch() {
 	if arena.MatchState != PostMatch {
@@ -673,7 +987,7 @@ func (arena *Arena) preLoadNextMatch() {
 // Asynchronously reconfigures the networking hardware for the new set of teams.
 func (arena *Arena) setupNetwork(teams [6]*model.Team) {
 	if arena.EventSettings.NetworkSecurityEnabled {
-		if arena.EventSettings.Ap2TeamChannel == 0 {
+		if arena.fieldSettings.Ap2TeamChannel == 0 {
 			// Only one AP is being used.
 			if err := arena.accessPoint.ConfigureTeamWifi(teams); err != nil {
 				log.Printf("Failed to configure team WiFi: %s", err.Error())
@@ -703,11 +1017,24 @@ func (arena *Arena) checkCanStartMatch() error {
 		return fmt.Errorf("cannot start match while there is a match still in progress or with results pending")
 	}
 
+	if arena.EventSettings.ElimTurnaroundEnforced && time.Now().Before(arena.EarliestMatchStartTime) {
+		return fmt.Errorf(
+			"cannot start match until %s to give the alliances their minimum turnaround",
+			arena.EarliestMatchStartTime.Format("3:04:05 PM"),
+		)
+	}
+
 	err := arena.checkAllianceStationsReady("R1", "R2", "R3", "B1", "B2", "B3")
 	if err != nil {
 		return err
 	}
 
+	if arena.EventSettings.InspectionRequired && arena.CurrentMatch.Type == "qualification" {
+		if err = arena.checkTeamsInspected("R1", "R2", "R3", "B1", "B2", "B3"); err != nil {
+			return err
+		}
+	}
+
 	if arena.Plc.IsEnabled() {
 		if !arena.Plc.IsHealthy {
 			return fmt.Errorf("cannot start match while PLC is not healthy")
@@ -741,6 +1068,19 @@ func (arena *Arena) checkAllianceStationsReady(stations ...string) error {
 	return nil
 }
 
+// Returns an error if any of the teams in the given stations haven't passed inspection and aren't bypassed.
+func (arena *Arena) checkTeamsInspected(stations ...string) error {
+	for _, station := range stations {
+		allianceStation := arena.AllianceStations[station]
+		if allianceStation.Team != nil && !allianceStation.Bypass && !allianceStation.Team.PassedInspection() {
+			return fmt.Errorf("cannot start match until team %d has passed inspection or is bypassed",
+				allianceStation.Team.Id)
+		}
+	}
+
+	return nil
+}
+
 func (arena *Arena) sendDsPacket(auto bool, enabled bool) {
 	for _, allianceStation := range arena.AllianceStations {
 		dsConn := allianceStation.DsConn
@@ -769,6 +1109,18 @@ func (arena *Arena) getAssignedAllianceStation(teamId int) string {
 	return ""
 }
 
+// Returns the ID of the playoff alliance playing on the given side ("red" or "blue") of the current match.
+func (arena *Arena) getElimAllianceId(alliance string) (int, error) {
+	switch alliance {
+	case "red":
+		return arena.CurrentMatch.ElimRedAlliance, nil
+	case "blue":
+		return arena.CurrentMatch.ElimBlueAlliance, nil
+	default:
+		return 0, fmt.Errorf("invalid alliance '%s'", alliance)
+	}
+}
+
 // Updates the score given new input information from the field PLC.
 func (arena *Arena) handlePlcInput() {
 	// Handle emergency stops.
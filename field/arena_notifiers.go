@@ -27,6 +27,7 @@ type ArenaNotifiers struct {
 	MatchTimeNotifier                  *websocket.Notifier
 	MatchTimingNotifier                *websocket.Notifier
 	PlaySoundNotifier                  *websocket.Notifier
+	PublicMatchLoadNotifier            *websocket.Notifier
 	RealtimeScoreNotifier              *websocket.Notifier
 	ReloadDisplaysNotifier             *websocket.Notifier
 	ScorePostedNotifier                *websocket.Notifier
@@ -58,6 +59,7 @@ func (arena *Arena) configureNotifiers() {
 	arena.MatchTimeNotifier = websocket.NewNotifier("matchTime", arena.generateMatchTimeMessage)
 	arena.MatchTimingNotifier = websocket.NewNotifier("matchTiming", arena.generateMatchTimingMessage)
 	arena.PlaySoundNotifier = websocket.NewNotifier("playSound", nil)
+	arena.PublicMatchLoadNotifier = websocket.NewNotifier("matchLoad", arena.generatePublicMatchLoadMessage)
 	arena.RealtimeScoreNotifier = websocket.NewNotifier("realtimeScore", arena.generateRealtimeScoreMessage)
 	arena.ReloadDisplaysNotifier = websocket.NewNotifier("reload", nil)
 	arena.ScorePostedNotifier = websocket.NewNotifier("scorePosted", arena.generateScorePostedMessage)
@@ -75,7 +77,7 @@ func (arena *Arena) generateArenaStatusMessage() interface{} {
 	// Convert AP team wifi network status array to a map by station for ease of client use.
 	teamWifiStatuses := make(map[string]network.TeamWifiStatus)
 	for i, station := range []string{"R1", "R2", "R3", "B1", "B2", "B3"} {
-		if arena.EventSettings.Ap2TeamChannel == 0 || i < 3 {
+		if arena.fieldSettings.Ap2TeamChannel == 0 || i < 3 {
 			teamWifiStatuses[station] = arena.accessPoint.TeamWifiStatuses[i]
 		} else {
 			teamWifiStatuses[station] = arena.accessPoint2.TeamWifiStatuses[i]
@@ -179,6 +181,20 @@ func (arena *Arena) generateMatchTimingMessage() interface{} {
 	return &game.MatchTiming
 }
 
+// Returns a reduced version of the match load message that leaves out the team details (such as WPA keys and contact
+// information), for use by clients that are exposed to the public.
+func (arena *Arena) generatePublicMatchLoadMessage() interface{} {
+	return &struct {
+		MatchType string
+		MatchName string
+		Match     *model.Match
+	}{
+		arena.CurrentMatch.CapitalizedType(),
+		arena.CurrentMatch.TypePrefix() + arena.CurrentMatch.DisplayName,
+		arena.CurrentMatch,
+	}
+}
+
 func (arena *Arena) generateRealtimeScoreMessage() interface{} {
 	fields := struct {
 		Red  *audienceAllianceScoreFields
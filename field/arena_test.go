@@ -9,6 +9,7 @@ import (
 
 	"github.com/BotDogs4645/da/game"
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/network"
 	"github.com/BotDogs4645/da/tournament"
 	"github.com/stretchr/testify/assert"
 )
@@ -473,6 +474,176 @@ func TestSubstituteTeam(t *testing.T) {
 	assert.Nil(t, arena.SubstituteTeam(107, "R1"))
 }
 
+func TestInvokeBackupTeam(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.EventSettings.NumElimAlliances = 2
+	tournament.CreateTestAlliances(arena.Database, 2)
+	assert.Nil(t, arena.CreatePlayoffBracket())
+	assert.Nil(t, arena.UpdatePlayoffBracket(nil))
+	arena.Database.CreateRanking(&game.Ranking{TeamId: 101, Rank: 1})
+	arena.Database.CreateRanking(&game.Ranking{TeamId: 254, Rank: 2})
+
+	// Check that backups can't be called outside the playoffs.
+	err := arena.InvokeBackupTeam("red", 102)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "can only call in a backup team for playoff matches")
+	}
+
+	matches, _ := arena.Database.GetMatchesByType("elimination")
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Nil(t, arena.LoadMatch(&matches[0]))
+	}
+	err = arena.InvokeBackupTeam("green", 102)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid alliance")
+	}
+	assert.Nil(t, arena.InvokeBackupTeam("red", 101))
+	assert.Equal(t, 102, arena.CurrentMatch.Red1)
+	assert.Equal(t, 254, arena.CurrentMatch.Red2)
+	assert.Equal(t, 254, arena.AllianceStations["R2"].Team.Id)
+
+	// Check that the change carries over to the later matches in the series.
+	match, _ := arena.Database.GetMatchById(matches[1].Id)
+	assert.Equal(t, 254, match.Red2)
+	alliance, _ := arena.Database.GetAllianceById(1)
+	assert.Equal(
+		t,
+		model.AllianceBackup{
+			TeamId: 254, ReplacedTeamId: 101, FromMatchId: matches[0].Id, ElimRound: matches[0].ElimRound,
+		},
+		*alliance.Backup,
+	)
+
+	// Check that no more backups are available.
+	err = arena.InvokeBackupTeam("blue", 201)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "there are no backup teams available")
+	}
+}
+
+func TestAllianceTimeout(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.EventSettings.NumElimAlliances = 2
+	tournament.CreateTestAlliances(arena.Database, 2)
+	assert.Nil(t, arena.CreatePlayoffBracket())
+	assert.Nil(t, arena.UpdatePlayoffBracket(nil))
+
+	// Check that alliance timeouts can't be called outside the playoffs.
+	err := arena.StartTimeout(1, "red")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "alliance timeouts can only be called during playoff matches")
+	}
+
+	matches, _ := arena.Database.GetMatchesByType("elimination")
+	if assert.Equal(t, 2, len(matches)) {
+		assert.Nil(t, arena.LoadMatch(&matches[0]))
+	}
+	err = arena.StartTimeout(1, "green")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid alliance")
+	}
+	assert.Nil(t, arena.StartTimeout(1, "blue"))
+	alliance, _ := arena.Database.GetAllianceById(2)
+	assert.Equal(t, []model.AllianceTimeout{{ElimRound: matches[0].ElimRound, MatchId: matches[0].Id}},
+		alliance.Timeouts)
+
+	// Check that a second timeout in the same round is refused but a field timeout is still allowed.
+	arena.MatchState = PreMatch
+	err = arena.StartTimeout(1, "blue")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "alliance 2 has already used its timeout")
+	}
+	assert.Nil(t, arena.StartTimeout(1, "red"))
+	arena.MatchState = PreMatch
+	assert.Nil(t, arena.StartTimeout(1, ""))
+}
+
+func TestMinimumAllianceTurnaround(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.EventSettings.NumElimAlliances = 2
+	arena.EventSettings.ElimTurnaroundMin = 10
+	arena.EventSettings.ElimTurnaroundEnforced = true
+	tournament.CreateTestAlliances(arena.Database, 2)
+	assert.Nil(t, arena.CreatePlayoffBracket())
+	assert.Nil(t, arena.UpdatePlayoffBracket(nil))
+	for _, station := range arena.AllianceStations {
+		station.Bypass = true
+	}
+
+	matches, _ := arena.Database.GetMatchesByType("elimination")
+	assert.Equal(t, 2, len(matches))
+	assert.Nil(t, arena.LoadMatch(&matches[0]))
+	assert.True(t, arena.EarliestMatchStartTime.IsZero())
+	assert.Nil(t, arena.checkCanStartMatch())
+	assert.Equal(t, "", arena.getEarlyLateMessage())
+
+	matches[0].StartedAt = time.Now().Add(-60 * time.Second)
+	matches[0].Status = game.RedWonMatch
+	assert.Nil(t, arena.Database.UpdateMatch(&matches[0]))
+	assert.Nil(t, arena.LoadMatch(&matches[1]))
+	assert.Equal(
+		t,
+		matches[0].StartedAt.Add(game.GetDurationToTeleopEnd()).Add(10*time.Minute).Unix(),
+		arena.EarliestMatchStartTime.Unix(),
+	)
+	err := arena.checkCanStartMatch()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "to give the alliances their minimum turnaround")
+	}
+	assert.Contains(t, arena.getEarlyLateMessage(), "Match may start at")
+
+	// Check that an early start is only warned about when the turnaround isn't enforced.
+	arena.EventSettings.ElimTurnaroundEnforced = false
+	assert.Nil(t, arena.checkCanStartMatch())
+	arena.EarliestMatchStartTime = time.Now().Add(-time.Second)
+	arena.EventSettings.ElimTurnaroundEnforced = true
+	assert.Nil(t, arena.checkCanStartMatch())
+	assert.Equal(t, "Alliance turnaround complete", arena.getEarlyLateMessage())
+}
+
+func TestArenaInspectionRequired(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.EventSettings.InspectionRequired = true
+	for _, teamId := range []int{101, 102, 103, 104, 105, 106} {
+		arena.Database.CreateTeam(&model.Team{Id: teamId, Inspection: model.InspectionPassed})
+	}
+	arena.Database.CreateTeam(&model.Team{Id: 107, Inspection: model.InspectionFailed})
+	for _, station := range arena.AllianceStations {
+		station.Bypass = true
+	}
+
+	// Check that uninspected teams are allowed in practice matches.
+	match := model.Match{Type: "practice", Red1: 107, Red2: 102, Red3: 103, Blue1: 104, Blue2: 105, Blue3: 106}
+	arena.Database.CreateMatch(&match)
+	assert.Nil(t, arena.LoadMatch(&match))
+	arena.AllianceStations["R1"].Bypass = false
+	arena.AllianceStations["R1"].DsConn = &DriverStationConnection{TeamId: 107, RobotLinked: true}
+	assert.Nil(t, arena.checkCanStartMatch())
+
+	match = model.Match{Type: "qualification", Red1: 107, Red2: 102, Red3: 103, Blue1: 104, Blue2: 105, Blue3: 106}
+	arena.Database.CreateMatch(&match)
+	assert.Nil(t, arena.LoadMatch(&match))
+	arena.AllianceStations["R1"].Bypass = false
+	arena.AllianceStations["R1"].DsConn = &DriverStationConnection{TeamId: 107, RobotLinked: true}
+	err := arena.checkCanStartMatch()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "team 107 has passed inspection or is bypassed")
+	}
+
+	// Check that bypassing the team or passing it at inspection allows the match to start.
+	arena.AllianceStations["R1"].Bypass = true
+	assert.Nil(t, arena.checkCanStartMatch())
+	arena.AllianceStations["R1"].Bypass = false
+	arena.RefreshTeam(&model.Team{Id: 107, Inspection: model.InspectionPassed})
+	assert.Nil(t, arena.checkCanStartMatch())
+
+	// Check that the requirement is only enforced when enabled.
+	arena.RefreshTeam(&model.Team{Id: 107, Inspection: model.InspectionReinspect})
+	assert.NotNil(t, arena.checkCanStartMatch())
+	arena.EventSettings.InspectionRequired = false
+	assert.Nil(t, arena.checkCanStartMatch())
+}
+
 func TestAstop(t *testing.T) {
 	arena := setupTestArena(t)
 
@@ -569,7 +740,7 @@ func TestArenaTimeout(t *testing.T) {
 
 	// Test regular ending of timeout.
 	timeoutDurationSec := 9
-	assert.Nil(t, arena.StartTimeout(timeoutDurationSec))
+	assert.Nil(t, arena.StartTimeout(timeoutDurationSec, ""))
 	assert.Equal(t, timeoutDurationSec, game.MatchTiming.TimeoutDurationSec)
 	assert.Equal(t, TimeoutActive, arena.MatchState)
 	arena.MatchStartTime = time.Now().Add(-time.Duration(timeoutDurationSec) * time.Second)
@@ -581,7 +752,7 @@ func TestArenaTimeout(t *testing.T) {
 
 	// Test early cancellation of timeout.
 	timeoutDurationSec = 28
-	assert.Nil(t, arena.StartTimeout(timeoutDurationSec))
+	assert.Nil(t, arena.StartTimeout(timeoutDurationSec, ""))
 	assert.Equal(t, timeoutDurationSec, game.MatchTiming.TimeoutDurationSec)
 	assert.Equal(t, TimeoutActive, arena.MatchState)
 	assert.Nil(t, arena.AbortMatch())
@@ -600,7 +771,7 @@ func TestArenaTimeout(t *testing.T) {
 	arena.AllianceStations["B3"].Bypass = true
 	assert.Nil(t, arena.StartMatch())
 	arena.Update()
-	assert.NotNil(t, arena.StartTimeout(1))
+	assert.NotNil(t, arena.StartTimeout(1, ""))
 	assert.NotEqual(t, TimeoutActive, arena.MatchState)
 	assert.Equal(t, timeoutDurationSec, game.MatchTiming.TimeoutDurationSec)
 	arena.MatchStartTime = time.Now().Add(-time.Duration(game.MatchTiming.WarmupDurationSec+
@@ -608,7 +779,7 @@ func TestArenaTimeout(t *testing.T) {
 		time.Second)
 	for arena.MatchState != PostMatch {
 		arena.Update()
-		assert.NotNil(t, arena.StartTimeout(1))
+		assert.NotNil(t, arena.StartTimeout(1, ""))
 	}
 }
 
@@ -648,3 +819,151 @@ func TestSaveTeamHasConnected(t *testing.T) {
 		assert.Equal(t, "San Jose", teams[5].City)
 	}
 }
+
+func TestMultipleFields(t *testing.T) {
+	arenas := SetupTestArenas(t, "fields", 2)
+	if !assert.Equal(t, 2, len(arenas)) {
+		return
+	}
+	arena1, arena2 := arenas[0], arenas[1]
+	assert.Equal(t, 1, arena1.FieldId)
+	assert.Equal(t, 2, arena2.FieldId)
+	assert.Same(t, arena1.Database, arena2.Database)
+	assert.Equal(t, arenas, arena2.FieldArenas())
+	assert.Same(t, arena2, arena1.FieldArena(2))
+	assert.Nil(t, arena1.FieldArena(3))
+
+	// Check that each field gets its own hardware configuration.
+	assert.Equal(t, network.ServerIpAddress, arena1.fieldSettings.DsListenAddress)
+	assert.Equal(t, "10.0.102.5", arena2.fieldSettings.DsListenAddress)
+	arena1.Database.SaveFieldSettings(&model.FieldSettings{Id: 2, DsListenAddress: "10.0.200.5",
+		PlcAddress: "10.0.200.10"})
+	assert.Nil(t, arena2.LoadSettings())
+	assert.Equal(t, "10.0.200.5", arena2.fieldSettings.DsListenAddress)
+	assert.True(t, arena2.Plc.IsEnabled())
+	assert.False(t, arena1.Plc.IsEnabled())
+
+	// Check that each field only loads the matches assigned to it, and that unassigned ones go to either field.
+	arena1.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1", FieldId: 1})
+	arena1.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2", FieldId: 2})
+	arena1.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "3", FieldId: 1})
+	arena1.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "4"})
+	arena1.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "5"})
+	match, _ := arena1.Database.GetMatchById(1)
+	assert.Nil(t, arena1.LoadMatch(match))
+	arena2.CurrentMatch = &model.Match{Type: "qualification"}
+	assert.Nil(t, arena2.LoadNextMatch())
+	assert.Equal(t, "2", arena2.CurrentMatch.DisplayName)
+
+	// Check that a match loaded on one field can't be loaded on the other.
+	err := arena2.LoadMatch(match)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "already loaded on field 1")
+	}
+
+	completeMatch := func(arena *Arena) {
+		arena.CurrentMatch.Status = game.TieMatch
+		arena.Database.UpdateMatch(arena.CurrentMatch)
+	}
+	completeMatch(arena1)
+	assert.Nil(t, arena1.LoadNextMatch())
+	assert.Equal(t, "3", arena1.CurrentMatch.DisplayName)
+	completeMatch(arena2)
+	assert.Nil(t, arena2.LoadNextMatch())
+	assert.Equal(t, "4", arena2.CurrentMatch.DisplayName)
+	completeMatch(arena1)
+	assert.Nil(t, arena1.LoadNextMatch())
+	assert.Equal(t, "5", arena1.CurrentMatch.DisplayName)
+	completeMatch(arena2)
+	assert.Nil(t, arena2.LoadNextMatch())
+	assert.Equal(t, "test", arena2.CurrentMatch.Type)
+}
+
+func TestMultipleFieldsWithoutDsListenAddress(t *testing.T) {
+	arenas := SetupTestArenas(t, "fields", 3)
+	assert.Equal(t, 3, len(arenas))
+	database := arenas[0].Database
+	database.SaveFieldSettings(&model.FieldSettings{Id: 3})
+	assert.Nil(t, database.Close())
+
+	// Check that a field without its own driver station address isn't started, since it would listen on all addresses.
+	arenas, err := NewArenas(database.Path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(arenas))
+}
+
+func TestArenaSwitchDatabase(t *testing.T) {
+	arenas := SetupTestArenas(t, "switch", 2)
+	arena1, arena2 := arenas[0], arenas[1]
+	arena1.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1"})
+	match, _ := arena1.Database.GetMatchById(1)
+	assert.Nil(t, arena1.LoadMatch(match))
+	arena1.AllianceSelectionAlliances = []model.Alliance{{Id: 1}}
+
+	otherDbPath := t.TempDir() + "/other.db"
+	otherDb, err := model.OpenDatabase(otherDbPath)
+	assert.Nil(t, err)
+	otherDb.CreateTeam(&model.Team{Id: 254})
+	eventSettings, _ := otherDb.GetEventSettings()
+	eventSettings.Name = "Other Event"
+	otherDb.UpdateEventSettings(eventSettings)
+	otherDb.CreateDisplay(&model.Display{DisplayId: "200", Type: "/displays/audience"})
+	otherDb.CreateUser(&model.User{Username: "other", Role: model.RoleAdmin})
+	otherDb.Close()
+	arena1.Database.CreateUser(&model.User{Username: "bertha", Role: model.RoleScorer})
+	arena1.Database.CreateUserSession(&model.UserSession{Token: "abc", Username: "bertha"})
+	arena1.RegisterDisplay(&DisplayConfiguration{Id: "100", Nickname: "Pit TV", Type: PitDisplay,
+		Configuration: map[string]string{}}, "1.2.3.4")
+	arena1.Database.CreateDisplay(&model.Display{DisplayId: "101", Type: "/displays/queueing"})
+
+	// Check that events can't be switched while a match is running on either field.
+	arena2.MatchState = AutoPeriod
+	err = arena1.SwitchDatabase(otherDbPath)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "field 2 has a match still in progress")
+	}
+	arena2.MatchState = PreMatch
+
+	arena1.EventSettings.AdminPassword = "secret"
+	assert.Nil(t, arena1.SwitchDatabase(otherDbPath))
+	for _, arena := range arenas {
+		assert.Equal(t, otherDbPath, arena.Database.Path)
+		assert.Equal(t, "Other Event", arena.EventSettings.Name)
+		assert.Equal(t, "secret", arena.EventSettings.AdminPassword)
+		assert.Equal(t, "test", arena.CurrentMatch.Type)
+	}
+	assert.Same(t, arena1.TbaCache, arena2.TbaCache)
+	assert.Empty(t, arena1.AllianceSelectionAlliances)
+	team, _ := arena2.Database.GetTeamById(254)
+	assert.NotNil(t, team)
+
+	// Check that the saved display configurations are carried over in place of those of the other event.
+	savedDisplays, _ := arena1.Database.GetAllDisplays()
+	if assert.Equal(t, 2, len(savedDisplays)) {
+		assert.Equal(t, "100", savedDisplays[0].DisplayId)
+		assert.Equal(t, "Pit TV", savedDisplays[0].Nickname)
+		assert.Equal(t, "101", savedDisplays[1].DisplayId)
+	}
+	assert.Equal(t, 1, arena1.Displays["100"].ConnectionCount)
+	assert.Contains(t, arena1.Displays, "101")
+	assert.NotContains(t, arena1.Displays, "200")
+
+	// Check that the user accounts and sessions are carried over in place of those of the other event.
+	users, _ := arena1.Database.GetAllUsers()
+	if assert.Equal(t, 1, len(users)) {
+		assert.Equal(t, "bertha", users[0].Username)
+	}
+	session, _ := arena1.Database.GetUserSessionByToken("abc")
+	assert.NotNil(t, session)
+
+	// Check that an event's own admin password is kept.
+	otherDbPath = t.TempDir() + "/another.db"
+	otherDb, err = model.OpenDatabase(otherDbPath)
+	assert.Nil(t, err)
+	eventSettings, _ = otherDb.GetEventSettings()
+	eventSettings.AdminPassword = "other"
+	otherDb.UpdateEventSettings(eventSettings)
+	otherDb.Close()
+	assert.Nil(t, arena1.SwitchDatabase(otherDbPath))
+	assert.Equal(t, "other", arena2.EventSettings.AdminPassword)
+}
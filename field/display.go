@@ -7,6 +7,7 @@ package field
 
 import (
 	"fmt"
+	"log"
 	"net/url"
 	"reflect"
 	"sort"
@@ -15,6 +16,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/BotDogs4645/da/model"
 	"github.com/BotDogs4645/da/websocket"
 )
 
@@ -37,6 +39,7 @@ const (
 	QueueingDisplay
 	RankingsDisplay
 	TwitchStreamDisplay
+	PitDisplay
 )
 
 var DisplayTypeNames = map[DisplayType]string{
@@ -47,6 +50,7 @@ var DisplayTypeNames = map[DisplayType]string{
 	BracketDisplay:         "Bracket",
 	FieldMonitorDisplay:    "Field Monitor",
 	LogoDisplay:            "Logo",
+	PitDisplay:             "Pit",
 	QueueingDisplay:        "Queueing",
 	RankingsDisplay:        "Rankings",
 	TwitchStreamDisplay:    "Twitch Stream",
@@ -60,6 +64,7 @@ var displayTypePaths = map[DisplayType]string{
 	BracketDisplay:         "/displays/bracket",
 	FieldMonitorDisplay:    "/displays/field_monitor",
 	LogoDisplay:            "/displays/logo",
+	PitDisplay:             "/displays/pit",
 	QueueingDisplay:        "/displays/queueing",
 	RankingsDisplay:        "/displays/rankings",
 	TwitchStreamDisplay:    "/displays/twitch",
@@ -169,6 +174,12 @@ func (arena *Arena) RegisterDisplay(displayConfig *DisplayConfiguration, ipAddre
 	defer displayRegistryMutex.Unlock()
 
 	display, ok := arena.Displays[displayConfig.Id]
+	if !ok && displayConfig.Type == PlaceholderDisplay {
+		// A display that was purged while disconnected should likewise adopt its saved configuration, if it has one.
+		if savedConfig := arena.savedDisplayConfiguration(displayConfig.Id); savedConfig != nil {
+			displayConfig = savedConfig
+		}
+	}
 	if ok && displayConfig.Type == PlaceholderDisplay {
 		// Don't rewrite the registered configuration if the new one is a placeholder -- if it is reconnecting after a
 		// restart, it should adopt the existing configuration.
@@ -181,6 +192,9 @@ func (arena *Arena) RegisterDisplay(displayConfig *DisplayConfiguration, ipAddre
 				display.generateDisplayConfigurationMessage)
 			arena.Displays[displayConfig.Id] = display
 		}
+		if !ok || !reflect.DeepEqual(*displayConfig, display.DisplayConfiguration) {
+			arena.saveDisplay(*displayConfig)
+		}
 		display.DisplayConfiguration = *displayConfig
 		display.IpAddress = ipAddress
 		display.ConnectionCount += 1
@@ -202,6 +216,7 @@ func (arena *Arena) UpdateDisplay(displayConfig DisplayConfiguration) error {
 		return fmt.Errorf("display %s doesn't exist", displayConfig.Id)
 	}
 	if !reflect.DeepEqual(displayConfig, display.DisplayConfiguration) {
+		arena.saveDisplay(displayConfig)
 		display.DisplayConfiguration = displayConfig
 		display.Notifier.Notify()
 		arena.DisplayConfigurationNotifier.Notify()
@@ -215,9 +230,7 @@ func (arena *Arena) MarkDisplayDisconnected(displayId string) {
 	defer displayRegistryMutex.Unlock()
 
 	if existingDisplay, ok := arena.Displays[displayId]; ok {
-		if existingDisplay.ConnectionCount == 1 && existingDisplay.DisplayConfiguration.Type == PlaceholderDisplay &&
-			existingDisplay.DisplayConfiguration.Nickname == "" &&
-			len(existingDisplay.DisplayConfiguration.Configuration) == 0 {
+		if existingDisplay.ConnectionCount == 1 && existingDisplay.DisplayConfiguration.isUnconfiguredPlaceholder() {
 			// If the display is an unconfigured placeholder, just remove it entirely to prevent clutter.
 			delete(arena.Displays, existingDisplay.DisplayConfiguration.Id)
 		} else {
@@ -229,6 +242,7 @@ func (arena *Arena) MarkDisplayDisconnected(displayId string) {
 }
 
 // Removes any displays from the list that haven't had any active connections for a while and don't have a nickname.
+// Their saved configurations are kept so that they are restored if the displays reconnect.
 func (arena *Arena) purgeDisconnectedDisplays() {
 	displayRegistryMutex.Lock()
 	defer displayRegistryMutex.Unlock()
@@ -245,3 +259,127 @@ func (arena *Arena) purgeDisconnectedDisplays() {
 		arena.DisplayConfigurationNotifier.Notify()
 	}
 }
+
+// Restores the displays whose configurations were saved before the server was restarted, giving each the usual grace
+// period in which to reconnect before it is purged.
+func (arena *Arena) loadSavedDisplays() error {
+	savedDisplays, err := arena.Database.GetAllDisplays()
+	if err != nil {
+		return err
+	}
+	arena.addSavedDisplays(savedDisplays)
+	return nil
+}
+
+// RestoreDisplays Saves the given display configurations, taken from the event database that the current one replaced,
+// in place of any in the current one, and adds those of them that aren't already registered to the arena registry.
+func (arena *Arena) RestoreDisplays(savedDisplays []model.Display) error {
+	if err := arena.Database.ImportDisplays(savedDisplays); err != nil {
+		return err
+	}
+	arena.addSavedDisplays(savedDisplays)
+	arena.DisplayConfigurationNotifier.Notify()
+	return nil
+}
+
+// Adds the given saved displays to the arena registry unless they are already in it, giving each the usual grace period
+// in which to reconnect before it is purged.
+func (arena *Arena) addSavedDisplays(savedDisplays []model.Display) {
+	displayRegistryMutex.Lock()
+	defer displayRegistryMutex.Unlock()
+
+	for _, savedDisplay := range savedDisplays {
+		if _, ok := arena.Displays[savedDisplay.DisplayId]; ok {
+			continue
+		}
+		displayConfig := newSavedDisplayConfiguration(&savedDisplay)
+		if displayConfig == nil {
+			continue
+		}
+		display := &Display{DisplayConfiguration: *displayConfig, lastConnectedTime: time.Now()}
+		display.Notifier = websocket.NewNotifier("displayConfiguration", display.generateDisplayConfigurationMessage)
+		arena.Displays[savedDisplay.DisplayId] = display
+	}
+}
+
+// Returns the saved configuration of the given display, or nil if there isn't one.
+func (arena *Arena) savedDisplayConfiguration(displayId string) *DisplayConfiguration {
+	savedDisplay, err := arena.Database.GetDisplayByDisplayId(displayId)
+	if err != nil {
+		log.Printf("Failed to get the saved configuration of display %s: %v", displayId, err)
+		return nil
+	}
+	if savedDisplay == nil {
+		return nil
+	}
+	return newSavedDisplayConfiguration(savedDisplay)
+}
+
+// Converts the given saved display configuration back into the form used by the arena registry, or returns nil if its
+// type is no longer known.
+func newSavedDisplayConfiguration(savedDisplay *model.Display) *DisplayConfiguration {
+	displayConfig := DisplayConfiguration{
+		Id:            savedDisplay.DisplayId,
+		Nickname:      savedDisplay.Nickname,
+		Configuration: savedDisplay.Configuration,
+	}
+	for displayType, displayPath := range displayTypePaths {
+		if savedDisplay.Type == displayPath {
+			displayConfig.Type = displayType
+			break
+		}
+	}
+	if displayConfig.Type == InvalidDisplay {
+		log.Printf("Ignoring the saved configuration of display %s of unknown type %s", savedDisplay.DisplayId,
+			savedDisplay.Type)
+		return nil
+	}
+	if displayConfig.Configuration == nil {
+		displayConfig.Configuration = make(map[string]string)
+	}
+	return &displayConfig
+}
+
+// Saves the given display configuration so that it can be restored after a restart, or deletes the saved one if there
+// is nothing worth keeping. Failures are logged rather than returned since the display itself is unaffected.
+func (arena *Arena) saveDisplay(displayConfig DisplayConfiguration) {
+	if displayConfig.isUnconfiguredPlaceholder() {
+		arena.deleteSavedDisplay(displayConfig.Id)
+		return
+	}
+
+	savedDisplay, err := arena.Database.GetDisplayByDisplayId(displayConfig.Id)
+	if err == nil {
+		if savedDisplay == nil {
+			savedDisplay = &model.Display{DisplayId: displayConfig.Id}
+		}
+		savedDisplay.Nickname = displayConfig.Nickname
+		savedDisplay.Type = displayTypePaths[displayConfig.Type]
+		savedDisplay.Configuration = displayConfig.Configuration
+		if savedDisplay.Id == 0 {
+			err = arena.Database.CreateDisplay(savedDisplay)
+		} else {
+			err = arena.Database.UpdateDisplay(savedDisplay)
+		}
+	}
+	if err != nil {
+		log.Printf("Failed to save the configuration of display %s: %v", displayConfig.Id, err)
+	}
+}
+
+// Deletes the saved configuration of the given display, if there is one.
+func (arena *Arena) deleteSavedDisplay(displayId string) {
+	savedDisplay, err := arena.Database.GetDisplayByDisplayId(displayId)
+	if err == nil && savedDisplay != nil {
+		err = arena.Database.DeleteDisplay(savedDisplay.Id)
+	}
+	if err != nil {
+		log.Printf("Failed to delete the saved configuration of display %s: %v", displayId, err)
+	}
+}
+
+// Returns true if the display is a placeholder that hasn't been given a nickname or any configuration.
+func (displayConfig *DisplayConfiguration) isUnconfiguredPlaceholder() bool {
+	return displayConfig.Type == PlaceholderDisplay && displayConfig.Nickname == "" &&
+		len(displayConfig.Configuration) == 0
+}
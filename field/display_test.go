@@ -4,6 +4,7 @@
 package field
 
 import (
+	"github.com/BotDogs4645/da/model"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -174,3 +175,60 @@ func TestDisplayPurge(t *testing.T) {
 	arena.purgeDisconnectedDisplays()
 	assert.Contains(t, arena.Displays, "1114")
 }
+
+func TestDisplaySavedAcrossRestart(t *testing.T) {
+	arena := setupTestArena(t)
+
+	arena.RegisterDisplay(
+		&DisplayConfiguration{Id: "100", Type: PlaceholderDisplay, Configuration: map[string]string{}}, "1.2.3.4",
+	)
+	arena.RegisterDisplay(&DisplayConfiguration{Id: "101", Nickname: "Red Wall", Type: AllianceStationDisplay,
+		Configuration: map[string]string{"station": "R1"}}, "1.2.3.5")
+	arena.RegisterDisplay(
+		&DisplayConfiguration{Id: "102", Type: AudienceDisplay, Configuration: map[string]string{}}, "1.2.3.6",
+	)
+	savedDisplays, _ := arena.Database.GetAllDisplays()
+	assert.Equal(t, 2, len(savedDisplays))
+	arena.UpdateDisplay(DisplayConfiguration{Id: "100", Nickname: "Pit TV", Type: PitDisplay,
+		Configuration: map[string]string{"scrollMsPerRow": "1000"}})
+	savedDisplays, _ = arena.Database.GetAllDisplays()
+	if assert.Equal(t, 3, len(savedDisplays)) {
+		assert.Equal(t, "100", savedDisplays[0].DisplayId)
+		assert.Equal(t, "Pit TV", savedDisplays[0].Nickname)
+		assert.Equal(t, "/displays/pit", savedDisplays[0].Type)
+		assert.Equal(t, map[string]string{"scrollMsPerRow": "1000"}, savedDisplays[0].Configuration)
+	}
+
+	// Simulate a restart of the server, after which a display of a type that no longer exists is ignored.
+	arena.Database.CreateDisplay(&model.Display{DisplayId: "103", Type: "/displays/obsolete"})
+	restartedArena, err := newArena(arena.Database, arena.TbaCache, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, restartedArena.loadSavedDisplays())
+	assert.Equal(t, 3, len(restartedArena.Displays))
+	if assert.Contains(t, restartedArena.Displays, "101") {
+		assert.Equal(t, arena.Displays["101"].DisplayConfiguration, restartedArena.Displays["101"].DisplayConfiguration)
+		assert.Equal(t, 0, restartedArena.Displays["101"].ConnectionCount)
+	}
+
+	// Check that a display reconnecting as a placeholder picks its configuration back up.
+	display := restartedArena.RegisterDisplay(
+		&DisplayConfiguration{Id: "101", Type: PlaceholderDisplay, Configuration: map[string]string{}}, "1.2.3.5",
+	)
+	assert.Equal(t, "/displays/alliance_station?displayId=101&nickname=Red+Wall&station=R1", display.ToUrl())
+	assert.Equal(t, 1, display.ConnectionCount)
+
+	// Check that restored displays aren't purged before they have had a chance to reconnect, but are forgotten after.
+	restartedArena.purgeDisconnectedDisplays()
+	assert.Contains(t, restartedArena.Displays, "102")
+	restartedArena.Displays["102"].lastConnectedTime = time.Now().Add(-displayPurgeTtlMin * time.Minute)
+	restartedArena.purgeDisconnectedDisplays()
+	assert.NotContains(t, restartedArena.Displays, "102")
+
+	// Check that a purged display still picks its configuration back up when it reconnects.
+	display = restartedArena.RegisterDisplay(
+		&DisplayConfiguration{Id: "102", Type: PlaceholderDisplay, Configuration: map[string]string{}}, "1.2.3.6",
+	)
+	assert.Equal(t, "/displays/audience?displayId=102", display.ToUrl())
+	savedDisplays, _ = arena.Database.GetAllDisplays()
+	assert.Equal(t, 4, len(savedDisplays))
+}
@@ -73,14 +73,24 @@ func newDriverStationConnection(teamId int, allianceStation string, tcpConn net.
 	return &DriverStationConnection{TeamId: teamId, AllianceStation: allianceStation, tcpConn: tcpConn, udpConn: udpConn}, nil
 }
 
-// Loops indefinitely to read packets and update connection status.
+// Loops indefinitely to read packets and update connection status. When multiple fields are being run, each one only
+// listens on the address of its own field network.
 func (arena *Arena) listenForDsUdpPackets() {
-	udpAddress, _ := net.ResolveUDPAddr("udp4", fmt.Sprintf(":%d", driverStationUdpReceivePort))
+	listenAddress := ""
+	if len(arena.fieldArenas) > 1 {
+		// Each field must only receive the packets sent to its own address, so never fall back to all addresses.
+		listenAddress = arena.fieldSettings.DsListenAddress
+		if listenAddress == "" {
+			log.Printf("Not listening for field %d driver station packets since no address is configured.", arena.FieldId)
+			return
+		}
+	}
+	udpAddress, _ := net.ResolveUDPAddr("udp4", fmt.Sprintf("%s:%d", listenAddress, driverStationUdpReceivePort))
 	listener, err := net.ListenUDP("udp4", udpAddress)
 	if err != nil {
-		log.Fatalf("Error opening driver station UDP socket: %v", err)
+		log.Fatalf("Error opening driver station UDP socket for field %d: %v", arena.FieldId, err)
 	}
-	log.Printf("Listening for driver stations on UDP port %d\n", driverStationUdpReceivePort)
+	log.Printf("Listening for field %d driver stations on UDP port %d\n", arena.FieldId, driverStationUdpReceivePort)
 
 	var data [50]byte
 	for {
@@ -275,15 +285,19 @@ func (dsConn *DriverStationConnection) decodeStatusPacket(data [36]byte) {
 
 // Listens for TCP connection requests to Cheesy Arena from driver stations.
 func (arena *Arena) listenForDriverStations() {
-	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", network.ServerIpAddress, driverStationTcpListenPort))
+	listenAddress := arena.fieldSettings.DsListenAddress
+	if arena.FieldId == 1 {
+		listenAddress = network.ServerIpAddress
+	}
+	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", listenAddress, driverStationTcpListenPort))
 	if err != nil {
-		log.Printf("Error opening driver station TCP socket: %v", err.Error())
-		log.Printf("Change IP address to %s and restart Cheesy Arena to fix.", network.ServerIpAddress)
+		log.Printf("Error opening driver station TCP socket for field %d: %v", arena.FieldId, err.Error())
+		log.Printf("Change IP address to %s and restart Cheesy Arena to fix.", listenAddress)
 		return
 	}
 	defer l.Close()
 
-	log.Printf("Listening for driver stations on TCP port %d\n", driverStationTcpListenPort)
+	log.Printf("Listening for field %d driver stations on TCP port %d\n", arena.FieldId, driverStationTcpListenPort)
 	for {
 		tcpConn, err := l.Accept()
 		if err != nil {
@@ -49,6 +49,9 @@ func (arena *Arena) updateEarlyLateMessage() {
 // Updates the string that indicates how early or late the event is running.
 func (arena *Arena) getEarlyLateMessage() string {
 	currentMatch := arena.CurrentMatch
+	if currentMatch.Type == "elimination" {
+		return arena.getTurnaroundMessage()
+	}
 	if currentMatch.Type != "practice" && currentMatch.Type != "qualification" {
 		// Only practice and qualification matches have a strict schedule.
 		return ""
@@ -105,3 +108,23 @@ func (arena *Arena) getEarlyLateMessage() string {
 	}
 	return "Event is running on schedule"
 }
+
+// Returns the string that indicates when the current playoff match may start given the alliances' minimum turnaround.
+func (arena *Arena) getTurnaroundMessage() string {
+	if arena.EarliestMatchStartTime.IsZero() {
+		return ""
+	}
+	if arena.MatchState == PreMatch {
+		if time.Now().Before(arena.EarliestMatchStartTime) {
+			return fmt.Sprintf("Match may start at %s", arena.EarliestMatchStartTime.Format("3:04:05 PM"))
+		}
+		return "Alliance turnaround complete"
+	}
+	if arena.CurrentMatch.StartedAt.Before(arena.EarliestMatchStartTime) {
+		return fmt.Sprintf(
+			"Match started %d seconds before the alliance turnaround ended",
+			int(arena.EarliestMatchStartTime.Sub(arena.CurrentMatch.StartedAt).Seconds()),
+		)
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+// Durable outbox of results waiting to be published, which is retried with backoff until each publish succeeds.
+
+package field
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+)
+
+const (
+	publishOutboxPeriodSec = 1
+	publishRetryMinDelay   = 5 * time.Second
+	publishRetryMaxDelay   = 5 * time.Minute
+)
+
+// PublishAsync Queues the given resources in the outbox for every enabled publisher and starts sending them in the
+// background. Any update to the same resource that hasn't been sent yet is superseded.
+func (arena *Arena) PublishAsync(resources ...partner.PublishResource) {
+	publishers := arena.Publishers()
+	if len(publishers) == 0 {
+		return
+	}
+	for _, publisher := range publishers {
+		for _, resource := range resources {
+			if err := arena.queuePublishOperation(publisher.Name(), string(resource)); err != nil {
+				log.Printf("Failed to queue %s for publishing to %s: %v", resource, publisher.Name(), err)
+			}
+		}
+	}
+	go arena.ProcessPublishOutbox()
+}
+
+// RetryPublishOperation Queues the given outbox entry to be sent again immediately, regardless of its state.
+func (arena *Arena) RetryPublishOperation(id int) error {
+	operation, err := arena.Database.GetPublishOperationById(id)
+	if err != nil {
+		return err
+	}
+	if operation == nil {
+		return fmt.Errorf("publish operation %d doesn't exist", id)
+	}
+	if err = arena.queuePublishOperation(operation.Publisher, operation.Resource); err != nil {
+		return err
+	}
+	go arena.ProcessPublishOutbox()
+	return nil
+}
+
+// ProcessPublishOutbox Attempts every pending outbox entry that is due, rescheduling failed ones with exponential
+// backoff. Only one pass runs at a time; a call made while another pass is running does nothing.
+func (arena *Arena) ProcessPublishOutbox() {
+	if !arena.publishOutboxRunMutex.TryLock() {
+		return
+	}
+	defer arena.publishOutboxRunMutex.Unlock()
+
+	// Keep going as long as there is due work, since entries may be queued while a pass is in progress.
+	for {
+		operations, err := arena.Database.GetAllPublishOperations()
+		if err != nil {
+			log.Printf("Failed to read publish outbox: %v", err)
+			return
+		}
+		publishers := make(map[string]partner.Publisher)
+		for _, publisher := range arena.Publishers() {
+			publishers[publisher.Name()] = publisher
+		}
+
+		numAttempted := 0
+		for _, operation := range operations {
+			publisher, ok := publishers[operation.Publisher]
+			if !operation.Pending || !ok || time.Now().Before(operation.NextAttemptAt) {
+				// Entries for publishers that have since been disabled stay queued until they are re-enabled.
+				continue
+			}
+			if err = arena.attemptPublishOperation(publisher, operation); err != nil {
+				// Stop the pass, since the entry would otherwise be retried straight away without any backoff.
+				log.Printf("Failed to update publish outbox: %v", err)
+				return
+			}
+			numAttempted++
+		}
+		if numAttempted == 0 {
+			return
+		}
+	}
+}
+
+// LockPublishOutbox Waits for any publishing in progress to finish and holds off any more from starting, or from being
+// queued, until UnlockPublishOutbox is called, so that the database can be swapped out underneath the outbox.
+func (arena *Arena) LockPublishOutbox() {
+	// The outbox is only ever drained by the arena for the first field.
+	outboxArena := arena.fieldArenas[0]
+	outboxArena.publishOutboxRunMutex.Lock()
+	outboxArena.publishOutboxMutex.Lock()
+}
+
+// UnlockPublishOutbox Allows publishing to resume after a call to LockPublishOutbox.
+func (arena *Arena) UnlockPublishOutbox() {
+	outboxArena := arena.fieldArenas[0]
+	outboxArena.publishOutboxMutex.Unlock()
+	outboxArena.publishOutboxRunMutex.Unlock()
+}
+
+// Loops indefinitely to retry publishing in the background.
+func (arena *Arena) runPublishOutbox() {
+	for {
+		arena.ProcessPublishOutbox()
+		time.Sleep(time.Second * publishOutboxPeriodSec)
+	}
+}
+
+// Adds the given resource to the outbox for the given publisher, superseding any entry that hasn't been sent yet.
+func (arena *Arena) queuePublishOperation(publisherName, resource string) error {
+	arena.publishOutboxMutex.Lock()
+	defer arena.publishOutboxMutex.Unlock()
+
+	operation, err := arena.Database.GetPublishOperation(publisherName, resource)
+	if err != nil {
+		return err
+	}
+	isNew := operation == nil
+	if isNew {
+		operation = &model.PublishOperation{Publisher: publisherName, Resource: resource}
+	}
+	operation.Pending = true
+	operation.QueuedAt = time.Now()
+	operation.Attempts = 0
+	operation.NextAttemptAt = operation.QueuedAt
+	if isNew {
+		return arena.Database.CreatePublishOperation(operation)
+	}
+	return arena.Database.UpdatePublishOperation(operation)
+}
+
+// Sends the given outbox entry and records the outcome. Returns an error if the outcome couldn't be recorded.
+func (arena *Arena) attemptPublishOperation(publisher partner.Publisher, operation model.PublishOperation) error {
+	attemptedAt := time.Now()
+	publishErr := partner.Publish(publisher, arena.Database, partner.PublishResource(operation.Resource))
+
+	arena.publishOutboxMutex.Lock()
+	defer arena.publishOutboxMutex.Unlock()
+
+	// Re-read the entry in case it was superseded while the publish was in progress.
+	current, err := arena.Database.GetPublishOperationById(operation.Id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		// The entry was removed while the publish was in progress, so there is nothing left to record.
+		return nil
+	}
+	superseded := !current.QueuedAt.Equal(operation.QueuedAt)
+	current.LastAttemptAt = attemptedAt
+	if publishErr == nil {
+		current.LastError = ""
+		current.LastSuccessAt = time.Now()
+		if !superseded {
+			current.Pending = false
+		}
+	} else {
+		log.Printf("Failed to publish %s to %s (attempt %d): %v", operation.Resource, operation.Publisher,
+			operation.Attempts+1, publishErr)
+		current.LastError = publishErr.Error()
+		if !superseded {
+			current.Attempts++
+			current.NextAttemptAt = attemptedAt.Add(publishRetryDelay(current.Attempts))
+		}
+	}
+	return arena.Database.UpdatePublishOperation(current)
+}
+
+// Returns how long to wait before retrying after the given number of consecutive failed attempts.
+func publishRetryDelay(attempts int) time.Duration {
+	delay := publishRetryMinDelay
+	for i := 1; i < attempts && delay < publishRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > publishRetryMaxDelay {
+		delay = publishRetryMaxDelay
+	}
+	return delay
+}
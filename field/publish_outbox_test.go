@@ -0,0 +1,142 @@
+package field
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishOutboxRetries(t *testing.T) {
+	arena := setupTestArena(t)
+	arena.EventSettings.TbaPublishingEnabled = true
+	arena.TbaClient.BaseUrl = "fakeUrl"
+
+	// Nothing should be queued if no publishers are enabled.
+	arena.EventSettings.TbaPublishingEnabled = false
+	arena.PublishAsync(partner.MatchesResource)
+	operations, _ := arena.Database.GetAllPublishOperations()
+	assert.Empty(t, operations)
+	arena.EventSettings.TbaPublishingEnabled = true
+
+	assert.Nil(t, arena.queuePublishOperation("TBA", "matches"))
+	arena.ProcessPublishOutbox()
+	operation, _ := arena.Database.GetPublishOperation("TBA", "matches")
+	assert.Equal(t, "failed", operation.Status())
+	assert.Equal(t, 1, operation.Attempts)
+	assert.NotEmpty(t, operation.LastError)
+	assert.True(t, operation.LastSuccessAt.IsZero())
+	nextAttemptAt := operation.NextAttemptAt
+	assert.True(t, nextAttemptAt.After(time.Now()))
+
+	// Check that the failed entry isn't retried until its backoff has elapsed.
+	arena.ProcessPublishOutbox()
+	operation, _ = arena.Database.GetPublishOperation("TBA", "matches")
+	assert.Equal(t, 1, operation.Attempts)
+	operation.NextAttemptAt = time.Now().Add(-time.Second)
+	arena.Database.UpdatePublishOperation(operation)
+	arena.ProcessPublishOutbox()
+	operation, _ = arena.Database.GetPublishOperation("TBA", "matches")
+	assert.Equal(t, 2, operation.Attempts)
+
+	// Check that a new update supersedes the failed one rather than adding another entry.
+	assert.Nil(t, arena.queuePublishOperation("TBA", "matches"))
+	operations, _ = arena.Database.GetAllPublishOperations()
+	if assert.Equal(t, 1, len(operations)) {
+		assert.Equal(t, "queued", operations[0].Status())
+		assert.Equal(t, 0, operations[0].Attempts)
+	}
+
+	// Check that entries for disabled publishers stay queued.
+	arena.EventSettings.TbaPublishingEnabled = false
+	arena.ProcessPublishOutbox()
+	operation, _ = arena.Database.GetPublishOperation("TBA", "matches")
+	assert.Equal(t, "queued", operation.Status())
+
+	assert.NotNil(t, arena.RetryPublishOperation(12345))
+}
+
+func TestPublishOutboxSuccess(t *testing.T) {
+	arena := setupTestArena(t)
+	exportDir := filepath.Join(t.TempDir(), "results")
+	arena.EventSettings.FileExportEnabled = true
+	arena.EventSettings.FileExportDir = exportDir
+	arena.Database.CreateTeam(&model.Team{Id: 254})
+
+	assert.Nil(t, arena.queuePublishOperation("file export", "teams"))
+	arena.ProcessPublishOutbox()
+	operation, _ := arena.Database.GetPublishOperation("file export", "teams")
+	assert.Equal(t, "succeeded", operation.Status())
+	assert.Equal(t, "", operation.LastError)
+	assert.False(t, operation.LastSuccessAt.IsZero())
+	_, err := os.Stat(filepath.Join(exportDir, "teams.json"))
+	assert.Nil(t, err)
+
+	// Re-sending a succeeded entry on request.
+	os.RemoveAll(exportDir)
+	assert.Nil(t, arena.queuePublishOperation("file export", "teams"))
+	operation, _ = arena.Database.GetPublishOperation("file export", "teams")
+	assert.Equal(t, "queued", operation.Status())
+	assert.Nil(t, arena.RetryPublishOperation(operation.Id))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(exportDir, "teams.json"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPublishOutboxDuringDatabaseSwitch(t *testing.T) {
+	arena := setupTestArena(t)
+	publishStarted := make(chan struct{}, 1)
+	releasePublish := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		publishStarted <- struct{}{}
+		<-releasePublish
+	}))
+	defer server.Close()
+	arena.EventSettings.WebhookPublishingEnabled = true
+	arena.EventSettings.WebhookUrl = server.URL
+
+	otherDbPath := t.TempDir() + "/other.db"
+	otherDb, err := model.OpenDatabase(otherDbPath)
+	assert.Nil(t, err)
+	otherDb.CreatePublishOperation(&model.PublishOperation{Publisher: "webhook", Resource: "matches"})
+	otherDb.Close()
+
+	assert.Nil(t, arena.queuePublishOperation("webhook", "matches"))
+	go arena.ProcessPublishOutbox()
+	<-publishStarted
+
+	// Check that the switch waits for the publish in progress rather than recording its outcome in the other event.
+	switchErr := make(chan error)
+	go func() {
+		switchErr <- arena.SwitchDatabase(otherDbPath)
+	}()
+	select {
+	case <-switchErr:
+		close(releasePublish)
+		assert.Fail(t, "database was switched while a publish was in progress")
+		return
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(releasePublish)
+	assert.Nil(t, <-switchErr)
+	operation, _ := arena.Database.GetPublishOperation("webhook", "matches")
+	if assert.NotNil(t, operation) {
+		assert.Equal(t, 0, operation.Attempts)
+		assert.True(t, operation.LastAttemptAt.IsZero())
+	}
+}
+
+func TestPublishRetryDelay(t *testing.T) {
+	assert.Equal(t, 5*time.Second, publishRetryDelay(1))
+	assert.Equal(t, 10*time.Second, publishRetryDelay(2))
+	assert.Equal(t, 80*time.Second, publishRetryDelay(5))
+	assert.Equal(t, 5*time.Minute, publishRetryDelay(7))
+	assert.Equal(t, 5*time.Minute, publishRetryDelay(100))
+}
@@ -0,0 +1,58 @@
+// Functions for pushing event data to the configured results publishers.
+
+package field
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BotDogs4645/da/partner"
+)
+
+// Publishers Returns the results publishers that are currently enabled in the event settings.
+func (arena *Arena) Publishers() []partner.Publisher {
+	var publishers []partner.Publisher
+	if arena.EventSettings.TbaPublishingEnabled {
+		publishers = append(publishers, arena.TbaClient)
+	}
+	if arena.EventSettings.WebhookPublishingEnabled && arena.EventSettings.WebhookUrl != "" {
+		publishers = append(publishers, partner.NewWebhookPublisher(arena.EventSettings.WebhookUrl,
+			arena.EventSettings.Name))
+	}
+	if arena.EventSettings.FileExportEnabled && arena.EventSettings.FileExportDir != "" {
+		publishers = append(publishers, partner.NewFileExporter(arena.EventSettings.FileExportDir))
+	}
+	return publishers
+}
+
+// Publish Sends the given resources to every enabled publisher. A failure in one publisher doesn't prevent the others
+// from being attempted; the returned error describes all failures.
+func (arena *Arena) Publish(resources ...partner.PublishResource) error {
+	var failures []string
+	for _, publisher := range arena.Publishers() {
+		for _, resource := range resources {
+			if err := partner.Publish(publisher, arena.Database, resource); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", publisher.Name(), err))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// DeletePublishedMatches Clears out the previously published matches on every enabled publisher, e.g. before the
+// regenerated schedule is published.
+func (arena *Arena) DeletePublishedMatches() error {
+	var failures []string
+	for _, publisher := range arena.Publishers() {
+		if err := publisher.DeletePublishedMatches(); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", publisher.Name(), err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package field
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArenaPublishers(t *testing.T) {
+	arena := setupTestArena(t)
+	assert.Empty(t, arena.Publishers())
+	assert.Nil(t, arena.Publish(partner.TeamsResource))
+
+	// Enabled publishers without a destination configured are ignored.
+	arena.EventSettings.WebhookPublishingEnabled = true
+	arena.EventSettings.FileExportEnabled = true
+	assert.Empty(t, arena.Publishers())
+
+	exportDir := filepath.Join(t.TempDir(), "results")
+	arena.EventSettings.FileExportDir = exportDir
+	arena.EventSettings.WebhookUrl = "fakeUrl"
+	arena.EventSettings.TbaPublishingEnabled = true
+	arena.TbaClient.BaseUrl = "fakeUrl"
+	publishers := arena.Publishers()
+	if assert.Equal(t, 3, len(publishers)) {
+		assert.Equal(t, "TBA", publishers[0].Name())
+		assert.Equal(t, "webhook", publishers[1].Name())
+		assert.Equal(t, "file export", publishers[2].Name())
+	}
+
+	// Check that the failing publishers don't stop the file from being exported.
+	arena.Database.CreateTeam(&model.Team{Id: 254})
+	err := arena.Publish(partner.TeamsResource)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "TBA: ")
+		assert.Contains(t, err.Error(), "webhook: ")
+		assert.NotContains(t, err.Error(), "file export")
+	}
+	data, err := os.ReadFile(filepath.Join(exportDir, "teams.json"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "frc254")
+
+	err = arena.DeletePublishedMatches()
+	if assert.NotNil(t, err) {
+		assert.NotContains(t, err.Error(), "file export")
+	}
+}
@@ -0,0 +1,146 @@
+// Functions for notifying teams when their next match is approaching.
+
+package field
+
+import (
+	"log"
+	"sync"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+)
+
+// Identifies a notification already sent to a team for a match, so that reloading a match doesn't repeat it.
+type teamMatchKey struct {
+	matchId int
+	teamId  int
+}
+
+// Set of the notifications already sent, shared by the arenas for all fields and safe for concurrent use.
+type notifiedTeamMatches struct {
+	mutex sync.Mutex
+	keys  map[teamMatchKey]struct{}
+}
+
+func newNotifiedTeamMatches() *notifiedTeamMatches {
+	return &notifiedTeamMatches{keys: make(map[teamMatchKey]struct{})}
+}
+
+// Returns true if a notification has already been sent for the given team and match.
+func (notified *notifiedTeamMatches) contains(key teamMatchKey) bool {
+	notified.mutex.Lock()
+	defer notified.mutex.Unlock()
+	_, ok := notified.keys[key]
+	return ok
+}
+
+// Records that a notification is being sent for the given team and match. Returns false if one already has been.
+func (notified *notifiedTeamMatches) add(key teamMatchKey) bool {
+	notified.mutex.Lock()
+	defer notified.mutex.Unlock()
+	if _, ok := notified.keys[key]; ok {
+		return false
+	}
+	notified.keys[key] = struct{}{}
+	return true
+}
+
+// Forgets all the notifications that have been sent.
+func (notified *notifiedTeamMatches) clear() {
+	notified.mutex.Lock()
+	defer notified.mutex.Unlock()
+	notified.keys = make(map[teamMatchKey]struct{})
+}
+
+// NotificationChannels Returns the team notification channels that are currently enabled in the event settings.
+func (arena *Arena) NotificationChannels() []partner.NotificationChannel {
+	var channels []partner.NotificationChannel
+	if !arena.EventSettings.TeamNotificationsEnabled {
+		return channels
+	}
+	channels = append(channels, partner.NewNotificationWebhook(arena.EventSettings.NotificationWebhookUrl))
+	if arena.EventSettings.SmtpHost != "" {
+		channels = append(channels, &partner.NotificationEmailer{
+			Host:     arena.EventSettings.SmtpHost,
+			Port:     arena.EventSettings.SmtpPort,
+			Username: arena.EventSettings.SmtpUsername,
+			Password: arena.EventSettings.SmtpPassword,
+			From:     arena.EventSettings.SmtpFrom,
+		})
+	}
+	if arena.EventSettings.NotificationLogEnabled {
+		channels = append(channels, new(partner.NotificationLogger))
+	}
+	return channels
+}
+
+// Notifies the teams in every unplayed match from the current one up to the configured number of matches ahead, other
+// than those that have already been told about their match. Notifications are sent in the background.
+func (arena *Arena) notifyUpcomingTeams() {
+	if arena.CurrentMatch.Type == "test" {
+		return
+	}
+	channels := arena.NotificationChannels()
+	if len(channels) == 0 {
+		return
+	}
+
+	matches, err := arena.Database.GetMatchesByType(arena.CurrentMatch.Type)
+	if err != nil {
+		log.Printf("Failed to get matches for team notifications: %v", err)
+		return
+	}
+	matchesAway := -1
+	for _, match := range matches {
+		if match.Id == arena.CurrentMatch.Id {
+			matchesAway = 0
+		} else if matchesAway >= 0 && !match.IsComplete() {
+			matchesAway++
+		} else {
+			continue
+		}
+		if matchesAway > arena.EventSettings.NotificationMatchesAhead {
+			break
+		}
+
+		for _, teamStation := range []struct {
+			teamId  int
+			station string
+		}{
+			{match.Red1, "R1"}, {match.Red2, "R2"}, {match.Red3, "R3"},
+			{match.Blue1, "B1"}, {match.Blue2, "B2"}, {match.Blue3, "B3"},
+		} {
+			key := teamMatchKey{match.Id, teamStation.teamId}
+			if teamStation.teamId == 0 || arena.notifiedTeamMatches.contains(key) {
+				continue
+			}
+			team, err := arena.Database.GetTeamById(teamStation.teamId)
+			if err != nil {
+				log.Printf("Failed to get team %d for notification: %v", teamStation.teamId, err)
+				continue
+			}
+			if team == nil || !arena.notifiedTeamMatches.add(key) {
+				continue
+			}
+			notification := &partner.TeamNotification{
+				EventName:   arena.EventSettings.Name,
+				TeamId:      team.Id,
+				MatchName:   match.TypePrefix() + match.DisplayName,
+				Station:     teamStation.station,
+				MatchesAway: matchesAway,
+			}
+			go sendTeamNotification(channels, team, notification)
+		}
+	}
+}
+
+// Sends the given notification through each of the given channels, logging any failures.
+func sendTeamNotification(
+	channels []partner.NotificationChannel, team *model.Team, notification *partner.TeamNotification,
+) {
+	for _, channel := range channels {
+		if err := channel.Send(team, notification); err != nil {
+			log.Printf("Failed to send %s notification to team %d: %v", channel.Name(), team.Id, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package field
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArenaNotificationChannels(t *testing.T) {
+	arena := setupTestArena(t)
+	assert.Empty(t, arena.NotificationChannels())
+
+	arena.EventSettings.TeamNotificationsEnabled = true
+	channels := arena.NotificationChannels()
+	if assert.Equal(t, 1, len(channels)) {
+		assert.Equal(t, "webhook", channels[0].Name())
+	}
+
+	arena.EventSettings.SmtpHost = "localhost"
+	arena.EventSettings.NotificationLogEnabled = true
+	channels = arena.NotificationChannels()
+	if assert.Equal(t, 3, len(channels)) {
+		assert.Equal(t, "webhook", channels[0].Name())
+		assert.Equal(t, "email", channels[1].Name())
+		assert.Equal(t, "log", channels[2].Name())
+	}
+}
+
+func TestArenaNotifyUpcomingTeams(t *testing.T) {
+	arena := setupTestArena(t)
+	event := arena.EventSettings.Name
+
+	var mutex sync.Mutex
+	var notifications []partner.TeamNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification partner.TeamNotification
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&notification))
+		mutex.Lock()
+		notifications = append(notifications, notification)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+	receivedNotifications := func(expectedCount int) []partner.TeamNotification {
+		assert.Eventually(t, func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(notifications) >= expectedCount
+		}, time.Second, 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		mutex.Lock()
+		defer mutex.Unlock()
+		received := notifications
+		notifications = nil
+		sort.Slice(received, func(i, j int) bool {
+			if received[i].MatchName != received[j].MatchName {
+				return received[i].MatchName < received[j].MatchName
+			}
+			return received[i].TeamId < received[j].TeamId
+		})
+		return received
+	}
+
+	for i := 1; i <= 4; i++ {
+		arena.Database.CreateTeam(&model.Team{Id: 100 + i})
+	}
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1", Red1: 101, Blue1: 102})
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2", Red1: 103, Blue2: 104})
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "3", Red1: 101, Blue3: 103})
+	arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "4", Red2: 102, Blue1: 104})
+
+	// Nothing should be sent while notifications are disabled.
+	match, _ := arena.Database.GetMatchById(1)
+	assert.Nil(t, arena.LoadMatch(match))
+	assert.Empty(t, arena.notifiedTeamMatches.keys)
+
+	arena.EventSettings.TeamNotificationsEnabled = true
+	arena.EventSettings.NotificationWebhookUrl = server.URL
+	arena.EventSettings.NotificationMatchesAhead = 1
+	assert.Nil(t, arena.LoadMatch(match))
+	received := receivedNotifications(4)
+	if assert.Equal(t, 4, len(received)) {
+		assert.Equal(
+			t,
+			partner.TeamNotification{EventName: event, TeamId: 101, MatchName: "Q1", Station: "R1", MatchesAway: 0},
+			received[0],
+		)
+		assert.Equal(t, "Q1", received[1].MatchName)
+		assert.Equal(t, 102, received[1].TeamId)
+		assert.Equal(t, "B1", received[1].Station)
+		assert.Equal(
+			t,
+			partner.TeamNotification{EventName: event, TeamId: 103, MatchName: "Q2", Station: "R1", MatchesAway: 1},
+			received[2],
+		)
+		assert.Equal(t, 104, received[3].TeamId)
+	}
+
+	// Reloading the same match shouldn't send anything again.
+	assert.Nil(t, arena.LoadMatch(match))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, receivedNotifications(0))
+
+	// Moving on to the next match should only notify the teams in the newly approaching one.
+	match.Status = game.RedWonMatch
+	arena.Database.UpdateMatch(match)
+	assert.Nil(t, arena.LoadNextMatch())
+	received = receivedNotifications(2)
+	if assert.Equal(t, 2, len(received)) {
+		assert.Equal(
+			t,
+			partner.TeamNotification{EventName: event, TeamId: 101, MatchName: "Q3", Station: "R1", MatchesAway: 1},
+			received[0],
+		)
+		assert.Equal(
+			t,
+			partner.TeamNotification{EventName: event, TeamId: 103, MatchName: "Q3", Station: "B3", MatchesAway: 1},
+			received[1],
+		)
+	}
+
+	// Test matches don't trigger notifications.
+	assert.Nil(t, arena.LoadTestMatch())
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, receivedNotifications(0))
+}
@@ -20,11 +20,27 @@ func SetupTestArena(t *testing.T, uniqueName string) *Arena {
 	model.BaseDir = ".."
 	dbPath := filepath.Join(model.BaseDir, fmt.Sprintf("%s_test.db", uniqueName))
 	os.Remove(dbPath)
+	os.RemoveAll(TbaCacheDir(dbPath))
 	arena, err := NewArena(dbPath)
 	assert.Nil(t, err)
 	return arena
 }
 
+// SetupTestArenas Creates a fresh database configured with the given number of fields and returns an arena for each.
+func SetupTestArenas(t *testing.T, uniqueName string, numFields int) []*Arena {
+	arena := SetupTestArena(t, uniqueName)
+	arena.EventSettings.NumFields = numFields
+	assert.Nil(t, arena.Database.UpdateEventSettings(arena.EventSettings))
+	for fieldId := 2; fieldId <= numFields; fieldId++ {
+		fieldSettings := model.FieldSettings{Id: fieldId, DsListenAddress: fmt.Sprintf("10.0.10%d.5", fieldId)}
+		assert.Nil(t, arena.Database.SaveFieldSettings(&fieldSettings))
+	}
+	assert.Nil(t, arena.Database.Close())
+	arenas, err := NewArenas(arena.Database.Path)
+	assert.Nil(t, err)
+	return arenas
+}
+
 func setupTestArena(t *testing.T) *Arena {
 	game.MatchTiming.WarmupDurationSec = 3
 	game.MatchTiming.PauseDurationSec = 2
@@ -8,25 +8,46 @@ package main
 
 import (
 	"log"
+	"path/filepath"
 
 	"github.com/BotDogs4645/da/field"
+	"github.com/BotDogs4645/da/model"
 	"github.com/BotDogs4645/da/web"
 )
 
-const eventDbPath = "./event.db"
+const legacyEventDbPath = "./event.db"
 const httpPort = 8080
+const publicHttpPort = 8081
+const httpsPort = 8443
 
 // Main entry point for the application.
 func main() {
-	arena, err := field.NewArena(eventDbPath)
+	// Open whichever event in the library was last switched to, bringing in the database of older versions if present.
+	eventLibrary := model.NewEventLibrary(filepath.Join(model.BaseDir, model.EventsDir))
+	if err := eventLibrary.ImportLegacyEvent(legacyEventDbPath); err != nil {
+		log.Fatalln("Error during startup: ", err)
+	}
+	eventDbPath, err := eventLibrary.CurrentEventPath()
 	if err != nil {
 		log.Fatalln("Error during startup: ", err)
 	}
+	arenas, err := field.NewArenas(eventDbPath)
+	if err != nil {
+		log.Fatalln("Error during startup: ", err)
+	}
+	arena := arenas[0]
 
 	// Start the webServer server in a separate goroutine.
 	webServer := web.NewWeb(arena)
 	go webServer.ServeWebInterface(httpPort)
+	go webServer.ServeSecureWebInterface(httpsPort)
+
+	// Serve the read-only public portal on its own port so that it can be exposed without exposing the admin pages.
+	go webServer.ServePublicInterface(publicHttpPort)
 
-	// Run the arena state machine in the main thread.
+	// Run the state machine of any additional fields in separate goroutines and that of the first in the main thread.
+	for _, fieldArena := range arenas[1:] {
+		go fieldArena.Run()
+	}
 	arena.Run()
 }
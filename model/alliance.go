@@ -5,12 +5,31 @@
 
 package model
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+)
 
 type Alliance struct {
-	Id      int `db:"id,manual"`
-	TeamIds []int
-	Lineup  [3]int
+	Id       int `db:"id,manual"`
+	TeamIds  []int
+	Lineup   [3]int
+	Backup   *AllianceBackup
+	Timeouts []AllianceTimeout
+}
+
+// Records a backup robot that was called in to replace one of the alliance's robots during the playoffs.
+type AllianceBackup struct {
+	TeamId         int
+	ReplacedTeamId int
+	FromMatchId    int
+	ElimRound      int
+}
+
+// Records a timeout that was called by the alliance during the playoffs.
+type AllianceTimeout struct {
+	ElimRound int
+	MatchId   int
 }
 
 func (database *Database) CreateAlliance(alliance *Alliance) error {
@@ -77,6 +96,116 @@ func (database *Database) UpdateAllianceFromMatch(allianceId int, matchTeamIds [
 	return nil
 }
 
+// Returns true if the given team was called into the alliance as its backup robot.
+func (alliance *Alliance) IsBackupTeam(teamId int) bool {
+	return alliance.Backup != nil && alliance.Backup.TeamId == teamId
+}
+
+// Returns true if the alliance has not yet called in a backup robot.
+func (alliance *Alliance) BackupAvailable() bool {
+	return alliance.Backup == nil
+}
+
+// Returns true if the alliance has not yet called a timeout in the given playoff round.
+func (alliance *Alliance) TimeoutAvailable(elimRound int) bool {
+	for _, timeout := range alliance.Timeouts {
+		if timeout.ElimRound == elimRound {
+			return false
+		}
+	}
+	return true
+}
+
+// Records a timeout called by the given alliance ahead of the given match. Returns an error if the alliance has already
+// used its timeout for the round.
+func (database *Database) UseAllianceTimeout(allianceId int, match *Match) error {
+	alliance, err := database.GetAllianceById(allianceId)
+	if err != nil {
+		return err
+	}
+	if alliance == nil {
+		return fmt.Errorf("alliance %d does not exist", allianceId)
+	}
+	if !alliance.TimeoutAvailable(match.ElimRound) {
+		return fmt.Errorf("alliance %d has already used its timeout in round %d", allianceId, match.ElimRound)
+	}
+
+	alliance.Timeouts = append(alliance.Timeouts, AllianceTimeout{ElimRound: match.ElimRound, MatchId: match.Id})
+	return database.UpdateAlliance(alliance)
+}
+
+// Returns the highest-ranked team that is not already part of any alliance, or zero if there are none left.
+func (database *Database) GetNextBackupTeamId() (int, error) {
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return 0, err
+	}
+	rankings, err := database.GetAllRankings()
+	if err != nil {
+		return 0, err
+	}
+
+	allianceTeams := make(map[int]bool)
+	for _, alliance := range alliances {
+		for _, allianceTeamId := range alliance.TeamIds {
+			allianceTeams[allianceTeamId] = true
+		}
+	}
+	for _, ranking := range rankings {
+		if !allianceTeams[ranking.TeamId] {
+			return ranking.TeamId, nil
+		}
+	}
+	return 0, nil
+}
+
+// Brings the given backup team into the alliance in place of the given team, effective from the given match onward.
+func (database *Database) InvokeBackupTeam(allianceId, replacedTeamId, backupTeamId int, fromMatch *Match) error {
+	alliances, err := database.GetAllAlliances()
+	if err != nil {
+		return err
+	}
+
+	var alliance *Alliance
+	for i := range alliances {
+		if alliances[i].Id == allianceId {
+			alliance = &alliances[i]
+		}
+		for _, allianceTeamId := range alliances[i].TeamIds {
+			if allianceTeamId == backupTeamId {
+				return fmt.Errorf("team %d is already part of alliance %d", backupTeamId, alliances[i].Id)
+			}
+		}
+	}
+	if alliance == nil {
+		return fmt.Errorf("alliance %d does not exist", allianceId)
+	}
+	if !alliance.BackupAvailable() {
+		return fmt.Errorf("alliance %d has already called in backup team %d", allianceId, alliance.Backup.TeamId)
+	}
+
+	position := -1
+	for i, teamId := range alliance.Lineup {
+		if teamId == replacedTeamId {
+			position = i
+			break
+		}
+	}
+	if position < 0 {
+		return fmt.Errorf("team %d is not in the current lineup of alliance %d", replacedTeamId, allianceId)
+	}
+
+	alliance.Lineup[position] = backupTeamId
+	alliance.TeamIds = append(alliance.TeamIds, backupTeamId)
+	alliance.Backup = &AllianceBackup{
+		TeamId:         backupTeamId,
+		ReplacedTeamId: replacedTeamId,
+		FromMatchId:    fromMatch.Id,
+		ElimRound:      fromMatch.ElimRound,
+	}
+	return database.UpdateAlliance(alliance)
+}
+
 // Returns two arrays containing the IDs of any teams for the red and blue alliances, respectively, who are part of the
 // elimination alliance but are not playing in the given match.
 // If the given match isn't an elimination match, empty arrays are returned.
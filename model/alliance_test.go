@@ -4,8 +4,10 @@
 package model
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetNonexistentAlliance(t *testing.T) {
@@ -133,3 +135,82 @@ func TestGetOffFieldTeamIds(t *testing.T) {
 	assert.Equal(t, []int{}, redOffFieldTeams)
 	assert.Equal(t, []int{254, 469}, blueOffFieldTeams)
 }
+
+func TestGetNextBackupTeamId(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	teamId, err := db.GetNextBackupTeamId()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, teamId)
+
+	BuildTestAlliances(db)
+	db.CreateRanking(&game.Ranking{TeamId: 254, Rank: 1})
+	db.CreateRanking(&game.Ranking{TeamId: 1114, Rank: 3})
+	db.CreateRanking(&game.Ranking{TeamId: 1718, Rank: 2})
+	db.CreateRanking(&game.Ranking{TeamId: 604, Rank: 4})
+	teamId, err = db.GetNextBackupTeamId()
+	assert.Nil(t, err)
+	assert.Equal(t, 1114, teamId)
+}
+
+func TestInvokeBackupTeam(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+	BuildTestAlliances(db)
+
+	match := &Match{Id: 10, ElimRound: 2}
+	err := db.InvokeBackupTeam(3, 469, 1114, match)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "alliance 3 does not exist", err.Error())
+	}
+	err = db.InvokeBackupTeam(1, 469, 1718, match)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "team 1718 is already part of alliance 2", err.Error())
+	}
+	err = db.InvokeBackupTeam(1, 74, 1114, match)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "team 74 is not in the current lineup of alliance 1", err.Error())
+	}
+
+	assert.Nil(t, db.InvokeBackupTeam(1, 254, 1114, match))
+	alliance, err := db.GetAllianceById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{254, 469, 2848, 74, 3175, 1114}, alliance.TeamIds)
+	assert.Equal(t, [3]int{469, 1114, 2848}, alliance.Lineup)
+	assert.Equal(t, AllianceBackup{TeamId: 1114, ReplacedTeamId: 254, FromMatchId: 10, ElimRound: 2}, *alliance.Backup)
+	assert.False(t, alliance.BackupAvailable())
+	assert.True(t, alliance.IsBackupTeam(1114))
+	assert.False(t, alliance.IsBackupTeam(254))
+
+	err = db.InvokeBackupTeam(1, 469, 604, &Match{Id: 11, ElimRound: 3})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "alliance 1 has already called in backup team 1114", err.Error())
+	}
+}
+
+func TestUseAllianceTimeout(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+	BuildTestAlliances(db)
+
+	err := db.UseAllianceTimeout(3, &Match{Id: 10, ElimRound: 1})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "alliance 3 does not exist", err.Error())
+	}
+
+	assert.Nil(t, db.UseAllianceTimeout(1, &Match{Id: 10, ElimRound: 1}))
+	err = db.UseAllianceTimeout(1, &Match{Id: 11, ElimRound: 1})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "alliance 1 has already used its timeout in round 1", err.Error())
+	}
+	assert.Nil(t, db.UseAllianceTimeout(2, &Match{Id: 11, ElimRound: 1}))
+	assert.Nil(t, db.UseAllianceTimeout(1, &Match{Id: 12, ElimRound: 2}))
+
+	alliance, err := db.GetAllianceById(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []AllianceTimeout{{ElimRound: 1, MatchId: 10}, {ElimRound: 2, MatchId: 12}}, alliance.Timeouts)
+	assert.False(t, alliance.TimeoutAvailable(1))
+	assert.False(t, alliance.TimeoutAvailable(2))
+	assert.True(t, alliance.TimeoutAvailable(3))
+}
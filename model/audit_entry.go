@@ -0,0 +1,190 @@
+// Model and datastore methods for the append-only log of administrative actions.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const auditRedactedValue = "[redacted]"
+
+// The names of the fields holding passwords, keys and webhook URLs (which usually embed an access token), whose values
+// are never written to the audit log or to event archives since both can be exported and shared. Team contact emails
+// are included too, as they are personal details that were only given to the event for sending notifications.
+var secretFields = map[string]bool{
+	"AdminPassword":          true,
+	"Ap2Password":            true,
+	"ApAdminWpaKey":          true,
+	"ApPassword":             true,
+	"ContactEmail":           true,
+	"ContactWebhookUrl":      true,
+	"NotificationWebhookUrl": true,
+	"PasswordHash":           true,
+	"SmtpPassword":           true,
+	"SwitchPassword":         true,
+	"TbaSecret":              true,
+	"WebhookUrl":             true,
+	"WpaKey":                 true,
+}
+
+// AuditEntry A record of a single administrative action, kept so that disputes can be resolved after the event.
+type AuditEntry struct {
+	Id            int       `db:"id"`
+	Timestamp     time.Time `db:"index"`
+	Username      string
+	Session       string
+	RemoteAddress string
+	Action        string `db:"index"`
+	Target        string
+	Before        string
+	After         string
+}
+
+// AuditFilter Criteria for selecting entries from the audit log, where empty values match all entries.
+type AuditFilter struct {
+	Action   string
+	Username string
+	Start    time.Time
+	End      time.Time
+}
+
+// CreateAuditEntry Appends the given entry to the audit log. Entries can't be changed or deleted once written.
+func (database *Database) CreateAuditEntry(entry *AuditEntry) error {
+	return database.auditEntryTable.create(entry)
+}
+
+// GetAuditEntries Returns the entries in the audit log that match the given filter, newest first.
+func (database *Database) GetAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	var err error
+	if filter.Action != "" {
+		entries, err = database.auditEntryTable.getByIndex("Action", filter.Action)
+	} else {
+		end := filter.End
+		if end.IsZero() {
+			end = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+		}
+		entries, err = database.auditEntryTable.getByIndexRange("Timestamp", filter.Start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingEntries []AuditEntry
+	for _, entry := range entries {
+		if filter.Username != "" && entry.Username != filter.Username ||
+			!filter.Start.IsZero() && entry.Timestamp.Before(filter.Start) ||
+			!filter.End.IsZero() && entry.Timestamp.After(filter.End) {
+			continue
+		}
+		matchingEntries = append(matchingEntries, entry)
+	}
+	sort.Slice(matchingEntries, func(i, j int) bool {
+		return matchingEntries[i].Id > matchingEntries[j].Id
+	})
+	return matchingEntries, nil
+}
+
+// ImportAuditEntries Appends the given entries taken from another database, such as the one replaced when restoring a
+// backup, so that its history isn't lost. Entries no newer than the latest one already in the log are skipped, since a
+// backup of the same event already holds the history up to the point at which it was taken.
+func (database *Database) ImportAuditEntries(entries []AuditEntry) error {
+	existingEntries, err := database.auditEntryTable.getAll()
+	if err != nil {
+		return err
+	}
+	var latest time.Time
+	for _, entry := range existingEntries {
+		if entry.Timestamp.After(latest) {
+			latest = entry.Timestamp
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Id < entries[j].Id
+	})
+	return database.RunInTransaction(func(database *Database) error {
+		for _, entry := range entries {
+			if !entry.Timestamp.After(latest) {
+				continue
+			}
+			entry.Id = 0
+			if err := database.CreateAuditEntry(&entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AuditValues Returns the JSON representations of the given values of something changed by an administrative action,
+// for recording in the audit log. Where both are structs only the fields that differ are kept, and the values of
+// sensitive fields such as passwords are redacted. A nil value is represented by an empty string.
+func AuditValues(before, after any) (string, string, error) {
+	beforeFields, beforeJson, err := auditFields(before)
+	if err != nil {
+		return "", "", err
+	}
+	afterFields, afterJson, err := auditFields(after)
+	if err != nil {
+		return "", "", err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if afterValue, ok := afterFields[key]; ok && bytes.Equal(value, afterValue) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+	if beforeJson, err = redactAuditFields(beforeFields, beforeJson); err != nil {
+		return "", "", err
+	}
+	if afterJson, err = redactAuditFields(afterFields, afterJson); err != nil {
+		return "", "", err
+	}
+	return beforeJson, afterJson, nil
+}
+
+// Returns the JSON representation of the given value along with its fields, if it is an object.
+func auditFields(value any) (map[string]json.RawMessage, string, error) {
+	if value == nil {
+		return nil, "", nil
+	}
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return nil, "", err
+	}
+	if string(valueJson) == "null" {
+		return nil, "", nil
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(valueJson, &fields) != nil {
+		return nil, string(valueJson), nil
+	}
+	return fields, string(valueJson), nil
+}
+
+// Returns the JSON representation of the given fields with any sensitive values redacted, or the given JSON if the
+// value isn't an object.
+func redactAuditFields(fields map[string]json.RawMessage, valueJson string) (string, error) {
+	if fields == nil {
+		return valueJson, nil
+	}
+	for key := range fields {
+		if secretFields[key] {
+			fields[key] = json.RawMessage(`"` + auditRedactedValue + `"`)
+		}
+	}
+	redactedJson, err := json.Marshal(fields)
+	return string(redactedJson), err
+}
+
+// Deletes the whole audit log, for use only when creating a new event as a copy of an existing one.
+func (database *Database) truncateAuditEntries() error {
+	return database.auditEntryTable.truncate()
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuditEntries(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	entries, err := db.GetAuditEntries(AuditFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	day1 := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	assert.Nil(t, db.CreateAuditEntry(&AuditEntry{Timestamp: day1, Username: "admin", Action: "team_add"}))
+	assert.Nil(t, db.CreateAuditEntry(&AuditEntry{Timestamp: day1, Username: "head_ref", Action: "team_edit"}))
+	assert.Nil(t, db.CreateAuditEntry(&AuditEntry{Timestamp: day2, Username: "admin", Action: "team_edit"}))
+
+	entries, err = db.GetAuditEntries(AuditFilter{})
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(entries)) {
+		assert.Equal(t, []int{3, 2, 1}, []int{entries[0].Id, entries[1].Id, entries[2].Id})
+	}
+	entries, _ = db.GetAuditEntries(AuditFilter{Action: "team_edit"})
+	assert.Equal(t, 2, len(entries))
+	entries, _ = db.GetAuditEntries(AuditFilter{Action: "team_edit", Username: "admin"})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, 3, entries[0].Id)
+	}
+	entries, _ = db.GetAuditEntries(AuditFilter{Start: day2})
+	assert.Equal(t, 1, len(entries))
+	entries, _ = db.GetAuditEntries(AuditFilter{Action: "team_edit", End: day1})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, 2, entries[0].Id)
+	}
+}
+
+func TestImportAuditEntries(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	start := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	db.CreateAuditEntry(&AuditEntry{Timestamp: start, Action: "team_add"})
+	db.CreateAuditEntry(&AuditEntry{Timestamp: start.Add(time.Minute), Action: "team_edit"})
+
+	// Entries no newer than the latest already in the log are assumed to be already there.
+	importedEntries := []AuditEntry{
+		{Id: 1, Timestamp: start, Action: "team_add"},
+		{Id: 2, Timestamp: start.Add(time.Minute), Action: "team_edit"},
+		{Id: 4, Timestamp: start.Add(3 * time.Minute), Action: "team_delete"},
+		{Id: 3, Timestamp: start.Add(2 * time.Minute), Action: "teams_clear"},
+	}
+	assert.Nil(t, db.ImportAuditEntries(importedEntries))
+	entries, _ := db.GetAuditEntries(AuditFilter{})
+	if assert.Equal(t, 4, len(entries)) {
+		assert.Equal(t, "team_delete", entries[0].Action)
+		assert.Equal(t, 4, entries[0].Id)
+		assert.Equal(t, "teams_clear", entries[1].Action)
+		assert.Equal(t, 3, entries[1].Id)
+	}
+}
+
+func TestAuditValues(t *testing.T) {
+	before, after, err := AuditValues(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", before)
+	assert.Equal(t, "", after)
+
+	before, after, err = AuditValues(5, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "5", before)
+	assert.Equal(t, "6", after)
+
+	// Only the fields that differ are kept.
+	team := Team{Id: 254, Nickname: "The Cheesy Poofs", WpaKey: "12345678"}
+	updatedTeam := team
+	updatedTeam.Nickname = "The Poofs"
+	updatedTeam.WpaKey = "87654321"
+	before, after, err = AuditValues(team, updatedTeam)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Nickname":"The Cheesy Poofs","WpaKey":"[redacted]"}`, before)
+	assert.Equal(t, `{"Nickname":"The Poofs","WpaKey":"[redacted]"}`, after)
+
+	// Sensitive fields are redacted even when there is nothing to compare against.
+	before, after, err = AuditValues(nil, team)
+	assert.Nil(t, err)
+	assert.Equal(t, "", before)
+	assert.Contains(t, after, `"Id":254`)
+	assert.Contains(t, after, `"WpaKey":"[redacted]"`)
+	assert.NotContains(t, after, "12345678")
+}
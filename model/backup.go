@@ -0,0 +1,227 @@
+// Functions for browsing the backups of event databases and keeping their number in check.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const backupTimeLayout = "20060102150405"
+
+var backupFilenameRe = regexp.MustCompile(`^(.+)_(\d{14})_(.+)\.db$`)
+
+// BackupInfo Details of a backup in the backups directory, as given by its filename.
+type BackupInfo struct {
+	Filename  string
+	EventName string
+	Reason    string
+	Time      time.Time
+	Size      int64
+}
+
+// SizeKb Returns the size of the backup in kilobytes, rounded up.
+func (backup BackupInfo) SizeKb() int64 {
+	return (backup.Size + 1023) / 1024
+}
+
+// BackupSummary The contents of a backup, for checking that it is the right one before restoring it.
+type BackupSummary struct {
+	EventName        string
+	NumTeams         int
+	NumMatches       int
+	NumMatchesPlayed int
+}
+
+// ListBackups Returns the backups in the backups directory, newest first.
+func ListBackups() ([]BackupInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(BaseDir, backupsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []BackupInfo
+	for _, entry := range entries {
+		backup, ok := parseBackupFilename(entry.Name())
+		if entry.IsDir() || !ok {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backup.Size = fileInfo.Size()
+		backups = append(backups, backup)
+	}
+	sortBackups(backups)
+	return backups, nil
+}
+
+// BackupPath Returns the path of the backup having the given filename, or an error if there is no such backup.
+func BackupPath(filename string) (string, error) {
+	if _, ok := parseBackupFilename(filename); !ok || filepath.Base(filename) != filename {
+		return "", fmt.Errorf("invalid backup filename '%s'", filename)
+	}
+	path := filepath.Join(BaseDir, backupsDir, filename)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("backup '%s' doesn't exist", filename)
+	}
+	return path, nil
+}
+
+// ReadBackupSummary Returns the summary of the contents of the backup having the given filename. The backup is read
+// from a copy, so that the backup itself is left untouched even if it needs migrating to be read.
+func ReadBackupSummary(filename string) (*BackupSummary, error) {
+	path, err := BackupPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	tempFile, err := os.CreateTemp("", "backup-preview-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile.Name())
+	if err = copyFile(path, tempFile); err != nil {
+		tempFile.Close()
+		return nil, err
+	}
+	tempFile.Close()
+
+	database, err := openDatabase(tempFile.Name(), false)
+	if err != nil {
+		return nil, fmt.Errorf("could not read backup '%s': %v", filename, err)
+	}
+	defer database.Close()
+	var summary BackupSummary
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		return nil, err
+	}
+	summary.EventName = eventSettings.Name
+	teams, err := database.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	summary.NumTeams = len(teams)
+	matches, err := database.matchTable.getAll()
+	if err != nil {
+		return nil, err
+	}
+	summary.NumMatches = len(matches)
+	for _, match := range matches {
+		if match.IsComplete() {
+			summary.NumMatchesPlayed++
+		}
+	}
+	return &summary, nil
+}
+
+// Copies the newly written backup at the given path to the mirror directory, if one is configured, and then deletes
+// the oldest backups of the event beyond the number to be kept, both in the backups directory and in the mirror. A
+// failure to mirror is only logged, since the mirror is commonly removable media that may not be present.
+func (database *Database) applyBackupPolicy(path, eventName string) error {
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		return err
+	}
+	if eventSettings.BackupMirrorDir != "" {
+		if err = mirrorBackup(path, eventSettings.BackupMirrorDir); err != nil {
+			log.Printf("Failed to mirror backup to %s: %v", eventSettings.BackupMirrorDir, err)
+		} else if err = pruneBackups(
+			eventSettings.BackupMirrorDir, eventName, eventSettings.BackupRetentionCount,
+		); err != nil {
+			log.Printf("Failed to prune mirrored backups in %s: %v", eventSettings.BackupMirrorDir, err)
+		}
+	}
+	return pruneBackups(filepath.Join(BaseDir, backupsDir), eventName, eventSettings.BackupRetentionCount)
+}
+
+// Deletes all but the given number of the most recent routine backups of the given event in the given directory. The
+// backups taken before destructive operations such as restores are always kept, as is everything if the number is zero.
+func pruneBackups(dir, eventName string, retentionCount int) error {
+	if retentionCount <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var backups []BackupInfo
+	for _, entry := range entries {
+		backup, ok := parseBackupFilename(entry.Name())
+		if entry.IsDir() || !ok || backup.EventName != backupEventName(eventName) ||
+			strings.HasPrefix(backup.Reason, "pre_") {
+			continue
+		}
+		backups = append(backups, backup)
+	}
+	sortBackups(backups)
+	for i := retentionCount; i < len(backups); i++ {
+		if err = os.Remove(filepath.Join(dir, backups[i].Filename)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copies the backup at the given path into the given directory.
+func mirrorBackup(path, mirrorDir string) error {
+	if err := os.MkdirAll(mirrorDir, 0755); err != nil {
+		return err
+	}
+	dest, err := os.Create(filepath.Join(mirrorDir, filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+	if err = copyFile(path, dest); err != nil {
+		dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+func copyFile(path string, dest io.Writer) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	_, err = io.Copy(dest, source)
+	return err
+}
+
+// Returns the form of the given event name used in backup filenames.
+func backupEventName(eventName string) string {
+	return strings.Replace(eventName, " ", "_", -1)
+}
+
+func parseBackupFilename(filename string) (BackupInfo, bool) {
+	matches := backupFilenameRe.FindStringSubmatch(filename)
+	if matches == nil {
+		return BackupInfo{}, false
+	}
+	backupTime, err := time.ParseInLocation(backupTimeLayout, matches[2], time.Local)
+	if err != nil {
+		return BackupInfo{}, false
+	}
+	return BackupInfo{Filename: filename, EventName: matches[1], Reason: matches[3], Time: backupTime}, true
+}
+
+// Sorts the given backups newest first, using the filename to order those taken within the same second.
+func sortBackups(backups []BackupInfo) {
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backups[i].Filename > backups[j].Filename
+	})
+}
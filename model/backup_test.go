@@ -0,0 +1,97 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListBackups(t *testing.T) {
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = "." }()
+
+	backups, err := ListBackups()
+	assert.Nil(t, err)
+	assert.Empty(t, backups)
+
+	database, err := OpenDatabase(filepath.Join(BaseDir, "event.db"))
+	assert.Nil(t, err)
+	defer database.Close()
+	database.CreateTeam(&Team{Id: 254})
+	database.CreateTeam(&Team{Id: 1114})
+	database.CreateMatch(&Match{Type: "qualification", DisplayName: "1", Status: game.RedWonMatch})
+	database.CreateMatch(&Match{Type: "qualification", DisplayName: "2"})
+	assert.Nil(t, database.Backup("Chezy Champs", "post_scheduling"))
+	os.WriteFile(filepath.Join(BaseDir, backupsDir, "not_a_backup.txt"), []byte{}, 0644)
+
+	backups, err = ListBackups()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(backups)) {
+		assert.Equal(t, "Chezy_Champs", backups[0].EventName)
+		assert.Equal(t, "post_scheduling", backups[0].Reason)
+		assert.WithinDuration(t, time.Now(), backups[0].Time, 2*time.Second)
+		assert.Greater(t, backups[0].Size, int64(0))
+
+		summary, err := ReadBackupSummary(backups[0].Filename)
+		assert.Nil(t, err)
+		assert.Equal(t, BackupSummary{"Untitled Event", 2, 2, 1}, *summary)
+	}
+
+	_, err = BackupPath("../event.db")
+	assert.NotNil(t, err)
+	_, err = ReadBackupSummary("Chezy_Champs_20230101000000_nonexistent.db")
+	assert.EqualError(t, err, "backup 'Chezy_Champs_20230101000000_nonexistent.db' doesn't exist")
+}
+
+func TestBackupRetentionAndMirror(t *testing.T) {
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = "." }()
+	mirrorDir := filepath.Join(t.TempDir(), "usb")
+	backupsPath := filepath.Join(BaseDir, backupsDir)
+	os.MkdirAll(backupsPath, 0755)
+	for _, filename := range []string{
+		"Chezy_Champs_20230101000000_post_qualification_match_1.db",
+		"Chezy_Champs_20230101000100_pre_restore.db",
+		"Chezy_Champs_20230101000200_post_qualification_match_2.db",
+		"Other_Event_20230101000000_post_qualification_match_1.db",
+	} {
+		os.WriteFile(filepath.Join(backupsPath, filename), []byte{}, 0644)
+	}
+
+	database, err := OpenDatabase(filepath.Join(BaseDir, "event.db"))
+	assert.Nil(t, err)
+	defer database.Close()
+	eventSettings, _ := database.GetEventSettings()
+	eventSettings.BackupRetentionCount = 2
+	eventSettings.BackupMirrorDir = mirrorDir
+	database.UpdateEventSettings(eventSettings)
+	assert.Nil(t, database.Backup("Chezy Champs", "post_qualification_match_3"))
+
+	// Only the oldest routine backup of this event should have been deleted.
+	backups, _ := ListBackups()
+	var filenames []string
+	for _, backup := range backups {
+		filenames = append(filenames, backup.Filename)
+	}
+	if assert.Equal(t, 4, len(filenames)) {
+		assert.Contains(t, filenames[0], "_post_qualification_match_3.db")
+		assert.Equal(
+			t,
+			[]string{
+				"Chezy_Champs_20230101000200_post_qualification_match_2.db",
+				"Chezy_Champs_20230101000100_pre_restore.db",
+				"Other_Event_20230101000000_post_qualification_match_1.db",
+			},
+			filenames[1:],
+		)
+	}
+
+	mirroredFiles, _ := os.ReadDir(mirrorDir)
+	if assert.Equal(t, 1, len(mirroredFiles)) {
+		assert.Equal(t, filenames[0], mirroredFiles[0].Name())
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/BotDogs4645/da/game"
@@ -21,24 +20,38 @@ const backupsDir = "db/backups"
 
 var BaseDir = "." // Mutable for testing
 
+var errDiscardTransaction = fmt.Errorf("transaction discarded")
+
 type Database struct {
-	Path               string
-	bolt               *bbolt.DB
-	allianceTable      *table[Alliance]
-	awardTable         *table[Award]
-	eventSettingsTable *table[EventSettings]
-	lowerThirdTable    *table[LowerThird]
-	matchTable         *table[Match]
-	matchResultTable   *table[MatchResult]
-	rankingTable       *table[game.Ranking]
-	scheduleBlockTable *table[ScheduleBlock]
-	sponsorSlideTable  *table[SponsorSlide]
-	teamTable          *table[Team]
-	userSessionTable   *table[UserSession]
+	Path                  string
+	bolt                  *bbolt.DB
+	tx                    *bbolt.Tx
+	allianceTable         *table[Alliance]
+	auditEntryTable       *table[AuditEntry]
+	awardTable            *table[Award]
+	displayTable          *table[Display]
+	eventSettingsTable    *table[EventSettings]
+	fieldSettingsTable    *table[FieldSettings]
+	lowerThirdTable       *table[LowerThird]
+	matchTable            *table[Match]
+	matchResultTable      *table[MatchResult]
+	publishOperationTable *table[PublishOperation]
+	rankingTable          *table[game.Ranking]
+	scheduleBlockTable    *table[ScheduleBlock]
+	sponsorSlideTable     *table[SponsorSlide]
+	teamTable             *table[Team]
+	userTable             *table[User]
+	userSessionTable      *table[UserSession]
 }
 
 // Opens the Bolt database at the given path, creating it if it doesn't exist.
 func OpenDatabase(filename string) (*Database, error) {
+	return openDatabase(filename, true)
+}
+
+// Opens the Bolt database at the given path, only backing it up before migrating it if requested, since a temporary
+// copy of a database doesn't need it.
+func openDatabase(filename string, backUpBeforeMigrating bool) (*Database, error) {
 	database := Database{Path: filename}
 	var err error
 	database.bolt, err = bbolt.Open(database.Path, 0644, &bbolt.Options{NoSync: true, Timeout: time.Second})
@@ -46,16 +59,31 @@ func OpenDatabase(filename string) (*Database, error) {
 		return nil, err
 	}
 
+	// Bring databases written by older versions up to date before any tables are read.
+	if err = database.migrate(backUpBeforeMigrating); err != nil {
+		database.bolt.Close()
+		return nil, err
+	}
+
 	// Register tables.
 	if database.allianceTable, err = newTable[Alliance](&database); err != nil {
 		return nil, err
 	}
+	if database.auditEntryTable, err = newTable[AuditEntry](&database); err != nil {
+		return nil, err
+	}
 	if database.awardTable, err = newTable[Award](&database); err != nil {
 		return nil, err
 	}
+	if database.displayTable, err = newTable[Display](&database); err != nil {
+		return nil, err
+	}
 	if database.eventSettingsTable, err = newTable[EventSettings](&database); err != nil {
 		return nil, err
 	}
+	if database.fieldSettingsTable, err = newTable[FieldSettings](&database); err != nil {
+		return nil, err
+	}
 	if database.lowerThirdTable, err = newTable[LowerThird](&database); err != nil {
 		return nil, err
 	}
@@ -65,6 +93,9 @@ func OpenDatabase(filename string) (*Database, error) {
 	if database.matchResultTable, err = newTable[MatchResult](&database); err != nil {
 		return nil, err
 	}
+	if database.publishOperationTable, err = newTable[PublishOperation](&database); err != nil {
+		return nil, err
+	}
 	if database.rankingTable, err = newTable[game.Ranking](&database); err != nil {
 		return nil, err
 	}
@@ -77,6 +108,9 @@ func OpenDatabase(filename string) (*Database, error) {
 	if database.teamTable, err = newTable[Team](&database); err != nil {
 		return nil, err
 	}
+	if database.userTable, err = newTable[User](&database); err != nil {
+		return nil, err
+	}
 	if database.userSessionTable, err = newTable[UserSession](&database); err != nil {
 		return nil, err
 	}
@@ -84,19 +118,70 @@ func OpenDatabase(filename string) (*Database, error) {
 	return &database, nil
 }
 
+// RunInTransaction Calls the given function with a view of the database in which every operation is part of a single
+// read-write transaction, which is committed if the function returns nil and rolled back otherwise. The function must
+// only use the database it is given, since other operations block until the transaction finishes. Calls made on a
+// database that is already in a transaction join that transaction.
+func (database *Database) RunInTransaction(f func(database *Database) error) error {
+	if database.tx != nil {
+		return f(database)
+	}
+	return database.bolt.Update(func(tx *bbolt.Tx) error {
+		txDatabase := *database
+		txDatabase.tx = tx
+		txDatabase.allianceTable = database.allianceTable.withTx(tx)
+		txDatabase.auditEntryTable = database.auditEntryTable.withTx(tx)
+		txDatabase.awardTable = database.awardTable.withTx(tx)
+		txDatabase.displayTable = database.displayTable.withTx(tx)
+		txDatabase.eventSettingsTable = database.eventSettingsTable.withTx(tx)
+		txDatabase.fieldSettingsTable = database.fieldSettingsTable.withTx(tx)
+		txDatabase.lowerThirdTable = database.lowerThirdTable.withTx(tx)
+		txDatabase.matchTable = database.matchTable.withTx(tx)
+		txDatabase.matchResultTable = database.matchResultTable.withTx(tx)
+		txDatabase.publishOperationTable = database.publishOperationTable.withTx(tx)
+		txDatabase.rankingTable = database.rankingTable.withTx(tx)
+		txDatabase.scheduleBlockTable = database.scheduleBlockTable.withTx(tx)
+		txDatabase.sponsorSlideTable = database.sponsorSlideTable.withTx(tx)
+		txDatabase.teamTable = database.teamTable.withTx(tx)
+		txDatabase.userTable = database.userTable.withTx(tx)
+		txDatabase.userSessionTable = database.userSessionTable.withTx(tx)
+		return f(&txDatabase)
+	})
+}
+
+// RunInDiscardedTransaction Calls the given function within a transaction like RunInTransaction, but always rolls it
+// back, for working out the effect of an operation without applying it. Can't be called on a database that is already
+// in a transaction, since its changes couldn't be discarded separately.
+func (database *Database) RunInDiscardedTransaction(f func(database *Database) error) error {
+	if database.tx != nil {
+		return fmt.Errorf("can't discard a transaction nested within another")
+	}
+	err := database.RunInTransaction(func(database *Database) error {
+		if err := f(database); err != nil {
+			return err
+		}
+		return errDiscardTransaction
+	})
+	if err == errDiscardTransaction {
+		return nil
+	}
+	return err
+}
+
 func (database *Database) Close() error {
 	return database.bolt.Close()
 }
 
-// Creates a copy of the current database and saves it to the backups directory.
+// Creates a copy of the current database and saves it to the backups directory, then mirrors it and applies the
+// retention policy given by the event settings.
 func (database *Database) Backup(eventName, reason string) error {
 	backupsPath := filepath.Join(BaseDir, backupsDir)
 	err := os.MkdirAll(backupsPath, 0755)
 	if err != nil {
 		return err
 	}
-	filename := fmt.Sprintf("%s/%s_%s_%s.db", backupsPath, strings.Replace(eventName, " ", "_", -1),
-		time.Now().Format("20060102150405"), reason)
+	filename := fmt.Sprintf("%s/%s_%s_%s.db", backupsPath, backupEventName(eventName),
+		time.Now().Format(backupTimeLayout), reason)
 
 	dest, err := os.Create(filename)
 	if err != nil {
@@ -107,11 +192,14 @@ func (database *Database) Backup(eventName, reason string) error {
 	if err = database.WriteBackup(dest); err != nil {
 		return err
 	}
-	return nil
+	return database.applyBackupPolicy(filename, eventName)
 }
 
 // Takes a snapshot of Bolt database and writes it to the given writer.
 func (database *Database) WriteBackup(writer io.Writer) error {
+	if database.tx != nil {
+		return fmt.Errorf("can't back up the database from within a transaction")
+	}
 	return database.bolt.View(func(tx *bbolt.Tx) error {
 		_, err := tx.WriteTo(writer)
 		return err
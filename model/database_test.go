@@ -16,3 +16,79 @@ func TestOpenUnreachableDatabase(t *testing.T) {
 func setupTestDb(t *testing.T) *Database {
 	return SetupTestDb(t, "model")
 }
+
+func TestRunInTransaction(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	// Check that changes to multiple tables are all committed together.
+	err := db.RunInTransaction(func(database *Database) error {
+		if err := database.CreateTeam(&Team{Id: 254}); err != nil {
+			return err
+		}
+		if err := database.CreateMatch(&Match{Type: "qualification", DisplayName: "1"}); err != nil {
+			return err
+		}
+
+		// Changes should be visible within the transaction, including through nested transactions.
+		return database.RunInTransaction(func(database *Database) error {
+			team, err := database.GetTeamById(254)
+			assert.NotNil(t, team)
+			return err
+		})
+	})
+	assert.Nil(t, err)
+	team, _ := db.GetTeamById(254)
+	assert.NotNil(t, team)
+	match, _ := db.GetMatchByName("qualification", "1")
+	assert.NotNil(t, match)
+
+	// Check that an error rolls back all the changes made in the transaction.
+	err = db.RunInTransaction(func(database *Database) error {
+		if err := database.CreateTeam(&Team{Id: 1114}); err != nil {
+			return err
+		}
+		if err := database.DeleteMatch(match.Id); err != nil {
+			return err
+		}
+		return database.CreateTeam(&Team{Id: 254})
+	})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Team with ID 254 already exists")
+	}
+	team, _ = db.GetTeamById(1114)
+	assert.Nil(t, team)
+	match, _ = db.GetMatchByName("qualification", "1")
+	assert.NotNil(t, match)
+
+	err = db.RunInTransaction(func(database *Database) error {
+		return database.WriteBackup(nil)
+	})
+	assert.NotNil(t, err)
+}
+
+func TestRunInDiscardedTransaction(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	var teams []Team
+	err := db.RunInDiscardedTransaction(func(database *Database) error {
+		if err := database.CreateTeam(&Team{Id: 254}); err != nil {
+			return err
+		}
+		var err error
+		teams, err = database.GetAllTeams()
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(teams))
+	team, _ := db.GetTeamById(254)
+	assert.Nil(t, team)
+
+	err = db.RunInTransaction(func(database *Database) error {
+		return database.RunInDiscardedTransaction(func(database *Database) error {
+			return nil
+		})
+	})
+	assert.NotNil(t, err)
+}
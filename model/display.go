@@ -0,0 +1,72 @@
+// Model and datastore CRUD methods for the saved configuration of a remote web display.
+
+package model
+
+import "sort"
+
+// Display The configuration assigned to a display, kept so that it can be restored when the display reconnects after
+// the server restarts. Type holds the path at which the display's type is served, such as "/displays/audience", which
+// unlike the field.DisplayType value doesn't change as types are added.
+type Display struct {
+	Id            int    `db:"id"`
+	DisplayId     string `db:"index"`
+	Nickname      string
+	Type          string
+	Configuration map[string]string
+}
+
+func (database *Database) CreateDisplay(display *Display) error {
+	return database.displayTable.create(display)
+}
+
+func (database *Database) GetDisplayByDisplayId(displayId string) (*Display, error) {
+	displays, err := database.displayTable.getByIndex("DisplayId", displayId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(displays) == 0 {
+		return nil, nil
+	}
+	return &displays[0], nil
+}
+
+func (database *Database) UpdateDisplay(display *Display) error {
+	return database.displayTable.update(display)
+}
+
+func (database *Database) DeleteDisplay(id int) error {
+	return database.displayTable.delete(id)
+}
+
+func (database *Database) TruncateDisplays() error {
+	return database.displayTable.truncate()
+}
+
+// ImportDisplays Replaces all the saved display configurations with the given ones, for carrying them over from another
+// database since they belong to the physical displays rather than to the event.
+func (database *Database) ImportDisplays(displays []Display) error {
+	return database.RunInTransaction(func(database *Database) error {
+		if err := database.TruncateDisplays(); err != nil {
+			return err
+		}
+		for _, display := range displays {
+			display.Id = 0
+			if err := database.CreateDisplay(&display); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (database *Database) GetAllDisplays() ([]Display, error) {
+	displays, err := database.displayTable.getAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(displays, func(i, j int) bool {
+		return displays[i].DisplayId < displays[j].DisplayId
+	})
+	return displays, nil
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentDisplay(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	display, err := db.GetDisplayByDisplayId("100")
+	assert.Nil(t, err)
+	assert.Nil(t, display)
+}
+
+func TestDisplayCrud(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	display := Display{
+		DisplayId: "254", Nickname: "Pit TV", Type: "/displays/pit", Configuration: map[string]string{"a": "b"},
+	}
+	assert.Nil(t, db.CreateDisplay(&display))
+	display2, err := db.GetDisplayByDisplayId("254")
+	assert.Nil(t, err)
+	assert.Equal(t, display, *display2)
+
+	display.Nickname = "Queue TV"
+	display.Configuration = map[string]string{}
+	assert.Nil(t, db.UpdateDisplay(&display))
+	display2, _ = db.GetDisplayByDisplayId("254")
+	assert.Equal(t, display, *display2)
+
+	db.CreateDisplay(&Display{DisplayId: "100", Type: "/displays/alliance_station"})
+	displays, err := db.GetAllDisplays()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(displays)) {
+		assert.Equal(t, "100", displays[0].DisplayId)
+		assert.Equal(t, "254", displays[1].DisplayId)
+	}
+
+	assert.Nil(t, db.DeleteDisplay(display.Id))
+	display2, err = db.GetDisplayByDisplayId("254")
+	assert.Nil(t, err)
+	assert.Nil(t, display2)
+	db.TruncateDisplays()
+	displays, _ = db.GetAllDisplays()
+	assert.Empty(t, displays)
+}
+
+func TestImportDisplays(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	db.CreateDisplay(&Display{DisplayId: "100", Type: "/displays/audience"})
+	displays := []Display{
+		{Id: 5, DisplayId: "101", Type: "/displays/pit", Configuration: map[string]string{"teamId": "254"}},
+		{Id: 6, DisplayId: "102", Nickname: "Queue TV", Type: "/displays/queueing"},
+	}
+	assert.Nil(t, db.ImportDisplays(displays))
+	importedDisplays, err := db.GetAllDisplays()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(importedDisplays)) {
+		assert.Equal(t, "101", importedDisplays[0].DisplayId)
+		assert.Equal(t, map[string]string{"teamId": "254"}, importedDisplays[0].Configuration)
+		assert.Equal(t, "Queue TV", importedDisplays[1].Nickname)
+	}
+}
@@ -0,0 +1,446 @@
+// Export of a whole event to a portable archive of JSON files, and the import that rebuilds an event database from it.
+
+package model
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BotDogs4645/da/game"
+	"go.etcd.io/bbolt"
+)
+
+// The version of the archive layout, which only changes if the layout itself changes rather than the fields of the
+// records within it, since missing and unknown fields are tolerated on import.
+const ArchiveFormatVersion = 1
+
+const (
+	archiveManifestFile = "manifest.json"
+	archiveAvatarsDir   = "avatars"
+)
+
+var archiveAvatarRe = regexp.MustCompile(`^` + archiveAvatarsDir + `/(\d+)\.png$`)
+
+// ArchiveManifest The description of an event archive, stored alongside its records.
+type ArchiveManifest struct {
+	FormatVersion int
+	SchemaVersion int
+	EventName     string
+	ExportedAt    time.Time
+}
+
+// The records held in an event archive, each written to a JSON file having the given name.
+type eventArchive struct {
+	EventSettings  *EventSettings  `archive:"event_settings.json"`
+	FieldSettings  []FieldSettings `archive:"field_settings.json"`
+	Teams          []Team          `archive:"teams.json"`
+	Matches        []Match         `archive:"matches.json"`
+	MatchResults   []MatchResult   `archive:"match_results.json"`
+	Rankings       []game.Ranking  `archive:"rankings.json"`
+	Alliances      []Alliance      `archive:"alliances.json"`
+	Awards         []Award         `archive:"awards.json"`
+	LowerThirds    []LowerThird    `archive:"lower_thirds.json"`
+	SponsorSlides  []SponsorSlide  `archive:"sponsor_slides.json"`
+	ScheduleBlocks []ScheduleBlock `archive:"schedule_blocks.json"`
+}
+
+// WriteArchive Writes a zipped archive of the event's settings, teams, schedule, results and other setup to the given
+// writer, along with the avatars of its teams from the given directory. Secrets such as passwords are left out, as
+// are the publishing queue, user sessions and audit log, which only make sense on this installation.
+func (database *Database) WriteArchive(writer io.Writer, avatarsDir string) error {
+	var archive eventArchive
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		return err
+	}
+	archive.EventSettings = eventSettings
+	if err = database.readArchiveRecords(&archive); err != nil {
+		return err
+	}
+	clearSecretFields(reflect.ValueOf(&archive).Elem())
+
+	schemaVersion, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	zipWriter := zip.NewWriter(writer)
+	manifest := ArchiveManifest{
+		FormatVersion: ArchiveFormatVersion,
+		SchemaVersion: schemaVersion,
+		EventName:     eventSettings.Name,
+		ExportedAt:    time.Now(),
+	}
+	if err = writeArchiveJson(zipWriter, archiveManifestFile, manifest); err != nil {
+		return err
+	}
+	archiveValue := reflect.ValueOf(archive)
+	for i := 0; i < archiveValue.NumField(); i++ {
+		name := archiveValue.Type().Field(i).Tag.Get("archive")
+		if err = writeArchiveJson(zipWriter, name, archiveValue.Field(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	for _, team := range archive.Teams {
+		avatar, err := os.ReadFile(filepath.Join(avatarsDir, fmt.Sprintf("%d.png", team.Id)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		file, err := zipWriter.Create(fmt.Sprintf("%s/%d.png", archiveAvatarsDir, team.Id))
+		if err != nil {
+			return err
+		}
+		if _, err = file.Write(avatar); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+// ImportArchive Creates a new event in the library from the given zipped event archive, writing any team avatars it
+// contains to the given directory. Archives written under an older schema are migrated, while those written by a newer
+// version of the software are refused. Returns the key of the new event.
+func (library *EventLibrary) ImportArchive(reader io.ReaderAt, size int64, avatarsDir string) (string, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return "", fmt.Errorf("not a valid event archive: %v", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	var manifest ArchiveManifest
+	if files[archiveManifestFile] == nil {
+		return "", fmt.Errorf("not a valid event archive: missing %s", archiveManifestFile)
+	}
+	if err = readArchiveJson(files, archiveManifestFile, &manifest); err != nil {
+		return "", err
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > ArchiveFormatVersion {
+		return "", fmt.Errorf(
+			"event archive format version %d isn't supported by this software, which reads up to version %d",
+			manifest.FormatVersion, ArchiveFormatVersion,
+		)
+	}
+	if manifest.SchemaVersion > LatestSchemaVersion() {
+		return "", fmt.Errorf(
+			"event archive schema version %d is newer than the latest version %d supported by this software; please "+
+				"upgrade it to import this archive", manifest.SchemaVersion, LatestSchemaVersion(),
+		)
+	}
+	var archive eventArchive
+	archiveValue := reflect.ValueOf(&archive).Elem()
+	if manifest.SchemaVersion < LatestSchemaVersion() {
+		if err = readMigratedArchive(files, manifest.SchemaVersion, &archive); err != nil {
+			return "", err
+		}
+	} else {
+		for i := 0; i < archiveValue.NumField(); i++ {
+			name := archiveValue.Type().Field(i).Tag.Get("archive")
+			if err = readArchiveJson(files, name, archiveValue.Field(i).Addr().Interface()); err != nil {
+				return "", err
+			}
+		}
+	}
+	clearSecretFields(archiveValue)
+
+	eventName := manifest.EventName
+	if archive.EventSettings != nil {
+		eventName = archive.EventSettings.Name
+	}
+	key, err := library.newEventKey(eventName)
+	if err != nil {
+		return "", err
+	}
+	path := library.path(key)
+	if err = writeArchiveDatabase(path, &archive, eventName); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to import event archive: %v", err)
+	}
+
+	for name, file := range files {
+		matches := archiveAvatarRe.FindStringSubmatch(name)
+		if matches == nil {
+			continue
+		}
+		teamId, _ := strconv.Atoi(matches[1])
+		if err = extractArchiveFile(file, filepath.Join(avatarsDir, fmt.Sprintf("%d.png", teamId))); err != nil {
+			return "", err
+		}
+	}
+	return key, nil
+}
+
+// Reads every record other than the event settings from the database into the given archive.
+func (database *Database) readArchiveRecords(archive *eventArchive) error {
+	var err error
+	if archive.FieldSettings, err = database.fieldSettingsTable.getAll(); err != nil {
+		return err
+	}
+	if archive.Teams, err = database.teamTable.getAll(); err != nil {
+		return err
+	}
+	if archive.Matches, err = database.matchTable.getAll(); err != nil {
+		return err
+	}
+	if archive.MatchResults, err = database.matchResultTable.getAll(); err != nil {
+		return err
+	}
+	if archive.Rankings, err = database.rankingTable.getAll(); err != nil {
+		return err
+	}
+	if archive.Alliances, err = database.allianceTable.getAll(); err != nil {
+		return err
+	}
+	if archive.Awards, err = database.awardTable.getAll(); err != nil {
+		return err
+	}
+	if archive.LowerThirds, err = database.lowerThirdTable.getAll(); err != nil {
+		return err
+	}
+	if archive.SponsorSlides, err = database.sponsorSlideTable.getAll(); err != nil {
+		return err
+	}
+	archive.ScheduleBlocks, err = database.scheduleBlockTable.getAll()
+	return err
+}
+
+// Reads the records of an archive written under the given older schema version into the given archive by way of a
+// temporary database, so that they are brought up to date by the same migrations as an event database would be.
+func readMigratedArchive(files map[string]*zip.File, schemaVersion int, archive *eventArchive) error {
+	tempDir, err := os.MkdirTemp("", "archive")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	path := filepath.Join(tempDir, "archive.db")
+	if err = writeRawArchiveDatabase(path, files, schemaVersion); err != nil {
+		return err
+	}
+	database, err := openDatabase(path, false)
+	if err != nil {
+		return fmt.Errorf("failed to migrate event archive: %v", err)
+	}
+	defer database.Close()
+
+	eventSettings, err := database.eventSettingsTable.getAll()
+	if err != nil {
+		return err
+	}
+	if len(eventSettings) > 0 {
+		archive.EventSettings = &eventSettings[0]
+	}
+	return database.readArchiveRecords(archive)
+}
+
+// Creates a new Bolt database at the given path holding the records of the archive exactly as they were written,
+// without registering any tables, and stamps it with the given schema version so that it is migrated when next opened.
+func writeRawArchiveDatabase(path string, files map[string]*zip.File, schemaVersion int) error {
+	bolt, err := bbolt.Open(path, 0644, &bbolt.Options{NoSync: true, Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	defer bolt.Close()
+	return bolt.Update(func(tx *bbolt.Tx) error {
+		if err := setSchemaVersion(tx, schemaVersion); err != nil {
+			return err
+		}
+		archiveType := reflect.TypeOf(eventArchive{})
+		for i := 0; i < archiveType.NumField(); i++ {
+			field := archiveType.Field(i)
+			name := field.Tag.Get("archive")
+			var records []map[string]json.RawMessage
+			if field.Type.Kind() == reflect.Slice {
+				if err := readArchiveJson(files, name, &records); err != nil {
+					return err
+				}
+			} else {
+				var record map[string]json.RawMessage
+				if err := readArchiveJson(files, name, &record); err != nil {
+					return err
+				}
+				if record != nil {
+					records = append(records, record)
+				}
+			}
+			if err := putRawRecords(tx, field.Type.Elem(), records); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Writes the given records to the bucket of the table for the given record type, keyed by their IDs.
+func putRawRecords(tx *bbolt.Tx, recordType reflect.Type, records []map[string]json.RawMessage) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(recordType.Name()))
+	if err != nil {
+		return err
+	}
+	idFieldName := ""
+	for i := 0; i < recordType.NumField() && idFieldName == ""; i++ {
+		for _, tag := range strings.Split(recordType.Field(i).Tag.Get("db"), ",") {
+			if tag == "id" {
+				idFieldName = recordType.Field(i).Name
+			}
+		}
+	}
+
+	for _, record := range records {
+		var id int
+		if idJson, ok := record[idFieldName]; ok {
+			if err = json.Unmarshal(idJson, &id); err != nil {
+				return fmt.Errorf("invalid ID %s for %s in event archive", idJson, recordType.Name())
+			}
+		}
+		key := idToKey(id)
+		if bucket.Get(key) != nil {
+			return fmt.Errorf("%s with ID %d appears more than once in event archive", recordType.Name(), id)
+		}
+		recordJson, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if err = bucket.Put(key, recordJson); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Creates a new database at the given path holding the records of the given archive, keeping their IDs so that the
+// references between them remain intact. Default settings are used if the archive doesn't include any.
+func writeArchiveDatabase(path string, archive *eventArchive, eventName string) error {
+	database, err := OpenDatabase(path)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	return database.RunInTransaction(func(database *Database) error {
+		if archive.EventSettings == nil {
+			if err := setEventName(database, eventName); err != nil {
+				return err
+			}
+		} else {
+			if archive.EventSettings.Id == 0 {
+				archive.EventSettings.Id = 1
+			}
+			if err := database.eventSettingsTable.createWithId(archive.EventSettings); err != nil {
+				return err
+			}
+		}
+		for _, importRecords := range []func() error{
+			func() error { return createRecordsWithIds(database.fieldSettingsTable, archive.FieldSettings) },
+			func() error { return createRecordsWithIds(database.teamTable, archive.Teams) },
+			func() error { return createRecordsWithIds(database.matchTable, archive.Matches) },
+			func() error { return createRecordsWithIds(database.matchResultTable, archive.MatchResults) },
+			func() error { return createRecordsWithIds(database.rankingTable, archive.Rankings) },
+			func() error { return createRecordsWithIds(database.allianceTable, archive.Alliances) },
+			func() error { return createRecordsWithIds(database.awardTable, archive.Awards) },
+			func() error { return createRecordsWithIds(database.lowerThirdTable, archive.LowerThirds) },
+			func() error { return createRecordsWithIds(database.sponsorSlideTable, archive.SponsorSlides) },
+			func() error { return createRecordsWithIds(database.scheduleBlockTable, archive.ScheduleBlocks) },
+		} {
+			if err := importRecords(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createRecordsWithIds[R any](table *table[R], records []R) error {
+	for i := range records {
+		if err := table.createWithId(&records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Zeroes the fields holding secrets within the given value, which may be a struct or a pointer to or slice of structs,
+// or contain them.
+func clearSecretFields(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !value.IsNil() {
+			clearSecretFields(value.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if secretFields[field.Name] {
+				value.Field(i).Set(reflect.Zero(field.Type))
+			} else {
+				clearSecretFields(value.Field(i))
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			clearSecretFields(value.Index(i))
+		}
+	}
+}
+
+func writeArchiveJson(zipWriter *zip.Writer, name string, value any) error {
+	file, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// Reads the given JSON file from the archive into the given value, leaving the value untouched if the file is absent.
+func readArchiveJson(files map[string]*zip.File, name string, value any) error {
+	file := files[name]
+	if file == nil {
+		return nil
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err = json.NewDecoder(reader).Decode(value); err != nil {
+		return fmt.Errorf("invalid %s in event archive: %v", strings.TrimSuffix(name, ".json"), err)
+	}
+	return nil
+}
+
+func extractArchiveFile(file *zip.File, path string) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	dest, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dest, reader); err != nil {
+		dest.Close()
+		return err
+	}
+	return dest.Close()
+}
@@ -0,0 +1,194 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+func TestEventArchiveRoundTrip(t *testing.T) {
+	library := NewEventLibrary(t.TempDir())
+	avatarsDir := t.TempDir()
+	key, _ := library.CreateEvent("Chezy Champs")
+	path, _ := library.EventPath(key)
+	database, err := OpenDatabase(path)
+	assert.Nil(t, err)
+	defer database.Close()
+	eventSettings, _ := database.GetEventSettings()
+	eventSettings.NumElimAlliances = 4
+	eventSettings.AdminPassword = "secret"
+	eventSettings.TbaSecret = "tbasecret"
+	eventSettings.WebhookUrl = "https://example.com/hook?token=webhooksecret"
+	database.UpdateEventSettings(eventSettings)
+	database.CreateTeam(
+		&Team{Id: 254, Nickname: "The Cheesy Poofs", WpaKey: "12345678", ContactEmail: "queue@team254.com"},
+	)
+	database.CreateTeam(&Team{Id: 1114})
+	os.WriteFile(filepath.Join(avatarsDir, "254.png"), []byte("avatar"), 0644)
+	database.CreateMatch(&Match{Type: "practice", DisplayName: "1"})
+	database.DeleteMatch(1)
+	database.CreateMatch(&Match{Type: "qualification", DisplayName: "1", Red1: 254, Blue1: 1114})
+	database.CreateMatchResult(BuildTestMatchResult(2, 1))
+	BuildTestAlliances(database)
+	database.CreateAward(&Award{Type: WinnerAward, AwardName: "Winner", TeamId: 254})
+	database.CreateLowerThird(&LowerThird{TopText: "Winner", AwardId: 1})
+	database.CreateSponsorSlide(&SponsorSlide{Subtitle: "Thanks"})
+	database.CreateUserSession(&UserSession{Token: "abc", Username: "admin"})
+
+	var archiveBuffer bytes.Buffer
+	assert.Nil(t, database.WriteArchive(&archiveBuffer, avatarsDir))
+	assert.NotContains(t, archiveBuffer.String(), "tbasecret")
+	assert.NotContains(t, archiveBuffer.String(), "webhooksecret")
+	assert.NotContains(t, archiveBuffer.String(), "queue@team254.com")
+
+	importedAvatarsDir := t.TempDir()
+	importedKey, err := library.ImportArchive(
+		bytes.NewReader(archiveBuffer.Bytes()), int64(archiveBuffer.Len()), importedAvatarsDir,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "chezy_champs_2", importedKey)
+	importedPath, _ := library.EventPath(importedKey)
+	imported, err := OpenDatabase(importedPath)
+	assert.Nil(t, err)
+	defer imported.Close()
+
+	importedSettings, _ := imported.GetEventSettings()
+	assert.Equal(t, "Chezy Champs", importedSettings.Name)
+	assert.Equal(t, 4, importedSettings.NumElimAlliances)
+	assert.Equal(t, "", importedSettings.AdminPassword)
+	assert.Equal(t, "", importedSettings.TbaSecret)
+	assert.Equal(t, "", importedSettings.WebhookUrl)
+	team, _ := imported.GetTeamById(254)
+	if assert.NotNil(t, team) {
+		assert.Equal(t, "The Cheesy Poofs", team.Nickname)
+		assert.Equal(t, "", team.WpaKey)
+		assert.Equal(t, "", team.ContactEmail)
+	}
+	avatar, _ := os.ReadFile(filepath.Join(importedAvatarsDir, "254.png"))
+	assert.Equal(t, "avatar", string(avatar))
+	_, err = os.Stat(filepath.Join(importedAvatarsDir, "1114.png"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Check that IDs are preserved so that the references between records remain intact.
+	match, _ := imported.GetMatchById(2)
+	if assert.NotNil(t, match) {
+		assert.Equal(t, 254, match.Red1)
+	}
+	matchResult, _ := imported.GetMatchResultForMatch(2)
+	if assert.NotNil(t, matchResult) {
+		assert.Equal(t, BuildTestMatchResult(2, 1).RedScore, matchResult.RedScore)
+	}
+	alliances, _ := imported.GetAllAlliances()
+	assert.Equal(t, 2, len(alliances))
+	lowerThirds, _ := imported.GetLowerThirdsByAwardId(1)
+	assert.Equal(t, 1, len(lowerThirds))
+	slides, _ := imported.GetAllSponsorSlides()
+	assert.Equal(t, 1, len(slides))
+	session, _ := imported.GetUserSessionByToken("abc")
+	assert.Nil(t, session)
+
+	// Check that new records are given IDs following the imported ones.
+	newMatch := Match{Type: "qualification", DisplayName: "2"}
+	assert.Nil(t, imported.CreateMatch(&newMatch))
+	assert.Equal(t, 3, newMatch.Id)
+}
+
+func TestEventArchiveImportMigratesOlderSchema(t *testing.T) {
+	library := NewEventLibrary(t.TempDir())
+	database := setupTestDb(t)
+	eventSettings, _ := database.GetEventSettings()
+	eventSettings.Name = "Old Event"
+	eventSettings.TbaSecret = "tbasecret"
+	database.UpdateEventSettings(eventSettings)
+	database.CreateTeam(&Team{Id: 254, Nickname: "The Cheesy Poofs"})
+	var archiveBuffer bytes.Buffer
+	assert.Nil(t, database.WriteArchive(&archiveBuffer, t.TempDir()))
+
+	// Add a migration that renames a field, as if the archive had been written by an older version of the software.
+	originalMigrations := migrations
+	defer func() { migrations = originalMigrations }()
+	migrations = append(append([]migration{}, originalMigrations...), migration{
+		"rename nickname", func(tx *bbolt.Tx) error {
+			return updateRecords(tx, "Team", func(record map[string]json.RawMessage) error {
+				record["Name"] = record["Nickname"]
+				delete(record, "Nickname")
+				return nil
+			})
+		},
+	})
+
+	key, err := library.ImportArchive(bytes.NewReader(archiveBuffer.Bytes()), int64(archiveBuffer.Len()), t.TempDir())
+	assert.Nil(t, err)
+	path, _ := library.EventPath(key)
+	imported, err := OpenDatabase(path)
+	assert.Nil(t, err)
+	defer imported.Close()
+	version, _ := imported.SchemaVersion()
+	assert.Equal(t, LatestSchemaVersion(), version)
+	importedSettings, _ := imported.GetEventSettings()
+	assert.Equal(t, "Old Event", importedSettings.Name)
+	assert.Equal(t, "", importedSettings.TbaSecret)
+	team, _ := imported.GetTeamById(254)
+	if assert.NotNil(t, team) {
+		assert.Equal(t, "The Cheesy Poofs", team.Name)
+		assert.Equal(t, "", team.Nickname)
+	}
+}
+
+func TestEventArchiveImportErrors(t *testing.T) {
+	library := NewEventLibrary(t.TempDir())
+	importArchive := func(files map[string]string) error {
+		var buffer bytes.Buffer
+		zipWriter := zip.NewWriter(&buffer)
+		for name, contents := range files {
+			file, _ := zipWriter.Create(name)
+			file.Write([]byte(contents))
+		}
+		zipWriter.Close()
+		_, err := library.ImportArchive(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), t.TempDir())
+		return err
+	}
+
+	_, err := library.ImportArchive(bytes.NewReader([]byte("not a zip")), 9, t.TempDir())
+	assert.NotNil(t, err)
+	assert.EqualError(t, importArchive(map[string]string{}), "not a valid event archive: missing manifest.json")
+	assert.EqualError(
+		t,
+		importArchive(map[string]string{"manifest.json": `{"FormatVersion": 2}`}),
+		"event archive format version 2 isn't supported by this software, which reads up to version 1",
+	)
+	assert.EqualError(
+		t,
+		importArchive(map[string]string{"manifest.json": `{"FormatVersion": 1, "SchemaVersion": 99}`}),
+		fmt.Sprintf(
+			"event archive schema version 99 is newer than the latest version %d supported by this software; "+
+				"please upgrade it to import this archive", LatestSchemaVersion(),
+		),
+	)
+	err = importArchive(map[string]string{"manifest.json": `{"FormatVersion": 1}`, "teams.json": "{"})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid teams in event archive")
+	}
+	err = importArchive(
+		map[string]string{"manifest.json": `{"FormatVersion": 1}`, "teams.json": `[{"Id": 254}, {"Id": 254}]`},
+	)
+	assert.NotNil(t, err)
+	events, _ := library.ListEvents(nil)
+	assert.Empty(t, events)
+
+	// Check that an archive holding only some of the records is accepted.
+	assert.Nil(t, importArchive(map[string]string{
+		"manifest.json": `{"FormatVersion": 1, "EventName": "Partial"}`, "teams.json": `[{"Id": 254}]`,
+	}))
+	events, _ = library.ListEvents(nil)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, "Partial", events[0].Name)
+	}
+}
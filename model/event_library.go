@@ -0,0 +1,335 @@
+// Functions for managing a library of events on the same installation, each kept in its own Bolt database file.
+
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	EventsDir           = "db/events"
+	eventArchiveDir     = "archive"
+	currentEventFile    = "current_event"
+	defaultEventKey     = "event"
+	eventDbExtension    = ".db"
+	eventTbaCacheSuffix = "_tba_cache"
+)
+
+var eventKeyInvalidChars = regexp.MustCompile("[^a-z0-9]+")
+
+// EventLibrary The set of event databases kept in a single directory, one of which is the current event.
+type EventLibrary struct {
+	Dir string
+}
+
+// EventInfo Summary of an event in the library.
+type EventInfo struct {
+	Key        string
+	Name       string
+	Archived   bool
+	Current    bool
+	ModifiedAt time.Time
+}
+
+func NewEventLibrary(dir string) *EventLibrary {
+	return &EventLibrary{Dir: dir}
+}
+
+// EventPath Returns the path of the database file of the non-archived event having the given key, or an error if there
+// is no such event.
+func (library *EventLibrary) EventPath(key string) (string, error) {
+	if !library.exists(key) {
+		return "", fmt.Errorf("event '%s' doesn't exist", key)
+	}
+	return library.path(key), nil
+}
+
+func (library *EventLibrary) path(key string) string {
+	return filepath.Join(library.Dir, key+eventDbExtension)
+}
+
+func (library *EventLibrary) archivePath(key string) string {
+	return filepath.Join(library.Dir, eventArchiveDir, key+eventDbExtension)
+}
+
+// CurrentEventKey Returns the key of the event that was most recently switched to, or the default one if there hasn't
+// been a switch yet.
+func (library *EventLibrary) CurrentEventKey() (string, error) {
+	key, err := os.ReadFile(filepath.Join(library.Dir, currentEventFile))
+	if os.IsNotExist(err) {
+		return defaultEventKey, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(key)), nil
+}
+
+// CurrentEventPath Returns the path of the database file of the current event, creating the library directory if
+// necessary.
+func (library *EventLibrary) CurrentEventPath() (string, error) {
+	if err := os.MkdirAll(library.Dir, 0755); err != nil {
+		return "", err
+	}
+	key, err := library.CurrentEventKey()
+	if err != nil {
+		return "", err
+	}
+	return library.path(key), nil
+}
+
+// SetCurrentEvent Records the given event as the one to open on startup.
+func (library *EventLibrary) SetCurrentEvent(key string) error {
+	if !library.exists(key) {
+		return fmt.Errorf("event '%s' doesn't exist", key)
+	}
+	return os.WriteFile(filepath.Join(library.Dir, currentEventFile), []byte(key+"\n"), 0644)
+}
+
+// ImportLegacyEvent Moves the single event database used by older versions into the library as the default event, if
+// there is one and the library doesn't already contain the default event.
+func (library *EventLibrary) ImportLegacyEvent(legacyPath string) error {
+	if _, err := os.Stat(legacyPath); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(library.Dir, 0755); err != nil {
+		return err
+	}
+	if library.exists(defaultEventKey) {
+		return nil
+	}
+	if err := os.Rename(legacyPath, library.path(defaultEventKey)); err != nil {
+		return err
+	}
+	return renameTbaCache(legacyPath, library.path(defaultEventKey))
+}
+
+// ListEvents Returns all the events in the library, with the current ones first and the archived ones last. The given
+// database is the one currently open, which is read from directly since its file is locked.
+func (library *EventLibrary) ListEvents(currentDatabase *Database) ([]EventInfo, error) {
+	var events []EventInfo
+	for _, archived := range []bool{false, true} {
+		dir := library.Dir
+		if archived {
+			dir = filepath.Join(library.Dir, eventArchiveDir)
+		}
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != eventDbExtension {
+				continue
+			}
+			fileInfo, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			event := EventInfo{
+				Key:        strings.TrimSuffix(entry.Name(), eventDbExtension),
+				Archived:   archived,
+				ModifiedAt: fileInfo.ModTime(),
+			}
+			path := filepath.Join(dir, entry.Name())
+			event.Current = currentDatabase != nil && sameFile(path, currentDatabase.Path)
+			err = withDatabase(path, currentDatabase, func(database *Database) error {
+				eventSettings, err := database.GetEventSettings()
+				if err != nil {
+					return err
+				}
+				event.Name = eventSettings.Name
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read event '%s': %v", event.Key, err)
+			}
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Archived != events[j].Archived {
+			return !events[i].Archived
+		}
+		return events[i].ModifiedAt.After(events[j].ModifiedAt)
+	})
+	return events, nil
+}
+
+// CreateEvent Creates a new empty event with the given name and default settings, and returns its key.
+func (library *EventLibrary) CreateEvent(name string) (string, error) {
+	key, err := library.newEventKey(name)
+	if err != nil {
+		return "", err
+	}
+	database, err := OpenDatabase(library.path(key))
+	if err != nil {
+		return "", err
+	}
+	defer database.Close()
+	return key, setEventName(database, name)
+}
+
+// CloneEvent Creates a new event with the given name as a copy of the given existing one, keeping its settings, teams
+// and other setup but none of its schedule, results, pending publishes or audit log. Returns the key of the new event.
+func (library *EventLibrary) CloneEvent(sourceKey, name string, currentDatabase *Database) (string, error) {
+	if !library.exists(sourceKey) {
+		return "", fmt.Errorf("event '%s' doesn't exist", sourceKey)
+	}
+	key, err := library.newEventKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	path := library.path(key)
+	err = withDatabase(library.path(sourceKey), currentDatabase, func(source *Database) error {
+		dest, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer dest.Close()
+		return source.WriteBackup(dest)
+	})
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	database, err := OpenDatabase(path)
+	if err != nil {
+		return "", err
+	}
+	defer database.Close()
+	for _, truncate := range []func() error{
+		database.TruncateMatches,
+		database.TruncateMatchResults,
+		database.TruncateRankings,
+		database.TruncateAlliances,
+		database.TruncateScheduleBlocks,
+		database.TruncatePublishOperations,
+		database.TruncateUserSessions,
+		database.truncateAuditEntries,
+	} {
+		if err = truncate(); err != nil {
+			return "", err
+		}
+	}
+	return key, setEventName(database, name)
+}
+
+// ArchiveEvent Moves the given event out of the list of active events. Neither the event that is open nor the one that
+// will be opened on startup can be archived.
+func (library *EventLibrary) ArchiveEvent(key string, currentDatabase *Database) error {
+	if !library.exists(key) {
+		return fmt.Errorf("event '%s' doesn't exist", key)
+	}
+	currentKey, err := library.CurrentEventKey()
+	if err != nil {
+		return err
+	}
+	if key == currentKey || currentDatabase != nil && sameFile(library.path(key), currentDatabase.Path) {
+		return fmt.Errorf("can't archive the current event")
+	}
+	if err = os.MkdirAll(filepath.Join(library.Dir, eventArchiveDir), 0755); err != nil {
+		return err
+	}
+	if err = os.Rename(library.path(key), library.archivePath(key)); err != nil {
+		return err
+	}
+	return renameTbaCache(library.path(key), library.archivePath(key))
+}
+
+// UnarchiveEvent Returns the given archived event to the list of active events.
+func (library *EventLibrary) UnarchiveEvent(key string) error {
+	if _, err := os.Stat(library.archivePath(key)); !isValidEventKey(key) || err != nil {
+		return fmt.Errorf("archived event '%s' doesn't exist", key)
+	}
+	if library.exists(key) {
+		return fmt.Errorf("an event with key '%s' already exists", key)
+	}
+	if err := os.Rename(library.archivePath(key), library.path(key)); err != nil {
+		return err
+	}
+	return renameTbaCache(library.archivePath(key), library.path(key))
+}
+
+// Returns whether a non-archived event with the given key exists.
+func (library *EventLibrary) exists(key string) bool {
+	if !isValidEventKey(key) {
+		return false
+	}
+	_, err := os.Stat(library.path(key))
+	return err == nil
+}
+
+// Returns whether the given key could have been generated by the library, so that it can't be used to refer to files
+// outside of it.
+func isValidEventKey(key string) bool {
+	return key != "" && !eventKeyInvalidChars.MatchString(strings.ReplaceAll(key, "_", ""))
+}
+
+// Returns a key derived from the given event name that isn't used by any existing or archived event.
+func (library *EventLibrary) newEventKey(name string) (string, error) {
+	baseKey := strings.Trim(eventKeyInvalidChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if baseKey == "" {
+		return "", fmt.Errorf("event name must contain at least one letter or number")
+	}
+	if err := os.MkdirAll(library.Dir, 0755); err != nil {
+		return "", err
+	}
+	key := baseKey
+	for i := 2; ; i++ {
+		_, err := os.Stat(library.path(key))
+		_, archivedErr := os.Stat(library.archivePath(key))
+		if os.IsNotExist(err) && os.IsNotExist(archivedErr) {
+			return key, nil
+		}
+		key = fmt.Sprintf("%s_%d", baseKey, i)
+	}
+}
+
+// Calls the given function with the database at the given path, using the given open database if it is the same file
+// since an open database can't be opened a second time.
+func withDatabase(path string, openDatabase *Database, f func(database *Database) error) error {
+	if openDatabase != nil && sameFile(path, openDatabase.Path) {
+		return f(openDatabase)
+	}
+	database, err := OpenDatabase(path)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	return f(database)
+}
+
+func setEventName(database *Database, name string) error {
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		return err
+	}
+	eventSettings.Name = name
+	return database.UpdateEventSettings(eventSettings)
+}
+
+// Moves the TBA cache belonging to the event database at the old path alongside the database's new path, if it exists.
+func renameTbaCache(oldDbPath, newDbPath string) error {
+	oldCacheDir := strings.TrimSuffix(oldDbPath, eventDbExtension) + eventTbaCacheSuffix
+	if _, err := os.Stat(oldCacheDir); os.IsNotExist(err) {
+		return nil
+	}
+	return os.Rename(oldCacheDir, strings.TrimSuffix(newDbPath, eventDbExtension)+eventTbaCacheSuffix)
+}
+
+func sameFile(path1, path2 string) bool {
+	fileInfo1, err1 := os.Stat(path1)
+	fileInfo2, err2 := os.Stat(path2)
+	return err1 == nil && err2 == nil && os.SameFile(fileInfo1, fileInfo2)
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLibraryCreateAndSwitch(t *testing.T) {
+	library := NewEventLibrary(t.TempDir())
+	currentPath, err := library.CurrentEventPath()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(library.Dir, "event.db"), currentPath)
+	events, err := library.ListEvents(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, events)
+
+	key, err := library.CreateEvent("Chezy Champs 2023")
+	assert.Nil(t, err)
+	assert.Equal(t, "chezy_champs_2023", key)
+	key, err = library.CreateEvent("Chezy Champs 2023!")
+	assert.Nil(t, err)
+	assert.Equal(t, "chezy_champs_2023_2", key)
+	_, err = library.CreateEvent(" !! ")
+	assert.NotNil(t, err)
+
+	events, err = library.ListEvents(nil)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(events)) {
+		assert.ElementsMatch(t, []string{"Chezy Champs 2023", "Chezy Champs 2023!"},
+			[]string{events[0].Name, events[1].Name})
+		assert.False(t, events[0].Current)
+	}
+
+	assert.Nil(t, library.SetCurrentEvent("chezy_champs_2023"))
+	currentPath, _ = library.CurrentEventPath()
+	assert.Equal(t, filepath.Join(library.Dir, "chezy_champs_2023.db"), currentPath)
+	assert.NotNil(t, library.SetCurrentEvent("nonexistent"))
+	assert.NotNil(t, library.SetCurrentEvent("../event"))
+	_, err = library.EventPath("../../event")
+	assert.NotNil(t, err)
+
+	// Check that the open database is read directly rather than being opened again.
+	database, err := OpenDatabase(currentPath)
+	assert.Nil(t, err)
+	defer database.Close()
+	events, err = library.ListEvents(database)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(events)) {
+		assert.True(t, events[0].Current || events[1].Current)
+	}
+}
+
+func TestEventLibraryClone(t *testing.T) {
+	library := NewEventLibrary(t.TempDir())
+	key, _ := library.CreateEvent("Week 1")
+	path, _ := library.EventPath(key)
+	database, err := OpenDatabase(path)
+	assert.Nil(t, err)
+	defer database.Close()
+	database.CreateTeam(&Team{Id: 254})
+	database.CreateMatch(&Match{Type: "qualification", DisplayName: "1"})
+	database.CreateMatchResult(BuildTestMatchResult(1, 1))
+	database.CreateAuditEntry(&AuditEntry{Timestamp: time.Now(), Action: "team_add"})
+	eventSettings, _ := database.GetEventSettings()
+	eventSettings.NumElimAlliances = 4
+	database.UpdateEventSettings(eventSettings)
+
+	cloneKey, err := library.CloneEvent(key, "Week 2", database)
+	assert.Nil(t, err)
+	assert.Equal(t, "week_2", cloneKey)
+	clonePath, _ := library.EventPath(cloneKey)
+	clone, err := OpenDatabase(clonePath)
+	assert.Nil(t, err)
+	defer clone.Close()
+	cloneSettings, _ := clone.GetEventSettings()
+	assert.Equal(t, "Week 2", cloneSettings.Name)
+	assert.Equal(t, 4, cloneSettings.NumElimAlliances)
+	teams, _ := clone.GetAllTeams()
+	assert.Equal(t, 1, len(teams))
+	matches, _ := clone.GetMatchesByType("qualification")
+	assert.Empty(t, matches)
+	matchResult, _ := clone.GetMatchResultForMatch(1)
+	assert.Nil(t, matchResult)
+	auditEntries, _ := clone.GetAuditEntries(AuditFilter{})
+	assert.Empty(t, auditEntries)
+
+	// Check that the source event is untouched.
+	eventSettings, _ = database.GetEventSettings()
+	assert.Equal(t, "Week 1", eventSettings.Name)
+	matches, _ = database.GetMatchesByType("qualification")
+	assert.Equal(t, 1, len(matches))
+
+	_, err = library.CloneEvent("nonexistent", "Week 3", database)
+	assert.NotNil(t, err)
+}
+
+func TestEventLibraryArchive(t *testing.T) {
+	library := NewEventLibrary(t.TempDir())
+	key1, _ := library.CreateEvent("Week 1")
+	key2, _ := library.CreateEvent("Week 2")
+	assert.Nil(t, library.SetCurrentEvent(key2))
+	assert.Nil(t, os.Mkdir(filepath.Join(library.Dir, key1+"_tba_cache"), 0755))
+
+	err := library.ArchiveEvent(key2, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "can't archive the current event", err.Error())
+	}
+	assert.Nil(t, library.ArchiveEvent(key1, nil))
+	_, err = library.EventPath(key1)
+	assert.NotNil(t, err)
+	assert.DirExists(t, filepath.Join(library.Dir, "archive", key1+"_tba_cache"))
+	events, _ := library.ListEvents(nil)
+	if assert.Equal(t, 2, len(events)) {
+		assert.Equal(t, key2, events[0].Key)
+		assert.False(t, events[0].Archived)
+		assert.Equal(t, key1, events[1].Key)
+		assert.True(t, events[1].Archived)
+	}
+
+	// Check that a new event can't take the key of an archived one.
+	key, _ := library.CreateEvent("Week 1")
+	assert.Equal(t, "week_1_2", key)
+
+	assert.Nil(t, library.UnarchiveEvent(key1))
+	_, err = library.EventPath(key1)
+	assert.Nil(t, err)
+	assert.DirExists(t, filepath.Join(library.Dir, key1+"_tba_cache"))
+	assert.NotNil(t, library.UnarchiveEvent(key1))
+}
+
+func TestEventLibraryImportLegacyEvent(t *testing.T) {
+	dir := t.TempDir()
+	library := NewEventLibrary(filepath.Join(dir, "events"))
+	assert.Nil(t, library.ImportLegacyEvent(filepath.Join(dir, "event.db")))
+
+	database, err := OpenDatabase(filepath.Join(dir, "event.db"))
+	assert.Nil(t, err)
+	database.CreateTeam(&Team{Id: 254})
+	database.Close()
+	assert.Nil(t, library.ImportLegacyEvent(filepath.Join(dir, "event.db")))
+	assert.NoFileExists(t, filepath.Join(dir, "event.db"))
+	path, _ := library.CurrentEventPath()
+	database, err = OpenDatabase(path)
+	assert.Nil(t, err)
+	defer database.Close()
+	team, _ := database.GetTeamById(254)
+	assert.NotNil(t, team)
+}
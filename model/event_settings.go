@@ -5,7 +5,11 @@
 
 package model
 
-import "github.com/BotDogs4645/da/game"
+import (
+	"time"
+
+	"github.com/BotDogs4645/da/game"
+)
 
 type EventSettings struct {
 	Id                          int `db:"id"`
@@ -14,11 +18,31 @@ type EventSettings struct {
 	NumElimAlliances            int
 	SelectionRound2Order        string
 	SelectionRound3Order        string
+	ElimTurnaroundMin           int
+	ElimTurnaroundEnforced      bool
 	TBADownloadEnabled          bool
+	InspectionRequired          bool
 	TbaPublishingEnabled        bool
 	TbaEventCode                string
 	TbaSecretId                 string
 	TbaSecret                   string
+	WebhookPublishingEnabled    bool
+	WebhookUrl                  string
+	FileExportEnabled           bool
+	FileExportDir               string
+	LivestreamVodUrl            string
+	TeamNotificationsEnabled    bool
+	NotificationMatchesAhead    int
+	NotificationWebhookUrl      string
+	SmtpHost                    string
+	SmtpPort                    int
+	SmtpUsername                string
+	SmtpPassword                string
+	SmtpFrom                    string
+	NotificationLogEnabled      bool
+	BackupRetentionCount        int
+	BackupMirrorDir             string
+	NumFields                   int
 	NetworkSecurityEnabled      bool
 	ApAddress                   string
 	ApUsername                  string
@@ -34,6 +58,9 @@ type EventSettings struct {
 	SwitchPassword              string
 	PlcAddress                  string
 	AdminPassword               string
+	SessionIdleTimeoutMin       int
+	SessionMaxAgeHours          int
+	HttpsEnabled                bool
 	WarmupDurationSec           int
 	AutoDurationSec             int
 	PauseDurationSec            int
@@ -58,10 +85,15 @@ func (database *Database) GetEventSettings() (*EventSettings, error) {
 		SelectionRound2Order:        "L",
 		SelectionRound3Order:        "",
 		TBADownloadEnabled:          true,
+		NotificationMatchesAhead:    2,
+		SmtpPort:                    25,
+		NumFields:                   1,
 		ApTeamChannel:               157,
 		ApAdminChannel:              0,
 		ApAdminWpaKey:               "1234Five",
 		Ap2TeamChannel:              0,
+		SessionIdleTimeoutMin:       120,
+		SessionMaxAgeHours:          24,
 		WarmupDurationSec:           game.MatchTiming.WarmupDurationSec,
 		AutoDurationSec:             game.MatchTiming.AutoDurationSec,
 		PauseDurationSec:            game.MatchTiming.PauseDurationSec,
@@ -78,3 +110,16 @@ func (database *Database) GetEventSettings() (*EventSettings, error) {
 func (database *Database) UpdateEventSettings(eventSettings *EventSettings) error {
 	return database.eventSettingsTable.update(eventSettings)
 }
+
+// SessionLimits Returns how long a login session may go unused and how long it may last in total before it expires,
+// where zero means no limit.
+func (eventSettings *EventSettings) SessionLimits() (time.Duration, time.Duration) {
+	return time.Duration(eventSettings.SessionIdleTimeoutMin) * time.Minute,
+		time.Duration(eventSettings.SessionMaxAgeHours) * time.Hour
+}
+
+// PublishingEnabled Returns true if results are published to at least one destination.
+func (eventSettings *EventSettings) PublishingEnabled() bool {
+	return eventSettings.TbaPublishingEnabled || eventSettings.WebhookPublishingEnabled ||
+		eventSettings.FileExportEnabled
+}
@@ -24,9 +24,14 @@ func TestEventSettingsReadWrite(t *testing.T) {
 			SelectionRound2Order:        "L",
 			SelectionRound3Order:        "",
 			TBADownloadEnabled:          true,
+			NotificationMatchesAhead:    2,
+			NumFields:                   1,
+			SmtpPort:                    25,
 			ApTeamChannel:               157,
 			ApAdminChannel:              0,
 			ApAdminWpaKey:               "1234Five",
+			SessionIdleTimeoutMin:       120,
+			SessionMaxAgeHours:          24,
 			WarmupDurationSec:           0,
 			AutoDurationSec:             15,
 			PauseDurationSec:            2,
@@ -0,0 +1,75 @@
+// Model and datastore CRUD methods for the hardware configuration of each additional field at the event.
+
+package model
+
+import "sort"
+
+// FieldSettings The per-field networking and PLC configuration of a field other than the first, whose configuration
+// lives in the event settings. The ID is the field number.
+type FieldSettings struct {
+	Id              int `db:"id,manual"`
+	DsListenAddress string
+	ApAddress       string
+	ApUsername      string
+	ApPassword      string
+	ApTeamChannel   int
+	Ap2Address      string
+	Ap2Username     string
+	Ap2Password     string
+	Ap2TeamChannel  int
+	SwitchAddress   string
+	SwitchPassword  string
+	PlcAddress      string
+}
+
+func (database *Database) CreateFieldSettings(fieldSettings *FieldSettings) error {
+	return database.fieldSettingsTable.create(fieldSettings)
+}
+
+func (database *Database) GetFieldSettingsById(id int) (*FieldSettings, error) {
+	return database.fieldSettingsTable.getById(id)
+}
+
+func (database *Database) UpdateFieldSettings(fieldSettings *FieldSettings) error {
+	return database.fieldSettingsTable.update(fieldSettings)
+}
+
+func (database *Database) DeleteFieldSettings(id int) error {
+	return database.fieldSettingsTable.delete(id)
+}
+
+func (database *Database) GetAllFieldSettings() ([]FieldSettings, error) {
+	allFieldSettings, err := database.fieldSettingsTable.getAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(allFieldSettings, func(i, j int) bool {
+		return allFieldSettings[i].Id < allFieldSettings[j].Id
+	})
+	return allFieldSettings, nil
+}
+
+// GetFieldSettings Returns the stored settings for the given field, or blank settings with the default channel if
+// none have been saved yet.
+func (database *Database) GetFieldSettings(fieldId int) (*FieldSettings, error) {
+	fieldSettings, err := database.GetFieldSettingsById(fieldId)
+	if err != nil {
+		return nil, err
+	}
+	if fieldSettings == nil {
+		fieldSettings = &FieldSettings{Id: fieldId, ApTeamChannel: 157}
+	}
+	return fieldSettings, nil
+}
+
+// SaveFieldSettings Creates or updates the stored settings for the field given by the settings' ID.
+func (database *Database) SaveFieldSettings(fieldSettings *FieldSettings) error {
+	existingFieldSettings, err := database.GetFieldSettingsById(fieldSettings.Id)
+	if err != nil {
+		return err
+	}
+	if existingFieldSettings == nil {
+		return database.CreateFieldSettings(fieldSettings)
+	}
+	return database.UpdateFieldSettings(fieldSettings)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNonexistentFieldSettings(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	fieldSettings, err := db.GetFieldSettingsById(2)
+	assert.Nil(t, err)
+	assert.Nil(t, fieldSettings)
+
+	// Check that defaults are returned for a field that hasn't been configured.
+	fieldSettings, err = db.GetFieldSettings(2)
+	assert.Nil(t, err)
+	assert.Equal(t, FieldSettings{Id: 2, ApTeamChannel: 157}, *fieldSettings)
+}
+
+func TestFieldSettingsCrud(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	fieldSettings := FieldSettings{Id: 3, DsListenAddress: "10.0.200.5", PlcAddress: "10.0.200.10"}
+	assert.Nil(t, db.SaveFieldSettings(&fieldSettings))
+	assert.Nil(t, db.SaveFieldSettings(&FieldSettings{Id: 2, DsListenAddress: "10.0.150.5"}))
+	fieldSettings2, err := db.GetFieldSettings(3)
+	assert.Nil(t, err)
+	assert.Equal(t, fieldSettings, *fieldSettings2)
+
+	fieldSettings.SwitchAddress = "10.0.200.2"
+	assert.Nil(t, db.SaveFieldSettings(&fieldSettings))
+	fieldSettings2, err = db.GetFieldSettingsById(3)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.200.2", fieldSettings2.SwitchAddress)
+
+	allFieldSettings, err := db.GetAllFieldSettings()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(allFieldSettings)) {
+		assert.Equal(t, 2, allFieldSettings[0].Id)
+		assert.Equal(t, 3, allFieldSettings[1].Id)
+	}
+
+	assert.Nil(t, db.DeleteFieldSettings(3))
+	fieldSettings2, err = db.GetFieldSettingsById(3)
+	assert.Nil(t, err)
+	assert.Nil(t, fieldSettings2)
+}
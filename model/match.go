@@ -14,8 +14,8 @@ import (
 )
 
 type Match struct {
-	Id               int `db:"id"`
-	Type             string
+	Id               int    `db:"id"`
+	Type             string `db:"index"`
 	DisplayName      string
 	Time             time.Time
 	ElimRound        int
@@ -38,6 +38,9 @@ type Match struct {
 	StartedAt        time.Time
 	ScoreCommittedAt time.Time
 	Status           game.MatchStatus
+	VideoUrl         string
+	VodOffsetSec     int
+	FieldId          int
 }
 
 func (database *Database) CreateMatch(match *Match) error {
@@ -48,6 +51,11 @@ func (database *Database) GetMatchById(id int) (*Match, error) {
 	return database.matchTable.getById(id)
 }
 
+// GetAllMatches Returns every match of every type, ordered by ID.
+func (database *Database) GetAllMatches() ([]Match, error) {
+	return database.matchTable.getAll()
+}
+
 func (database *Database) UpdateMatch(match *Match) error {
 	return database.matchTable.update(match)
 }
@@ -61,13 +69,13 @@ func (database *Database) TruncateMatches() error {
 }
 
 func (database *Database) GetMatchByName(matchType string, displayName string) (*Match, error) {
-	matches, err := database.matchTable.getAll()
+	matches, err := database.matchTable.getByIndex("Type", matchType)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, match := range matches {
-		if match.Type == matchType && match.DisplayName == displayName {
+		if match.DisplayName == displayName {
 			return &match, nil
 		}
 	}
@@ -90,18 +98,11 @@ func (database *Database) GetMatchesByElimRoundGroup(round int, group int) ([]Ma
 }
 
 func (database *Database) GetMatchesByType(matchType string) ([]Match, error) {
-	matches, err := database.matchTable.getAll()
+	matchingMatches, err := database.matchTable.getByIndex("Type", matchType)
 	if err != nil {
 		return nil, err
 	}
 
-	var matchingMatches []Match
-	for _, match := range matches {
-		if match.Type == matchType {
-			matchingMatches = append(matchingMatches, match)
-		}
-	}
-
 	sort.Slice(matchingMatches, func(i, j int) bool {
 		if matchingMatches[i].ElimRound == matchingMatches[j].ElimRound {
 			if matchingMatches[i].ElimInstance == matchingMatches[j].ElimInstance {
@@ -139,6 +140,12 @@ func (match *Match) TypePrefix() string {
 	return ""
 }
 
+// IsOnField Returns true if the match may be played on the given field. Matches not assigned to a specific field may be
+// played on any of them.
+func (match *Match) IsOnField(fieldId int) bool {
+	return match.FieldId == 0 || match.FieldId == fieldId
+}
+
 // Returns true if the match is of a type that allows substitution of teams.
 func (match *Match) ShouldAllowSubstitution() bool {
 	return match.Type != "qualification"
@@ -25,7 +25,7 @@ func TestMatchCrud(t *testing.T) {
 	defer db.Close()
 
 	match := Match{0, "qualification", "254", time.Now().UTC(), 0, 0, 0, 0, 0, 1, false, 2, false, 3, false, 4, false,
-		5, false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed}
+		5, false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed, "", 0, 0}
 	db.CreateMatch(&match)
 	match2, err := db.GetMatchById(1)
 	assert.Nil(t, err)
@@ -51,7 +51,7 @@ func TestTruncateMatches(t *testing.T) {
 	defer db.Close()
 
 	match := Match{0, "qualification", "254", time.Now().UTC(), 0, 0, 0, 0, 0, 1, false, 2, false, 3, false, 4, false,
-		5, false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed}
+		5, false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed, "", 0, 0}
 	db.CreateMatch(&match)
 	db.TruncateMatches()
 	match2, err := db.GetMatchById(1)
@@ -94,13 +94,13 @@ func TestGetMatchesByType(t *testing.T) {
 	defer db.Close()
 
 	match := Match{0, "qualification", "1", time.Now().UTC(), 0, 0, 0, 0, 0, 1, false, 2, false, 3, false, 4, false,
-		5, false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed}
+		5, false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed, "", 0, 0}
 	db.CreateMatch(&match)
 	match2 := Match{0, "practice", "1", time.Now().UTC(), 0, 0, 0, 0, 0, 1, false, 2, false, 3, false, 4, false, 5,
-		false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed}
+		false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed, "", 0, 0}
 	db.CreateMatch(&match2)
 	match3 := Match{0, "practice", "2", time.Now().UTC(), 0, 0, 0, 0, 0, 1, false, 2, false, 3, false, 4, false, 5,
-		false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed}
+		false, 6, false, time.Now().UTC(), time.Now().UTC(), game.MatchNotPlayed, "", 0, 0}
 	db.CreateMatch(&match3)
 
 	matches, err := db.GetMatchesByType("test")
@@ -113,3 +113,13 @@ func TestGetMatchesByType(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(matches))
 }
+
+func TestMatchIsOnField(t *testing.T) {
+	match := Match{}
+	assert.True(t, match.IsOnField(1))
+	assert.True(t, match.IsOnField(2))
+
+	match.FieldId = 2
+	assert.False(t, match.IsOnField(1))
+	assert.True(t, match.IsOnField(2))
+}
@@ -0,0 +1,187 @@
+// Versioning of the database schema and the migrations that bring databases written by older versions up to date.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var schemaVersionBucketKey = []byte("SchemaVersion")
+var schemaVersionKey = []byte("version")
+
+// A change to the way data is stored, which brings a database from the previous schema version up to this one.
+type migration struct {
+	description string
+	migrate     func(tx *bbolt.Tx) error
+}
+
+// The migrations that have been made to the schema over time, in order. The schema version of a database is the number
+// of these that have been applied to it, so migrations must only ever be appended to this list. Mutable for testing.
+var migrations = []migration{
+	{
+		description: "stamp databases created before the schema was versioned",
+		migrate:     func(tx *bbolt.Tx) error { return nil },
+	},
+}
+
+// LatestSchemaVersion Returns the schema version that databases are migrated to when opened.
+func LatestSchemaVersion() int {
+	return len(migrations)
+}
+
+// SchemaVersion Returns the schema version of the database.
+func (database *Database) SchemaVersion() (int, error) {
+	var version int
+	err := database.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		version, err = getSchemaVersion(tx)
+		return err
+	})
+	return version, err
+}
+
+// Brings the database up to the latest schema version by running any migrations it hasn't yet had, in a single
+// transaction and after taking a backup if requested. Refuses to touch a database written by a newer version of the
+// software.
+func (database *Database) migrate(backUp bool) error {
+	var version int
+	var isEmpty bool
+	err := database.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		if version, err = getSchemaVersion(tx); err != nil {
+			return err
+		}
+		isEmpty = tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			return fmt.Errorf("not empty")
+		}) == nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if version > LatestSchemaVersion() {
+		return fmt.Errorf(
+			"database schema version %d is newer than the latest version %d supported by this software; please "+
+				"upgrade it to open this database", version, LatestSchemaVersion(),
+		)
+	}
+	if version == LatestSchemaVersion() {
+		return nil
+	}
+
+	// A brand new database has nothing to migrate and starts out at the latest version.
+	if isEmpty {
+		return database.bolt.Update(func(tx *bbolt.Tx) error {
+			return setSchemaVersion(tx, LatestSchemaVersion())
+		})
+	}
+
+	if backUp {
+		if err = database.backupBeforeMigration(version); err != nil {
+			return fmt.Errorf("failed to back up database before migrating it: %v", err)
+		}
+	}
+	return database.bolt.Update(func(tx *bbolt.Tx) error {
+		for i := version; i < LatestSchemaVersion(); i++ {
+			log.Printf("Migrating database schema to version %d: %s", i+1, migrations[i].description)
+			if err := migrations[i].migrate(tx); err != nil {
+				return fmt.Errorf("failed to migrate database schema to version %d: %v", i+1, err)
+			}
+		}
+		return setSchemaVersion(tx, LatestSchemaVersion())
+	})
+}
+
+// Writes a copy of the database to the backups directory, named after its file since the event settings can't be read
+// until it has been migrated.
+func (database *Database) backupBeforeMigration(version int) error {
+	backupsPath := filepath.Join(BaseDir, backupsDir)
+	if err := os.MkdirAll(backupsPath, 0755); err != nil {
+		return err
+	}
+	baseName := strings.TrimSuffix(filepath.Base(database.Path), filepath.Ext(database.Path))
+	filename := fmt.Sprintf("%s/%s_%s_pre_migration_v%d.db", backupsPath, baseName,
+		time.Now().Format("20060102150405"), version)
+	dest, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	return database.WriteBackup(dest)
+}
+
+// Returns the schema version stamped in the database, or zero if there isn't one.
+func getSchemaVersion(tx *bbolt.Tx) (int, error) {
+	bucket := tx.Bucket(schemaVersionBucketKey)
+	if bucket == nil {
+		return 0, nil
+	}
+	value := bucket.Get(schemaVersionKey)
+	if value == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid database schema version '%s'", value)
+	}
+	return version, nil
+}
+
+func setSchemaVersion(tx *bbolt.Tx, version int) error {
+	bucket, err := tx.CreateBucketIfNotExists(schemaVersionBucketKey)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+}
+
+// Helper for use by migrations that applies the given function to the JSON fields of every record in the given table,
+// so that data can be moved between fields before the structs stop reading it. Does nothing if the table doesn't exist.
+// The table's indexes are dropped, to be rebuilt from the updated records when the table is next registered.
+func updateRecords(tx *bbolt.Tx, tableName string, update func(record map[string]json.RawMessage) error) error {
+	bucket := tx.Bucket([]byte(tableName))
+	if bucket == nil {
+		return nil
+	}
+	if tx.Bucket(indexBucketKey(tableName)) != nil {
+		if err := tx.DeleteBucket(indexBucketKey(tableName)); err != nil {
+			return err
+		}
+	}
+	updatedRecords := make(map[string][]byte)
+	err := bucket.ForEach(func(key, value []byte) error {
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		if err := update(record); err != nil {
+			return fmt.Errorf("%s with ID %s: %v", tableName, key, err)
+		}
+		recordJson, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		updatedRecords[string(key)] = recordJson
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Bolt doesn't allow modifying a bucket while iterating over it, so write the updated records afterwards.
+	for key, recordJson := range updatedRecords {
+		if err = bucket.Put([]byte(key), recordJson); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+func TestNewDatabaseHasLatestSchemaVersion(t *testing.T) {
+	database := setupTestDb(t)
+	defer database.Close()
+
+	version, err := database.SchemaVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	BaseDir = t.TempDir()
+	dbPath := filepath.Join(BaseDir, "legacy.db")
+	writeLegacyTeams(t, dbPath, 3)
+
+	// Replace the registry with migrations that rename a field and record the order in which they ran.
+	originalMigrations := migrations
+	defer func() { migrations = originalMigrations }()
+	var applied []string
+	migrations = []migration{
+		{"first", func(tx *bbolt.Tx) error { applied = append(applied, "first"); return nil }},
+		{"rename nickname", func(tx *bbolt.Tx) error {
+			applied = append(applied, "rename nickname")
+			return updateRecords(tx, "Team", func(record map[string]json.RawMessage) error {
+				record["Name"] = record["OldNickname"]
+				delete(record, "OldNickname")
+				return nil
+			})
+		}},
+	}
+
+	database, err := OpenDatabase(dbPath)
+	assert.Nil(t, err)
+	defer database.Close()
+	assert.Equal(t, []string{"first", "rename nickname"}, applied)
+	version, _ := database.SchemaVersion()
+	assert.Equal(t, 2, version)
+	for i := 1; i <= 3; i++ {
+		team, err := database.GetTeamById(i)
+		if assert.Nil(t, err) && assert.NotNil(t, team) {
+			assert.Equal(t, fmt.Sprintf("Team %d", i), team.Name)
+		}
+	}
+
+	// Check that a backup was taken before migrating.
+	backups, _ := filepath.Glob(filepath.Join(BaseDir, backupsDir, "legacy_*_pre_migration_v0.db"))
+	assert.Equal(t, 1, len(backups))
+
+	// Check that migrations aren't run again when the database is reopened.
+	database.Close()
+	database, err = OpenDatabase(dbPath)
+	assert.Nil(t, err)
+	defer database.Close()
+	assert.Equal(t, 2, len(applied))
+}
+
+func TestMigrationFailureLeavesDatabaseUntouched(t *testing.T) {
+	BaseDir = t.TempDir()
+	dbPath := filepath.Join(BaseDir, "legacy.db")
+	writeLegacyTeams(t, dbPath, 1)
+
+	originalMigrations := migrations
+	defer func() { migrations = originalMigrations }()
+	migrations = []migration{
+		{"rename nickname", func(tx *bbolt.Tx) error {
+			return updateRecords(tx, "Team", func(record map[string]json.RawMessage) error {
+				delete(record, "OldNickname")
+				return nil
+			})
+		}},
+		{"broken", func(tx *bbolt.Tx) error { return fmt.Errorf("oops") }},
+	}
+	_, err := OpenDatabase(dbPath)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "failed to migrate database schema to version 2: oops", err.Error())
+	}
+
+	migrations = originalMigrations[:0]
+	database, err := OpenDatabase(dbPath)
+	assert.Nil(t, err)
+	defer database.Close()
+	version, _ := database.SchemaVersion()
+	assert.Equal(t, 0, version)
+	database.bolt.View(func(tx *bbolt.Tx) error {
+		assert.Contains(t, string(tx.Bucket([]byte("Team")).Get(idToKey(1))), "OldNickname")
+		return nil
+	})
+}
+
+func TestRefuseNewerDatabase(t *testing.T) {
+	database := setupTestDb(t)
+	database.bolt.Update(func(tx *bbolt.Tx) error {
+		return setSchemaVersion(tx, LatestSchemaVersion()+1)
+	})
+	database.Close()
+
+	_, err := OpenDatabase(database.Path)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "is newer than the latest version")
+	}
+}
+
+// Writes a database as it would have been before schema versioning, containing teams with a since-renamed field.
+func writeLegacyTeams(t *testing.T, dbPath string, numTeams int) {
+	os.Remove(dbPath)
+	legacyDb, err := bbolt.Open(dbPath, 0644, nil)
+	assert.Nil(t, err)
+	err = legacyDb.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("Team"))
+		if err != nil {
+			return err
+		}
+		for i := 1; i <= numTeams; i++ {
+			if err = bucket.Put(idToKey(i), []byte(fmt.Sprintf(`{"Id":%d,"OldNickname":"Team %d"}`, i, i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, legacyDb.Close())
+}
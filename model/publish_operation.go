@@ -0,0 +1,83 @@
+// Model and datastore CRUD methods for an entry in the outbox of results waiting to be published.
+
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// PublishOperation Tracks the publishing of one resource (e.g. "matches") to one publisher (e.g. "TBA"). There is at
+// most one record per pair, so that a newer update to a resource supersedes any that haven't been sent yet.
+type PublishOperation struct {
+	Id            int    `db:"id"`
+	Publisher     string `db:"index"`
+	Resource      string
+	Pending       bool
+	QueuedAt      time.Time
+	Attempts      int
+	NextAttemptAt time.Time
+	LastAttemptAt time.Time
+	LastError     string
+	LastSuccessAt time.Time
+}
+
+// Status Returns a short description of the state of the operation for display.
+func (operation *PublishOperation) Status() string {
+	if !operation.Pending {
+		return "succeeded"
+	}
+	if operation.Attempts > 0 {
+		return "failed"
+	}
+	return "queued"
+}
+
+func (database *Database) CreatePublishOperation(operation *PublishOperation) error {
+	return database.publishOperationTable.create(operation)
+}
+
+func (database *Database) GetPublishOperationById(id int) (*PublishOperation, error) {
+	return database.publishOperationTable.getById(id)
+}
+
+func (database *Database) GetPublishOperation(publisher, resource string) (*PublishOperation, error) {
+	operations, err := database.publishOperationTable.getByIndex("Publisher", publisher)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operation := range operations {
+		if operation.Resource == resource {
+			return &operation, nil
+		}
+	}
+	return nil, nil
+}
+
+func (database *Database) UpdatePublishOperation(operation *PublishOperation) error {
+	return database.publishOperationTable.update(operation)
+}
+
+func (database *Database) DeletePublishOperation(id int) error {
+	return database.publishOperationTable.delete(id)
+}
+
+func (database *Database) TruncatePublishOperations() error {
+	return database.publishOperationTable.truncate()
+}
+
+// GetAllPublishOperations Returns all the outbox entries, ordered by publisher and then resource.
+func (database *Database) GetAllPublishOperations() ([]PublishOperation, error) {
+	operations, err := database.publishOperationTable.getAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Publisher != operations[j].Publisher {
+			return operations[i].Publisher < operations[j].Publisher
+		}
+		return operations[i].Resource < operations[j].Resource
+	})
+	return operations, nil
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNonexistentPublishOperation(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	operation, err := db.GetPublishOperation("TBA", "matches")
+	assert.Nil(t, err)
+	assert.Nil(t, operation)
+}
+
+func TestPublishOperationCrud(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	operation := PublishOperation{Publisher: "webhook", Resource: "teams", Pending: true, QueuedAt: time.Now()}
+	assert.Nil(t, db.CreatePublishOperation(&operation))
+	assert.Nil(t, db.CreatePublishOperation(&PublishOperation{Publisher: "TBA", Resource: "matches"}))
+	assert.Nil(t, db.CreatePublishOperation(&PublishOperation{Publisher: "TBA", Resource: "awards"}))
+	operation2, err := db.GetPublishOperation("webhook", "teams")
+	assert.Nil(t, err)
+	assert.Equal(t, operation.Id, operation2.Id)
+	assert.True(t, operation.QueuedAt.Equal(operation2.QueuedAt))
+	assert.Equal(t, "queued", operation2.Status())
+
+	operation.Attempts = 2
+	operation.LastError = "oh noes"
+	assert.Nil(t, db.UpdatePublishOperation(&operation))
+	operation2, err = db.GetPublishOperationById(operation.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "failed", operation2.Status())
+	operation.Pending = false
+	assert.Equal(t, "succeeded", operation.Status())
+
+	operations, err := db.GetAllPublishOperations()
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(operations)) {
+		assert.Equal(t, "awards", operations[0].Resource)
+		assert.Equal(t, "matches", operations[1].Resource)
+		assert.Equal(t, "webhook", operations[2].Publisher)
+	}
+
+	assert.Nil(t, db.DeletePublishOperation(operation.Id))
+	operation2, err = db.GetPublishOperationById(operation.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, operation2)
+	assert.Nil(t, db.TruncatePublishOperations())
+	operations, err = db.GetAllPublishOperations()
+	assert.Nil(t, err)
+	assert.Empty(t, operations)
+}
@@ -42,16 +42,19 @@ func (database *Database) GetAllRankings() (game.Rankings, error) {
 	return rankings, nil
 }
 
-// Deletes the existing rankings and inserts the given ones as a replacement.
+// Deletes the existing rankings and inserts the given ones as a replacement, within a single transaction so that the
+// rankings are never left partially replaced.
 func (database *Database) ReplaceAllRankings(rankings game.Rankings) error {
-	if err := database.rankingTable.truncate(); err != nil {
-		return err
-	}
-
-	for _, ranking := range rankings {
-		if err := database.CreateRanking(&ranking); err != nil {
+	return database.RunInTransaction(func(database *Database) error {
+		if err := database.rankingTable.truncate(); err != nil {
 			return err
 		}
-	}
-	return nil
+
+		for _, ranking := range rankings {
+			if err := database.CreateRanking(&ranking); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
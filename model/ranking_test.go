@@ -67,3 +67,24 @@ func TestGetAllRankings(t *testing.T) {
 		assert.Equal(t, i+1, rankings[i].TeamId)
 	}
 }
+
+func TestReplaceAllRankings(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	assert.Nil(t, db.CreateRanking(&game.Ranking{TeamId: 254, Rank: 1}))
+	assert.Nil(t, db.ReplaceAllRankings(game.Rankings{{TeamId: 1114, Rank: 1}, {TeamId: 2056, Rank: 2}}))
+	rankings, _ := db.GetAllRankings()
+	if assert.Equal(t, 2, len(rankings)) {
+		assert.Equal(t, 1114, rankings[0].TeamId)
+		assert.Equal(t, 2056, rankings[1].TeamId)
+	}
+
+	// Check that a failure partway through leaves the existing rankings in place.
+	err := db.ReplaceAllRankings(game.Rankings{{TeamId: 254, Rank: 1}, {TeamId: 0, Rank: 2}})
+	assert.NotNil(t, err)
+	rankings, _ = db.GetAllRankings()
+	if assert.Equal(t, 2, len(rankings)) {
+		assert.Equal(t, 1114, rankings[0].TeamId)
+	}
+}
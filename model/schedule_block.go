@@ -11,8 +11,8 @@ import (
 )
 
 type ScheduleBlock struct {
-	Id              int `db:"id"`
-	MatchType       string
+	Id              int    `db:"id"`
+	MatchType       string `db:"index"`
 	StartTime       time.Time
 	NumMatches      int
 	MatchSpacingSec int
@@ -23,18 +23,11 @@ func (database *Database) CreateScheduleBlock(block *ScheduleBlock) error {
 }
 
 func (database *Database) GetScheduleBlocksByMatchType(matchType string) ([]ScheduleBlock, error) {
-	scheduleBlocks, err := database.scheduleBlockTable.getAll()
+	matchingScheduleBlocks, err := database.scheduleBlockTable.getByIndex("MatchType", matchType)
 	if err != nil {
 		return nil, err
 	}
 
-	var matchingScheduleBlocks []ScheduleBlock
-	for _, scheduleBlock := range scheduleBlocks {
-		if scheduleBlock.MatchType == matchType {
-			matchingScheduleBlocks = append(matchingScheduleBlocks, scheduleBlock)
-		}
-	}
-
 	sort.Slice(matchingScheduleBlocks, func(i, j int) bool {
 		return matchingScheduleBlocks[i].StartTime.Before(matchingScheduleBlocks[j].StartTime)
 	})
@@ -17,11 +17,13 @@ import (
 // Encapsulates all persistence operations for a particular data type represented by a struct.
 type table[R any] struct {
 	bolt         *bbolt.DB
+	tx           *bbolt.Tx
 	recordType   reflect.Type
 	name         string
 	bucketKey    []byte
 	idFieldIndex *int
 	manualId     bool
+	indexes      []*tableIndex
 }
 
 // Registers a new table for a struct.
@@ -38,7 +40,7 @@ func newTable[R any](database *Database) (*table[R], error) {
 	table.name = table.recordType.Name()
 	table.bucketKey = []byte(table.name)
 
-	// Determine which field in the struct is tagged as the ID and cache its index.
+	// Determine which field in the struct is tagged as the ID and cache its index, and which fields are indexed.
 	idFound := false
 	for i := 0; i < recordTypeValue.Type().NumField(); i++ {
 		field := recordTypeValue.Type().Field(i)
@@ -46,7 +48,14 @@ func newTable[R any](database *Database) (*table[R], error) {
 		for _, tag := range strings.Split(field.Tag.Get("db"), ",") {
 			tags[tag] = struct{}{}
 		}
-		if _, ok := tags["id"]; ok {
+		if _, ok := tags["index"]; ok {
+			index, err := newTableIndex(table.name, field, i)
+			if err != nil {
+				return nil, err
+			}
+			table.indexes = append(table.indexes, index)
+		}
+		if _, ok := tags["id"]; ok && !idFound {
 			if field.Type.Kind() != reflect.Int {
 				return nil,
 					fmt.Errorf(
@@ -57,17 +66,18 @@ func newTable[R any](database *Database) (*table[R], error) {
 			*table.idFieldIndex = i
 			idFound = true
 			_, table.manualId = tags["manual"]
-			break
 		}
 	}
 	if !idFound {
 		return nil, fmt.Errorf("struct %s has no field tagged as the id", table.name)
 	}
 
-	// Create the Bolt bucket corresponding to the struct.
+	// Create the Bolt bucket corresponding to the struct, and build any of its indexes that don't exist yet.
 	err := table.bolt.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(table.bucketKey)
-		return err
+		if _, err := tx.CreateBucketIfNotExists(table.bucketKey); err != nil {
+			return err
+		}
+		return table.ensureIndexes(tx)
 	})
 	if err != nil {
 		return nil, err
@@ -79,7 +89,7 @@ func newTable[R any](database *Database) (*table[R], error) {
 // Returns the record with the given ID, or nil if it doesn't exist.
 func (table *table[R]) getById(id int) (*R, error) {
 	record := new(R)
-	err := table.bolt.View(func(tx *bbolt.Tx) error {
+	err := table.viewTx(func(tx *bbolt.Tx) error {
 		bucket, err := table.getBucket(tx)
 		if err != nil {
 			return err
@@ -99,7 +109,7 @@ func (table *table[R]) getById(id int) (*R, error) {
 // Returns a slice containing every record in the table, ordered by string representation of ID.
 func (table *table[R]) getAll() ([]R, error) {
 	var records []R
-	err := table.bolt.View(func(tx *bbolt.Tx) error {
+	err := table.viewTx(func(tx *bbolt.Tx) error {
 		bucket, err := table.getBucket(tx)
 		if err != nil {
 			return err
@@ -132,7 +142,7 @@ func (table *table[R]) create(record *R) error {
 		)
 	}
 
-	return table.bolt.Update(func(tx *bbolt.Tx) error {
+	return table.updateTx(func(tx *bbolt.Tx) error {
 		bucket, err := table.getBucket(tx)
 		if err != nil {
 			return err
@@ -147,22 +157,51 @@ func (table *table[R]) create(record *R) error {
 			id = int(newSequence)
 			value.Field(*table.idFieldIndex).SetInt(int64(id))
 		}
+		return table.putNew(tx, bucket, record, id)
+	})
+}
 
-		// Ensure that a record having the same ID does not already exist in the table.
-		key := idToKey(id)
-		oldRecord := bucket.Get(key)
-		if oldRecord != nil {
-			return fmt.Errorf("%s with ID %d already exists: %s", table.name, id, string(oldRecord))
-		}
+// Persists the given record as a new row in the table under the ID it already has, even if the table is configured for
+// autogenerated IDs, for recreating records taken from another database. Generated IDs then carry on after it.
+func (table *table[R]) createWithId(record *R) error {
+	id := int(reflect.ValueOf(record).Elem().Field(*table.idFieldIndex).Int())
+	if id <= 0 {
+		return fmt.Errorf("can't create %s with non-positive ID %d", table.name, id)
+	}
 
-		recordJson, err := json.Marshal(record)
+	return table.updateTx(func(tx *bbolt.Tx) error {
+		bucket, err := table.getBucket(tx)
 		if err != nil {
 			return err
 		}
-		return bucket.Put(key, recordJson)
+		if uint64(id) > bucket.Sequence() {
+			if err = bucket.SetSequence(uint64(id)); err != nil {
+				return err
+			}
+		}
+		return table.putNew(tx, bucket, record, id)
 	})
 }
 
+// Writes the given record under the given ID within the given transaction, along with its index entries, checking that
+// no record having the same ID already exists.
+func (table *table[R]) putNew(tx *bbolt.Tx, bucket *bbolt.Bucket, record *R, id int) error {
+	key := idToKey(id)
+	oldRecord := bucket.Get(key)
+	if oldRecord != nil {
+		return fmt.Errorf("%s with ID %d already exists: %s", table.name, id, string(oldRecord))
+	}
+
+	recordJson, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err = bucket.Put(key, recordJson); err != nil {
+		return err
+	}
+	return table.addIndexEntries(tx, reflect.ValueOf(record).Elem(), id)
+}
+
 // Persists the given record as an update to the existing row in the table. Returns an error if the record does not
 // already exist.
 func (table *table[R]) update(record *R) error {
@@ -173,7 +212,7 @@ func (table *table[R]) update(record *R) error {
 		return fmt.Errorf("can't update %s with zero ID", table.name)
 	}
 
-	return table.bolt.Update(func(tx *bbolt.Tx) error {
+	return table.updateTx(func(tx *bbolt.Tx) error {
 		bucket, err := table.getBucket(tx)
 		if err != nil {
 			return err
@@ -185,18 +224,24 @@ func (table *table[R]) update(record *R) error {
 		if oldRecord == nil {
 			return fmt.Errorf("can't update non-existent %s with ID %d", table.name, id)
 		}
+		if err = table.removeIndexEntries(tx, oldRecord, id); err != nil {
+			return err
+		}
 
 		recordJson, err := json.Marshal(record)
 		if err != nil {
 			return err
 		}
-		return bucket.Put(key, recordJson)
+		if err = bucket.Put(key, recordJson); err != nil {
+			return err
+		}
+		return table.addIndexEntries(tx, value, id)
 	})
 }
 
 // Deletes the record having the given ID from the table. Returns an error if the record does not exist.
 func (table *table[R]) delete(id int) error {
-	return table.bolt.Update(func(tx *bbolt.Tx) error {
+	return table.updateTx(func(tx *bbolt.Tx) error {
 		bucket, err := table.getBucket(tx)
 		if err != nil {
 			return err
@@ -208,6 +253,9 @@ func (table *table[R]) delete(id int) error {
 		if oldRecord == nil {
 			return fmt.Errorf("can't delete non-existent %s with ID %d", table.name, id)
 		}
+		if err = table.removeIndexEntries(tx, oldRecord, id); err != nil {
+			return err
+		}
 
 		return bucket.Delete(key)
 	})
@@ -215,7 +263,7 @@ func (table *table[R]) delete(id int) error {
 
 // Deletes all records from the table.
 func (table *table[R]) truncate() error {
-	return table.bolt.Update(func(tx *bbolt.Tx) error {
+	return table.updateTx(func(tx *bbolt.Tx) error {
 		_, err := table.getBucket(tx)
 		if err != nil {
 			return err
@@ -226,11 +274,41 @@ func (table *table[R]) truncate() error {
 		if err != nil {
 			return err
 		}
-		_, err = tx.CreateBucket(table.bucketKey)
-		return err
+		if _, err = tx.CreateBucket(table.bucketKey); err != nil {
+			return err
+		}
+		if tx.Bucket(indexBucketKey(table.name)) != nil {
+			if err = tx.DeleteBucket(indexBucketKey(table.name)); err != nil {
+				return err
+			}
+		}
+		return table.ensureIndexes(tx)
 	})
 }
 
+// Returns a copy of the table whose operations all run within the given transaction instead of their own.
+func (table *table[R]) withTx(tx *bbolt.Tx) *table[R] {
+	txTable := *table
+	txTable.tx = tx
+	return &txTable
+}
+
+// Runs the given function within a read-only transaction, or within the table's transaction if it is bound to one.
+func (table *table[R]) viewTx(f func(tx *bbolt.Tx) error) error {
+	if table.tx != nil {
+		return f(table.tx)
+	}
+	return table.bolt.View(f)
+}
+
+// Runs the given function within a read-write transaction, or within the table's transaction if it is bound to one.
+func (table *table[R]) updateTx(f func(tx *bbolt.Tx) error) error {
+	if table.tx != nil {
+		return f(table.tx)
+	}
+	return table.bolt.Update(f)
+}
+
 // Obtains the Bolt bucket belonging to the table.
 func (table *table[R]) getBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
 	bucket := tx.Bucket(table.bucketKey)
@@ -0,0 +1,256 @@
+// Secondary indexes on the fields of a table, for looking up records without reading the whole table.
+
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// A secondary index on one field of a table's struct, which is declared by tagging the field with `db:"index"`.
+//
+// Each index is a Bolt bucket, nested within the table's index bucket, whose keys are the encoded value of the field
+// followed by the ID of the record. The encoding sorts in the same order as the values themselves, so that looking up
+// a value or a range of values is a seek followed by a scan of only the matching keys.
+type tableIndex struct {
+	fieldName  string
+	fieldIndex int
+	fieldType  reflect.Type
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Returns the key of the bucket that holds the indexes of the table having the given name.
+func indexBucketKey(tableName string) []byte {
+	return []byte(tableName + "Indexes")
+}
+
+// Creates an index on the given struct field, checking that its type is one that can be indexed.
+func newTableIndex(tableName string, field reflect.StructField, fieldIndex int) (*tableIndex, error) {
+	switch field.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.String, reflect.Bool:
+	default:
+		if field.Type != timeType {
+			return nil, fmt.Errorf(
+				"field %s in struct %s tagged with 'index' must be an int, string, bool or time; got %v",
+				field.Name, tableName, field.Type,
+			)
+		}
+	}
+	return &tableIndex{fieldName: field.Name, fieldIndex: fieldIndex, fieldType: field.Type}, nil
+}
+
+// Returns the sortable encoding of the given value of the indexed field.
+func (index *tableIndex) encode(value reflect.Value) []byte {
+	if index.fieldType == timeType {
+		timeValue := value.Interface().(time.Time)
+		encoded := encodeIndexInt(timeValue.Unix())
+		return binary.BigEndian.AppendUint32(encoded, uint32(timeValue.Nanosecond()))
+	}
+	switch value.Kind() {
+	case reflect.String:
+		// Escape any zero bytes and terminate the string so that no encoded value is a prefix of another.
+		encoded := bytes.ReplaceAll([]byte(value.String()), []byte{0}, []byte{0, 0xff})
+		return append(encoded, 0, 1)
+	case reflect.Bool:
+		if value.Bool() {
+			return []byte{1}
+		}
+		return []byte{0}
+	default:
+		return encodeIndexInt(value.Int())
+	}
+}
+
+// Returns the sortable encoding of the given query value, which must be convertible to the type of the indexed field.
+func (index *tableIndex) encodeQueryValue(value any) ([]byte, error) {
+	reflectValue := reflect.ValueOf(value)
+	if !reflectValue.IsValid() || !reflectValue.CanConvert(index.fieldType) {
+		return nil, fmt.Errorf("can't query index on %s with value of type %T", index.fieldName, value)
+	}
+	return index.encode(reflectValue.Convert(index.fieldType)), nil
+}
+
+// Returns the key of the entry in this index for the given record.
+func (index *tableIndex) entryKey(record reflect.Value, id int) []byte {
+	return binary.BigEndian.AppendUint64(index.encode(record.Field(index.fieldIndex)), uint64(id))
+}
+
+// Encodes the given integer such that negative numbers sort before positive ones.
+func encodeIndexInt(value int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(value)^(1<<63))
+}
+
+// Returns the records whose value of the given indexed field is equal to the given value, ordered by ID.
+func (table *table[R]) getByIndex(fieldName string, value any) ([]R, error) {
+	return table.getByIndexRange(fieldName, value, value)
+}
+
+// Returns the records whose value of the given indexed field is between the given minimum and maximum inclusive,
+// ordered by that value and then by ID.
+func (table *table[R]) getByIndexRange(fieldName string, min, max any) ([]R, error) {
+	index, err := table.getIndex(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	minKey, err := index.encodeQueryValue(min)
+	if err != nil {
+		return nil, err
+	}
+	maxKey, err := index.encodeQueryValue(max)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []R
+	err = table.viewTx(func(tx *bbolt.Tx) error {
+		bucket, err := table.getBucket(tx)
+		if err != nil {
+			return err
+		}
+		indexBucket, err := table.getIndexBucket(tx, index)
+		if err != nil {
+			return err
+		}
+
+		cursor := indexBucket.Cursor()
+		for key, _ := cursor.Seek(minKey); key != nil; key, _ = cursor.Next() {
+			encodedValue, encodedId := key[:len(key)-8], key[len(key)-8:]
+			if bytes.Compare(encodedValue, maxKey) > 0 {
+				break
+			}
+			id := int(binary.BigEndian.Uint64(encodedId))
+			recordJson := bucket.Get(idToKey(id))
+			if recordJson == nil {
+				return fmt.Errorf("index on %s.%s refers to non-existent ID %d", table.name, fieldName, id)
+			}
+			var record R
+			if err = json.Unmarshal(recordJson, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Returns the index on the given field, or an error if the field isn't indexed.
+func (table *table[R]) getIndex(fieldName string) (*tableIndex, error) {
+	for _, index := range table.indexes {
+		if index.fieldName == fieldName {
+			return index, nil
+		}
+	}
+	return nil, fmt.Errorf("field %s of struct %s is not indexed", fieldName, table.name)
+}
+
+// Obtains the Bolt bucket belonging to the given index of the table.
+func (table *table[R]) getIndexBucket(tx *bbolt.Tx, index *tableIndex) (*bbolt.Bucket, error) {
+	if indexesBucket := tx.Bucket(indexBucketKey(table.name)); indexesBucket != nil {
+		if indexBucket := indexesBucket.Bucket([]byte(index.fieldName)); indexBucket != nil {
+			return indexBucket, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown index on %s.%s", table.name, index.fieldName)
+}
+
+// Adds the index entries for the given record struct within the given transaction.
+func (table *table[R]) addIndexEntries(tx *bbolt.Tx, record reflect.Value, id int) error {
+	for _, index := range table.indexes {
+		indexBucket, err := table.getIndexBucket(tx, index)
+		if err != nil {
+			return err
+		}
+		if err = indexBucket.Put(index.entryKey(record, id), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the index entries for the given serialized record within the given transaction.
+func (table *table[R]) removeIndexEntries(tx *bbolt.Tx, recordJson []byte, id int) error {
+	if len(table.indexes) == 0 {
+		return nil
+	}
+	record := new(R)
+	if err := json.Unmarshal(recordJson, record); err != nil {
+		return err
+	}
+	for _, index := range table.indexes {
+		indexBucket, err := table.getIndexBucket(tx, index)
+		if err != nil {
+			return err
+		}
+		if err = indexBucket.Delete(index.entryKey(reflect.ValueOf(record).Elem(), id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recreates the table's indexes from its records if the indexes stored in the database don't match those declared on
+// the struct, e.g. because an index has been added or the records have been changed by a migration.
+func (table *table[R]) ensureIndexes(tx *bbolt.Tx) error {
+	key := indexBucketKey(table.name)
+	indexesBucket := tx.Bucket(key)
+	if indexesBucket != nil {
+		var storedFieldNames []string
+		err := indexesBucket.ForEach(func(name, value []byte) error {
+			storedFieldNames = append(storedFieldNames, string(name))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var fieldNames []string
+		for _, index := range table.indexes {
+			fieldNames = append(fieldNames, index.fieldName)
+		}
+		sort.Strings(storedFieldNames)
+		sort.Strings(fieldNames)
+		if reflect.DeepEqual(storedFieldNames, fieldNames) {
+			return nil
+		}
+		if err = tx.DeleteBucket(key); err != nil {
+			return err
+		}
+	}
+	if len(table.indexes) == 0 {
+		return nil
+	}
+	return table.rebuildIndexes(tx)
+}
+
+// Creates empty indexes for the table and populates them from its records.
+func (table *table[R]) rebuildIndexes(tx *bbolt.Tx) error {
+	indexesBucket, err := tx.CreateBucket(indexBucketKey(table.name))
+	if err != nil {
+		return err
+	}
+	for _, index := range table.indexes {
+		if _, err = indexesBucket.CreateBucket([]byte(index.fieldName)); err != nil {
+			return err
+		}
+	}
+	bucket, err := table.getBucket(tx)
+	if err != nil {
+		return err
+	}
+	return bucket.ForEach(func(key, value []byte) error {
+		record := new(R)
+		if err := json.Unmarshal(value, record); err != nil {
+			return err
+		}
+		id := int(reflect.ValueOf(record).Elem().Field(*table.idFieldIndex).Int())
+		return table.addIndexEntries(tx, reflect.ValueOf(record).Elem(), id)
+	})
+}
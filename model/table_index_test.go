@@ -0,0 +1,128 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+type indexedRecord struct {
+	Id         int       `db:"id"`
+	IntData    int       `db:"index"`
+	StringData string    `db:"index"`
+	BoolData   bool      `db:"index"`
+	TimeData   time.Time `db:"index"`
+	Unindexed  string
+}
+
+func TestTableIndexQueries(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	table, err := newTable[indexedRecord](db)
+	if !assert.Nil(t, err) {
+		return
+	}
+	baseTime := time.Unix(1000, 0)
+	records := []indexedRecord{
+		{IntData: 5, StringData: "b", TimeData: baseTime.Add(3 * time.Second)},
+		{IntData: -3, StringData: "bc", BoolData: true, TimeData: baseTime.Add(-time.Hour)},
+		{IntData: 5, StringData: "a", TimeData: baseTime},
+		{IntData: 254, StringData: "b\x00", BoolData: true},
+		{IntData: 0, StringData: "b", TimeData: baseTime.Add(time.Nanosecond)},
+	}
+	for i := range records {
+		assert.Nil(t, table.create(&records[i]))
+	}
+	ids := func(records []indexedRecord, err error) []int {
+		assert.Nil(t, err)
+		var ids []int
+		for _, record := range records {
+			ids = append(ids, record.Id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int{1, 3}, ids(table.getByIndex("IntData", 5)))
+	assert.Equal(t, []int{1, 5}, ids(table.getByIndex("StringData", "b")))
+	assert.Equal(t, []int{4}, ids(table.getByIndex("StringData", "b\x00")))
+	assert.Equal(t, []int{2, 4}, ids(table.getByIndex("BoolData", true)))
+	assert.Equal(t, []int{3}, ids(table.getByIndex("TimeData", baseTime)))
+	assert.Empty(t, ids(table.getByIndex("IntData", 6)))
+
+	// Check that ranges are inclusive and sorted by value, including negative numbers and times before the epoch.
+	assert.Equal(t, []int{2, 5, 1, 3}, ids(table.getByIndexRange("IntData", -10, 5)))
+	assert.Equal(t, []int{1, 3, 4}, ids(table.getByIndexRange("IntData", 1, 1000)))
+	assert.Equal(t, []int{3, 1, 5, 4}, ids(table.getByIndexRange("StringData", "a", "b\x00")))
+	assert.Equal(t, []int{4, 2, 3, 5}, ids(table.getByIndexRange("TimeData", time.Time{}, baseTime.Add(time.Second))))
+
+	// Check that updates and deletes are reflected in the indexes.
+	records[0].IntData = 6
+	records[0].StringData = "z"
+	assert.Nil(t, table.update(&records[0]))
+	assert.Equal(t, []int{3}, ids(table.getByIndex("IntData", 5)))
+	assert.Equal(t, []int{1}, ids(table.getByIndex("IntData", 6)))
+	assert.Equal(t, []int{5}, ids(table.getByIndex("StringData", "b")))
+	assert.Nil(t, table.delete(records[2].Id))
+	assert.Empty(t, ids(table.getByIndex("IntData", 5)))
+	assert.Equal(t, []int{2, 5, 1, 4}, ids(table.getByIndexRange("IntData", -1000, 1000)))
+
+	assert.Nil(t, table.truncate())
+	assert.Empty(t, ids(table.getByIndexRange("IntData", -1000, 1000)))
+	record := indexedRecord{IntData: 5}
+	assert.Nil(t, table.create(&record))
+	assert.Equal(t, []int{record.Id}, ids(table.getByIndex("IntData", 5)))
+}
+
+func TestTableIndexRebuild(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	table, _ := newTable[indexedRecord](db)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, table.create(&indexedRecord{IntData: i % 2}))
+	}
+
+	// Simulate an index that was added after the records were written, and check that it is built on registration.
+	assert.Nil(t, db.bolt.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(indexBucketKey("indexedRecord")).DeleteBucket([]byte("IntData"))
+	}))
+	_, err := table.getByIndex("IntData", 0)
+	assert.NotNil(t, err)
+	table, err = newTable[indexedRecord](db)
+	assert.Nil(t, err)
+	records, err := table.getByIndex("IntData", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+}
+
+func TestTableIndexErrors(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	type recordWithWrongIndexType struct {
+		Id   int     `db:"id"`
+		Data float64 `db:"index"`
+	}
+	_, err := newTable[recordWithWrongIndexType](db)
+	if assert.NotNil(t, err) {
+		assert.Equal(
+			t,
+			"field Data in struct recordWithWrongIndexType tagged with 'index' must be an int, string, bool or time; "+
+				"got float64",
+			err.Error(),
+		)
+	}
+
+	table, _ := newTable[indexedRecord](db)
+	_, err = table.getByIndex("Unindexed", "a")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "field Unindexed of struct indexedRecord is not indexed", err.Error())
+	}
+	_, err = table.getByIndex("IntData", "a")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "can't query index on IntData with value of type string", err.Error())
+	}
+}
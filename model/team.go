@@ -7,19 +7,53 @@ package model
 
 import "sort"
 
+// Robot inspection states that a team can be in.
+const (
+	InspectionPending   = ""
+	InspectionPassed    = "passed"
+	InspectionFailed    = "failed"
+	InspectionReinspect = "reinspect"
+)
+
+var InspectionStatusNames = map[string]string{
+	InspectionPending:   "Not Inspected",
+	InspectionPassed:    "Passed",
+	InspectionFailed:    "Failed",
+	InspectionReinspect: "Re-inspection Required",
+}
+
 type Team struct {
-	Id              int `db:"id,manual"`
-	Name            string
-	Nickname        string
-	City            string
-	StateProv       string
-	Country         string
-	RookieYear      int
-	RobotName       string
-	Accomplishments string
-	WpaKey          string
-	HasConnected    bool
-	FtaNotes        string
+	Id                int `db:"id,manual"`
+	Name              string
+	Nickname          string
+	City              string
+	StateProv         string
+	Country           string
+	RookieYear        int
+	RobotName         string
+	Accomplishments   string
+	WpaKey            string
+	HasConnected      bool
+	FtaNotes          string
+	CheckedIn         bool
+	RobotWeightLb     float64
+	Inspection        string
+	InspectionNotes   string
+	ContactEmail      string
+	ContactWebhookUrl string
+}
+
+// Returns true if the team's robot has passed inspection and doesn't need to be re-inspected.
+func (team *Team) PassedInspection() bool {
+	return team.Inspection == InspectionPassed
+}
+
+// Returns the human-readable description of the team's inspection status.
+func (team *Team) InspectionStatusName() string {
+	if name, ok := InspectionStatusNames[team.Inspection]; ok {
+		return name
+	}
+	return team.Inspection
 }
 
 func (database *Database) CreateTeam(team *Team) error {
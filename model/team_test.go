@@ -72,3 +72,21 @@ func TestGetAllTeams(t *testing.T) {
 		assert.Equal(t, i+1, teams[i].Id)
 	}
 }
+
+func TestTeamInspectionStatus(t *testing.T) {
+	team := Team{Id: 254}
+	assert.False(t, team.PassedInspection())
+	assert.Equal(t, "Not Inspected", team.InspectionStatusName())
+
+	team.Inspection = InspectionFailed
+	assert.False(t, team.PassedInspection())
+	assert.Equal(t, "Failed", team.InspectionStatusName())
+
+	team.Inspection = InspectionReinspect
+	assert.False(t, team.PassedInspection())
+	assert.Equal(t, "Re-inspection Required", team.InspectionStatusName())
+
+	team.Inspection = InspectionPassed
+	assert.True(t, team.PassedInspection())
+	assert.Equal(t, "Passed", team.InspectionStatusName())
+}
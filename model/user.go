@@ -0,0 +1,154 @@
+// Model and datastore CRUD methods for a user account that can log in to the server.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The roles a user account can have, each of which grants access to a different set of pages. Admins can access
+// everything.
+const (
+	RoleAdmin   = "admin"
+	RoleEmcee   = "emcee"
+	RoleFta     = "fta"
+	RoleHeadRef = "head_ref"
+	RoleScorer  = "scorer"
+)
+
+// The roles in the order in which they are shown, along with their human-readable names.
+var Roles = []string{RoleAdmin, RoleFta, RoleHeadRef, RoleScorer, RoleEmcee}
+var RoleNames = map[string]string{
+	RoleAdmin:   "Admin",
+	RoleEmcee:   "Emcee",
+	RoleFta:     "FTA",
+	RoleHeadRef: "Head Referee",
+	RoleScorer:  "Scorer",
+}
+
+type User struct {
+	Id           int    `db:"id"`
+	Username     string `db:"index"`
+	PasswordHash string
+	Role         string
+}
+
+// NewUser Returns a new user account with the given details and a hash of the given password, or an error if any of
+// them are invalid.
+func NewUser(username, password, role string) (*User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, fmt.Errorf("username must not be blank")
+	}
+	user := User{Username: username}
+	if err := user.SetRole(role); err != nil {
+		return nil, err
+	}
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetPassword Replaces the user's password hash with one for the given password.
+func (user *User) SetPassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("password must not be blank")
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(passwordHash)
+	return nil
+}
+
+// CheckPassword Returns true if the given password matches the user's password hash.
+func (user *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
+// SetRole Sets the user's role, or returns an error if it isn't one of the known roles.
+func (user *User) SetRole(role string) error {
+	if _, ok := RoleNames[role]; !ok {
+		return fmt.Errorf("invalid role '%s'", role)
+	}
+	user.Role = role
+	return nil
+}
+
+// RoleName Returns the human-readable name of the user's role.
+func (user *User) RoleName() string {
+	return RoleNames[user.Role]
+}
+
+func (database *Database) CreateUser(user *User) error {
+	existingUser, err := database.GetUserByUsername(user.Username)
+	if err != nil {
+		return err
+	}
+	if existingUser != nil {
+		return fmt.Errorf("user '%s' already exists", user.Username)
+	}
+	return database.userTable.create(user)
+}
+
+func (database *Database) GetUserById(id int) (*User, error) {
+	return database.userTable.getById(id)
+}
+
+func (database *Database) GetUserByUsername(username string) (*User, error) {
+	users, err := database.userTable.getByIndex("Username", username)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+func (database *Database) UpdateUser(user *User) error {
+	return database.userTable.update(user)
+}
+
+func (database *Database) DeleteUser(id int) error {
+	return database.userTable.delete(id)
+}
+
+func (database *Database) TruncateUsers() error {
+	return database.userTable.truncate()
+}
+
+// ImportUsers Replaces all the user accounts with the given ones, for carrying them over from another database since
+// they belong to the installation rather than to the event.
+func (database *Database) ImportUsers(users []User) error {
+	return database.RunInTransaction(func(database *Database) error {
+		if err := database.TruncateUsers(); err != nil {
+			return err
+		}
+		for _, user := range users {
+			user.Id = 0
+			if err := database.CreateUser(&user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (database *Database) GetAllUsers() ([]User, error) {
+	users, err := database.userTable.getAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users, nil
+}
@@ -5,13 +5,35 @@
 
 package model
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 type UserSession struct {
-	Id        int `db:"id"`
-	Token     string
-	Username  string
-	CreatedAt time.Time
+	Id            int    `db:"id"`
+	Token         string `db:"index"`
+	Username      string
+	CreatedAt     time.Time
+	LastActiveAt  time.Time
+	RemoteAddress string
+	UserAgent     string
+}
+
+// LastActive Returns the time the session was last used, falling back to when it was created for sessions from before
+// activity was tracked.
+func (session UserSession) LastActive() time.Time {
+	if session.LastActiveAt.IsZero() {
+		return session.CreatedAt
+	}
+	return session.LastActiveAt
+}
+
+// IsExpired Returns true if, as of the given time, the session has gone unused for longer than the given idle timeout
+// or was created longer ago than the given maximum age. A limit of zero is never reached.
+func (session *UserSession) IsExpired(now time.Time, idleTimeout, maxAge time.Duration) bool {
+	return idleTimeout > 0 && now.Sub(session.LastActive()) > idleTimeout ||
+		maxAge > 0 && now.Sub(session.CreatedAt) > maxAge
 }
 
 func (database *Database) CreateUserSession(session *UserSession) error {
@@ -19,17 +41,23 @@ func (database *Database) CreateUserSession(session *UserSession) error {
 }
 
 func (database *Database) GetUserSessionByToken(token string) (*UserSession, error) {
-	userSessions, err := database.userSessionTable.getAll()
+	userSessions, err := database.userSessionTable.getByIndex("Token", token)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, userSession := range userSessions {
-		if userSession.Token == token {
-			return &userSession, nil
-		}
+	if len(userSessions) == 0 {
+		return nil, nil
 	}
-	return nil, nil
+	return &userSessions[0], nil
+}
+
+func (database *Database) GetUserSessionById(id int) (*UserSession, error) {
+	return database.userSessionTable.getById(id)
+}
+
+func (database *Database) UpdateUserSession(session *UserSession) error {
+	return database.userSessionTable.update(session)
 }
 
 func (database *Database) DeleteUserSession(id int) error {
@@ -39,3 +67,64 @@ func (database *Database) DeleteUserSession(id int) error {
 func (database *Database) TruncateUserSessions() error {
 	return database.userSessionTable.truncate()
 }
+
+// ImportUserSessions Replaces all the sessions with the given ones, for carrying them over from another database along
+// with the user accounts they belong to, so that nobody is logged out and no revoked session comes back.
+func (database *Database) ImportUserSessions(userSessions []UserSession) error {
+	return database.RunInTransaction(func(database *Database) error {
+		if err := database.TruncateUserSessions(); err != nil {
+			return err
+		}
+		for _, session := range userSessions {
+			session.Id = 0
+			if err := database.CreateUserSession(&session); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteUserSessionsByUsername Deletes all sessions belonging to the given user, logging them out everywhere.
+func (database *Database) DeleteUserSessionsByUsername(username string) error {
+	userSessions, err := database.userSessionTable.getAll()
+	if err != nil {
+		return err
+	}
+	for _, session := range userSessions {
+		if session.Username == username {
+			if err = database.userSessionTable.delete(session.Id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetAllUserSessions Returns all sessions, most recently used first.
+func (database *Database) GetAllUserSessions() ([]UserSession, error) {
+	userSessions, err := database.userSessionTable.getAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(userSessions, func(i, j int) bool {
+		return userSessions[i].LastActive().After(userSessions[j].LastActive())
+	})
+	return userSessions, nil
+}
+
+// DeleteExpiredUserSessions Deletes all sessions that have expired as of the given time according to the given limits.
+func (database *Database) DeleteExpiredUserSessions(now time.Time, idleTimeout, maxAge time.Duration) error {
+	userSessions, err := database.userSessionTable.getAll()
+	if err != nil {
+		return err
+	}
+	for _, session := range userSessions {
+		if session.IsExpired(now, idleTimeout, maxAge) {
+			if err = database.userSessionTable.delete(session.Id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
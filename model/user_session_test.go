@@ -22,7 +22,7 @@ func TestUserSessionCrud(t *testing.T) {
 	db := setupTestDb(t)
 	defer db.Close()
 
-	session := UserSession{0, "token1", "Bertha", time.Now()}
+	session := UserSession{Token: "token1", Username: "Bertha", CreatedAt: time.Now()}
 	err := db.CreateUserSession(&session)
 	assert.Nil(t, err)
 	session2, err := db.GetUserSessionByToken("token1")
@@ -41,10 +41,82 @@ func TestTruncateUserSessions(t *testing.T) {
 	db := setupTestDb(t)
 	defer db.Close()
 
-	session := UserSession{0, "token1", "Bertha", time.Now()}
+	session := UserSession{Token: "token1", Username: "Bertha", CreatedAt: time.Now()}
 	db.CreateUserSession(&session)
 	db.TruncateUserSessions()
 	session2, err := db.GetUserSessionByToken("token1")
 	assert.Nil(t, err)
 	assert.Nil(t, session2)
 }
+
+func TestImportUserSessions(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	db.CreateUserSession(&UserSession{Token: "token1", Username: "Bertha", CreatedAt: time.Now()})
+	assert.Nil(t, db.ImportUserSessions([]UserSession{{Id: 7, Token: "token2", Username: "Alfred"}}))
+	session, _ := db.GetUserSessionByToken("token1")
+	assert.Nil(t, session)
+	session, _ = db.GetUserSessionByToken("token2")
+	if assert.NotNil(t, session) {
+		assert.Equal(t, "Alfred", session.Username)
+	}
+}
+
+func TestDeleteUserSessionsByUsername(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	db.CreateUserSession(&UserSession{Token: "token1", Username: "Bertha", CreatedAt: time.Now()})
+	db.CreateUserSession(&UserSession{Token: "token2", Username: "Alfred", CreatedAt: time.Now()})
+	db.CreateUserSession(&UserSession{Token: "token3", Username: "Bertha", CreatedAt: time.Now()})
+	assert.Nil(t, db.DeleteUserSessionsByUsername("Bertha"))
+	session, _ := db.GetUserSessionByToken("token1")
+	assert.Nil(t, session)
+	session, _ = db.GetUserSessionByToken("token2")
+	assert.NotNil(t, session)
+	session, _ = db.GetUserSessionByToken("token3")
+	assert.Nil(t, session)
+}
+
+func TestUserSessionExpiry(t *testing.T) {
+	now := time.Now()
+	session := UserSession{CreatedAt: now.Add(-5 * time.Hour)}
+	assert.Equal(t, session.CreatedAt, session.LastActive())
+	assert.False(t, session.IsExpired(now, 0, 0))
+	assert.True(t, session.IsExpired(now, time.Hour, 0))
+	assert.True(t, session.IsExpired(now, 0, 4*time.Hour))
+	assert.False(t, session.IsExpired(now, 0, 6*time.Hour))
+
+	session.LastActiveAt = now.Add(-30 * time.Minute)
+	assert.Equal(t, session.LastActiveAt, session.LastActive())
+	assert.False(t, session.IsExpired(now, time.Hour, 6*time.Hour))
+	assert.True(t, session.IsExpired(now, 20*time.Minute, 6*time.Hour))
+	assert.True(t, session.IsExpired(now, time.Hour, 4*time.Hour))
+}
+
+func TestGetAllAndDeleteExpiredUserSessions(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	now := time.Now()
+	db.CreateUserSession(&UserSession{Token: "token1", CreatedAt: now.Add(-3 * time.Hour)})
+	db.CreateUserSession(
+		&UserSession{Token: "token2", CreatedAt: now.Add(-3 * time.Hour), LastActiveAt: now.Add(-time.Minute)},
+	)
+	db.CreateUserSession(&UserSession{Token: "token3", CreatedAt: now.Add(-2 * time.Hour)})
+	sessions, err := db.GetAllUserSessions()
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(sessions)) {
+		assert.Equal(t, "token2", sessions[0].Token)
+		assert.Equal(t, "token3", sessions[1].Token)
+		assert.Equal(t, "token1", sessions[2].Token)
+	}
+
+	assert.Nil(t, db.DeleteExpiredUserSessions(now, 150*time.Minute, 0))
+	sessions, _ = db.GetAllUserSessions()
+	if assert.Equal(t, 2, len(sessions)) {
+		assert.Equal(t, "token2", sessions[0].Token)
+		assert.Equal(t, "token3", sessions[1].Token)
+	}
+}
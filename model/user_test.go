@@ -0,0 +1,84 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNonexistentUser(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	user, err := db.GetUserByUsername("blorpy")
+	assert.Nil(t, err)
+	assert.Nil(t, user)
+}
+
+func TestNewUser(t *testing.T) {
+	user, err := NewUser(" bertha ", "password1", RoleHeadRef)
+	assert.Nil(t, err)
+	assert.Equal(t, "bertha", user.Username)
+	assert.Equal(t, "Head Referee", user.RoleName())
+	assert.NotContains(t, user.PasswordHash, "password1")
+	assert.True(t, user.CheckPassword("password1"))
+	assert.False(t, user.CheckPassword("password2"))
+
+	_, err = NewUser(" ", "password1", RoleHeadRef)
+	assert.EqualError(t, err, "username must not be blank")
+	_, err = NewUser("bertha", "", RoleHeadRef)
+	assert.EqualError(t, err, "password must not be blank")
+	_, err = NewUser("bertha", "password1", "janitor")
+	assert.EqualError(t, err, "invalid role 'janitor'")
+}
+
+func TestUserCrud(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	user, _ := NewUser("bertha", "password1", RoleScorer)
+	assert.Nil(t, db.CreateUser(user))
+	user2, err := db.GetUserByUsername("bertha")
+	assert.Nil(t, err)
+	assert.Equal(t, *user, *user2)
+	duplicateUser, _ := NewUser("bertha", "password2", RoleFta)
+	assert.EqualError(t, db.CreateUser(duplicateUser), "user 'bertha' already exists")
+
+	user.SetRole(RoleEmcee)
+	assert.Nil(t, db.UpdateUser(user))
+	user2, _ = db.GetUserById(user.Id)
+	assert.Equal(t, RoleEmcee, user2.Role)
+
+	anotherUser, _ := NewUser("alfred", "password3", RoleAdmin)
+	db.CreateUser(anotherUser)
+	users, err := db.GetAllUsers()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(users)) {
+		assert.Equal(t, "alfred", users[0].Username)
+		assert.Equal(t, "bertha", users[1].Username)
+	}
+
+	assert.Nil(t, db.DeleteUser(user.Id))
+	user2, err = db.GetUserByUsername("bertha")
+	assert.Nil(t, err)
+	assert.Nil(t, user2)
+}
+
+func TestImportUsers(t *testing.T) {
+	db := setupTestDb(t)
+	defer db.Close()
+
+	user, _ := NewUser("bertha", "password1", RoleScorer)
+	db.CreateUser(user)
+	users := []User{
+		{Id: 5, Username: "alfred", PasswordHash: "hash1", Role: RoleAdmin},
+		{Id: 6, Username: "bertha", PasswordHash: "hash2", Role: RoleFta},
+	}
+	assert.Nil(t, db.ImportUsers(users))
+	importedUsers, err := db.GetAllUsers()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(importedUsers)) {
+		assert.Equal(t, "alfred", importedUsers[0].Username)
+		assert.Equal(t, "hash1", importedUsers[0].PasswordHash)
+		assert.Equal(t, RoleFta, importedUsers[1].Role)
+	}
+}
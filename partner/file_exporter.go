@@ -0,0 +1,108 @@
+// Publisher that writes event data as JSON files to a local directory, e.g. for pickup by a streaming overlay or sync
+// tool.
+
+package partner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/BotDogs4645/da/model"
+)
+
+type FileExporter struct {
+	Dir string
+}
+
+func NewFileExporter(dir string) *FileExporter {
+	return &FileExporter{Dir: dir}
+}
+
+func (exporter *FileExporter) Name() string {
+	return "file export"
+}
+
+// PublishTeams Writes the event team list to teams.json.
+func (exporter *FileExporter) PublishTeams(database *model.Database) error {
+	teamKeys, err := buildTbaTeams(database)
+	if err != nil {
+		return err
+	}
+	return exporter.write(TeamsResource, teamKeys)
+}
+
+// PublishMatches Writes the match schedule and results to matches.json.
+func (exporter *FileExporter) PublishMatches(database *model.Database) error {
+	tbaMatches, err := buildTbaMatches(database)
+	if err != nil {
+		return err
+	}
+	return exporter.write(MatchesResource, tbaMatches)
+}
+
+// PublishRankings Writes the team standings to rankings.json.
+func (exporter *FileExporter) PublishRankings(database *model.Database) error {
+	tbaRankings, err := buildTbaRankings(database)
+	if err != nil {
+		return err
+	}
+	return exporter.write(RankingsResource, tbaRankings)
+}
+
+// PublishAlliances Writes the alliance selection results to alliances.json.
+func (exporter *FileExporter) PublishAlliances(database *model.Database) error {
+	tbaAlliances, err := buildTbaAlliances(database)
+	if err != nil {
+		return err
+	}
+	return exporter.write(AlliancesResource, tbaAlliances)
+}
+
+// PublishAwards Writes the awards to awards.json.
+func (exporter *FileExporter) PublishAwards(database *model.Database) error {
+	tbaAwards, err := buildTbaAwards(database)
+	if err != nil {
+		return err
+	}
+	return exporter.write(AwardsResource, tbaAwards)
+}
+
+// PublishMatchVideos Writes the match video IDs to videos.json.
+func (exporter *FileExporter) PublishMatchVideos(database *model.Database) error {
+	matchVideos, err := buildTbaMatchVideos(database)
+	if err != nil {
+		return err
+	}
+	return exporter.write(VideosResource, matchVideos)
+}
+
+// DeletePublishedMatches Removes the exported matches file.
+func (exporter *FileExporter) DeletePublishedMatches() error {
+	err := os.Remove(exporter.filePath(MatchesResource))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Writes the given data to the file for the given resource, replacing it atomically so that readers never see a
+// partially written file.
+func (exporter *FileExporter) write(resource PublishResource, data interface{}) error {
+	if err := os.MkdirAll(exporter.Dir, 0755); err != nil {
+		return err
+	}
+	jsonBody, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempPath := exporter.filePath(resource) + ".tmp"
+	if err = os.WriteFile(tempPath, jsonBody, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, exporter.filePath(resource))
+}
+
+func (exporter *FileExporter) filePath(resource PublishResource) string {
+	return filepath.Join(exporter.Dir, string(resource)+".json")
+}
@@ -0,0 +1,154 @@
+// Channels through which teams are notified that their upcoming match is approaching.
+
+package partner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+)
+
+const notificationWebhookTimeout = 10 * time.Second
+
+// TeamNotification Describes an upcoming match that a team is being told about.
+type TeamNotification struct {
+	EventName   string `json:"event_name"`
+	TeamId      int    `json:"team_id"`
+	MatchName   string `json:"match_name"`
+	Station     string `json:"station"`
+	MatchesAway int    `json:"matches_away"`
+}
+
+// Message Returns the human-readable text of the notification.
+func (notification *TeamNotification) Message() string {
+	var when string
+	switch notification.MatchesAway {
+	case 0:
+		when = "is now on the field"
+	case 1:
+		when = "is up next"
+	default:
+		when = fmt.Sprintf("is %d matches away", notification.MatchesAway)
+	}
+	return fmt.Sprintf("Team %d: %s (%s) %s. Please report to queueing.", notification.TeamId,
+		notification.MatchName, notification.Station, when)
+}
+
+// NotificationChannel A means of delivering notifications to teams. Channels silently skip teams that have no contact
+// information for them.
+type NotificationChannel interface {
+	Name() string
+	Send(team *model.Team, notification *TeamNotification) error
+}
+
+// NotificationWebhook Posts notifications as JSON to a webhook, e.g. one that feeds a Discord bot. The payload includes
+// a "content" field so that it can be pointed directly at a Discord channel webhook.
+type NotificationWebhook struct {
+	Url string
+}
+
+type notificationWebhookPayload struct {
+	Content string `json:"content"`
+	*TeamNotification
+}
+
+func NewNotificationWebhook(url string) *NotificationWebhook {
+	return &NotificationWebhook{Url: url}
+}
+
+func (channel *NotificationWebhook) Name() string {
+	return "webhook"
+}
+
+// Send Posts the notification to the team's own webhook if it has one, or to the event-wide one otherwise.
+func (channel *NotificationWebhook) Send(team *model.Team, notification *TeamNotification) error {
+	url := team.ContactWebhookUrl
+	if url == "" {
+		url = channel.Url
+	}
+	if url == "" {
+		return nil
+	}
+
+	jsonBody, err := json.Marshal(notificationWebhookPayload{notification.Message(), notification})
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{Timeout: notificationWebhookTimeout}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("got status code %d from webhook: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// NotificationEmailer Sends notifications by email through an SMTP server. Authentication is skipped if no username is
+// configured, so that an unauthenticated local relay can be used.
+type NotificationEmailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (channel *NotificationEmailer) Name() string {
+	return "email"
+}
+
+// Send Emails the notification to the team's contact address.
+func (channel *NotificationEmailer) Send(team *model.Team, notification *TeamNotification) error {
+	if team.ContactEmail == "" {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if channel.Username != "" {
+		auth = smtp.PlainAuth("", channel.Username, channel.Password, channel.Host)
+	}
+	address := net.JoinHostPort(channel.Host, strconv.Itoa(channel.Port))
+	return smtp.SendMail(address, auth, channel.From, []string{team.ContactEmail},
+		channel.buildMessage(team, notification))
+}
+
+// Returns the full RFC 822 message, including headers, for the given notification.
+func (channel *NotificationEmailer) buildMessage(team *model.Team, notification *TeamNotification) []byte {
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", channel.From)
+	fmt.Fprintf(&message, "To: %s\r\n", team.ContactEmail)
+	fmt.Fprintf(&message, "Subject: %s: Team %d is in %s\r\n", notification.EventName, team.Id,
+		notification.MatchName)
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(notification.Message() + "\r\n")
+	return []byte(message.String())
+}
+
+// NotificationLogger Writes notifications to the log, for testing notifications without contacting any teams.
+type NotificationLogger struct{}
+
+func (channel *NotificationLogger) Name() string {
+	return "log"
+}
+
+// Send Logs the notification.
+func (channel *NotificationLogger) Send(team *model.Team, notification *TeamNotification) error {
+	log.Printf("Team notification: %s", notification.Message())
+	return nil
+}
@@ -0,0 +1,74 @@
+package partner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamNotificationMessage(t *testing.T) {
+	notification := TeamNotification{TeamId: 254, MatchName: "Q12", Station: "B2", MatchesAway: 3}
+	assert.Equal(t, "Team 254: Q12 (B2) is 3 matches away. Please report to queueing.", notification.Message())
+	notification.MatchesAway = 1
+	assert.Equal(t, "Team 254: Q12 (B2) is up next. Please report to queueing.", notification.Message())
+	notification.MatchesAway = 0
+	assert.Equal(t, "Team 254: Q12 (B2) is now on the field. Please report to queueing.", notification.Message())
+}
+
+func TestNotificationWebhook(t *testing.T) {
+	var requestPaths []string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPaths = append(requestPaths, r.URL.Path)
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+		if r.URL.Path == "/broken" {
+			http.Error(w, "oops", 500)
+		}
+	}))
+	defer server.Close()
+
+	notification := &TeamNotification{EventName: "Chezy Champs", TeamId: 254, MatchName: "Q12", Station: "R1",
+		MatchesAway: 2}
+	channel := NewNotificationWebhook(server.URL + "/event")
+	assert.Nil(t, channel.Send(&model.Team{Id: 254}, notification))
+	assert.Equal(t, []string{"/event"}, requestPaths)
+	assert.Equal(t, notification.Message(), payload["content"])
+	assert.Equal(t, "Chezy Champs", payload["event_name"])
+	assert.Equal(t, 254.0, payload["team_id"])
+	assert.Equal(t, "Q12", payload["match_name"])
+	assert.Equal(t, 2.0, payload["matches_away"])
+
+	// Check that a team's own webhook takes precedence over the event-wide one.
+	assert.Nil(t, channel.Send(&model.Team{Id: 254, ContactWebhookUrl: server.URL + "/team"}, notification))
+	assert.Equal(t, []string{"/event", "/team"}, requestPaths)
+
+	err := channel.Send(&model.Team{Id: 254, ContactWebhookUrl: server.URL + "/broken"}, notification)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "got status code 500")
+	}
+
+	// Check that nothing is sent if there is nowhere to send it.
+	assert.Nil(t, NewNotificationWebhook("").Send(&model.Team{Id: 254}, notification))
+	assert.Equal(t, 3, len(requestPaths))
+}
+
+func TestNotificationEmailer(t *testing.T) {
+	channel := &NotificationEmailer{Host: "localhost", Port: 1, From: "fms@example.com"}
+	notification := &TeamNotification{EventName: "Chezy Champs", TeamId: 254, MatchName: "E3", Station: "B1",
+		MatchesAway: 1}
+
+	// Teams without an email address are skipped rather than attempting to connect.
+	assert.Nil(t, channel.Send(&model.Team{Id: 254}, notification))
+
+	message := string(channel.buildMessage(&model.Team{Id: 254, ContactEmail: "queue@team254.com"}, notification))
+	headers, body, _ := strings.Cut(message, "\r\n\r\n")
+	assert.Contains(t, headers, "From: fms@example.com\r\n")
+	assert.Contains(t, headers, "To: queue@team254.com\r\n")
+	assert.Contains(t, headers, "Subject: Chezy Champs: Team 254 is in E3\r\n")
+	assert.Equal(t, notification.Message()+"\r\n", body)
+}
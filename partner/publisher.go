@@ -0,0 +1,59 @@
+// Common interface for the destinations to which event results are published.
+
+package partner
+
+import (
+	"fmt"
+
+	"github.com/BotDogs4645/da/model"
+)
+
+// PublishResource Identifies a category of event data that is published as a unit.
+type PublishResource string
+
+const (
+	TeamsResource     PublishResource = "teams"
+	MatchesResource   PublishResource = "matches"
+	RankingsResource  PublishResource = "rankings"
+	AlliancesResource PublishResource = "alliances"
+	AwardsResource    PublishResource = "awards"
+	VideosResource    PublishResource = "videos"
+)
+
+// PublishResources All the resources that can be published, in the order in which they are normally sent.
+var PublishResources = []PublishResource{
+	TeamsResource, MatchesResource, RankingsResource, AlliancesResource, AwardsResource, VideosResource,
+}
+
+// Publisher A destination to which event data is sent whenever it changes. Each method sends the full current state of
+// its resource, so that publishing the same resource twice is harmless.
+type Publisher interface {
+	Name() string
+	PublishTeams(database *model.Database) error
+	PublishMatches(database *model.Database) error
+	PublishRankings(database *model.Database) error
+	PublishAlliances(database *model.Database) error
+	PublishAwards(database *model.Database) error
+	PublishMatchVideos(database *model.Database) error
+	DeletePublishedMatches() error
+}
+
+// Publish Sends the given resource to the given publisher.
+func Publish(publisher Publisher, database *model.Database, resource PublishResource) error {
+	switch resource {
+	case TeamsResource:
+		return publisher.PublishTeams(database)
+	case MatchesResource:
+		return publisher.PublishMatches(database)
+	case RankingsResource:
+		return publisher.PublishRankings(database)
+	case AlliancesResource:
+		return publisher.PublishAlliances(database)
+	case AwardsResource:
+		return publisher.PublishAwards(database)
+	case VideosResource:
+		return publisher.PublishMatchVideos(database)
+	default:
+		return fmt.Errorf("invalid publish resource '%s'", resource)
+	}
+}
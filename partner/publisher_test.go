@@ -0,0 +1,78 @@
+package partner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	database := setupTestDb(t)
+	database.CreateTeam(&model.Team{Id: 254})
+	database.CreateTeam(&model.Team{Id: 1114})
+
+	var payloads []WebhookPayload
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var payload WebhookPayload
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer webhookServer.Close()
+	publisher := NewWebhookPublisher(webhookServer.URL, "Chezy Champs")
+
+	assert.Nil(t, Publish(publisher, database, TeamsResource))
+	assert.Nil(t, publisher.DeletePublishedMatches())
+	if assert.Equal(t, 2, len(payloads)) {
+		assert.Equal(t, "Chezy Champs", payloads[0].EventName)
+		assert.Equal(t, TeamsResource, payloads[0].Resource)
+		assert.Equal(t, "update", payloads[0].Action)
+		assert.Equal(t, []interface{}{"frc254", "frc1114"}, payloads[0].Data)
+		assert.Equal(t, MatchesResource, payloads[1].Resource)
+		assert.Equal(t, "delete_all", payloads[1].Action)
+	}
+
+	// Check that an error response from the webhook is surfaced.
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "oh noes", 500)
+	}))
+	defer failingServer.Close()
+	publisher.Url = failingServer.URL
+	err := publisher.PublishRankings(database)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "got status code 500 from webhook")
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	database := setupTestDb(t)
+	database.CreateTeam(&model.Team{Id: 254})
+	database.CreateAward(&model.Award{Type: model.JudgedAward, AwardName: "Safety Award", TeamId: 254})
+	exporter := NewFileExporter(filepath.Join(t.TempDir(), "results"))
+
+	for _, resource := range []PublishResource{
+		TeamsResource, MatchesResource, RankingsResource, AlliancesResource, AwardsResource,
+	} {
+		assert.Nil(t, Publish(exporter, database, resource))
+		_, err := os.Stat(filepath.Join(exporter.Dir, string(resource)+".json"))
+		assert.Nil(t, err)
+	}
+	data, err := os.ReadFile(filepath.Join(exporter.Dir, "awards.json"))
+	assert.Nil(t, err)
+	var awards []TbaPublishedAward
+	assert.Nil(t, json.Unmarshal(data, &awards))
+	assert.Equal(t, []TbaPublishedAward{{Name: "Safety Award", TeamKey: "frc254"}}, awards)
+
+	assert.Nil(t, exporter.DeletePublishedMatches())
+	_, err = os.Stat(filepath.Join(exporter.Dir, "matches.json"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, exporter.DeletePublishedMatches())
+
+	assert.NotNil(t, Publish(exporter, database, "bogus"))
+}
@@ -12,11 +12,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/BotDogs4645/da/game"
 	"github.com/BotDogs4645/da/model"
 )
 
@@ -26,8 +32,14 @@ const (
 	AvatarsDir = "static/img/avatars"
 )
 
+var youtubeVideoIdRe = regexp.MustCompile("^[A-Za-z0-9_-]{11}$")
+
 type TbaClient struct {
-	BaseUrl         string
+	BaseUrl string
+	// Cache If set, read API responses are stored here and served from it when TBA can't be reached.
+	Cache           *TbaCache
+	offline         bool
+	offlineMutex    sync.Mutex
 	eventCode       string
 	secretId        string
 	secret          string
@@ -35,13 +47,22 @@ type TbaClient struct {
 }
 
 type TbaMatch struct {
-	CompLevel   string                  `json:"comp_level"`
-	SetNumber   int                     `json:"set_number"`
-	MatchNumber int                     `json:"match_number"`
-	Alliances   map[string]*TbaAlliance `json:"alliances"`
-	TimeString  string                  `json:"time_string"`
-	TimeUtc     string                  `json:"time_utc"`
-	DisplayName string                  `json:"display_name"`
+	CompLevel      string                        `json:"comp_level"`
+	SetNumber      int                           `json:"set_number"`
+	MatchNumber    int                           `json:"match_number"`
+	Alliances      map[string]*TbaAlliance       `json:"alliances"`
+	ScoreBreakdown map[string]*TbaScoreBreakdown `json:"score_breakdown"`
+	TimeString     string                        `json:"time_string"`
+	TimeUtc        string                        `json:"time_utc"`
+	DisplayName    string                        `json:"display_name"`
+}
+
+type TbaScoreBreakdown struct {
+	AutoPoints    int `json:"autoPoints"`
+	TeleopPoints  int `json:"teleopPoints"`
+	EndgamePoints int `json:"endGamePoints"`
+	FoulPoints    int `json:"foulPoints"`
+	TotalPoints   int `json:"totalPoints"`
 }
 
 type TbaAlliance struct {
@@ -139,16 +160,20 @@ func NewTbaClient(eventCode, secretId, secret string) *TbaClient {
 		eventNamesCache: make(map[string]string)}
 }
 
-func (client *TbaClient) GetTeam(teamNumber int) (*TbaTeam, error) {
-	path := fmt.Sprintf("/api/v3/team/%s", getTbaTeam(teamNumber))
-	resp, err := client.getRequest(path)
-	if err != nil {
-		return nil, err
-	}
+func (client *TbaClient) Name() string {
+	return "TBA"
+}
 
-	// Get the response and handle errors
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+// IsOffline Returns whether the most recent read request failed and was served from the cache instead.
+func (client *TbaClient) IsOffline() bool {
+	client.offlineMutex.Lock()
+	defer client.offlineMutex.Unlock()
+	return client.offline
+}
+
+func (client *TbaClient) GetTeam(teamNumber int) (*TbaTeam, error) {
+	path := getTbaTeamPath(teamNumber)
+	body, err := client.getCachedRequest(path)
 	if err != nil {
 		return nil, err
 	}
@@ -161,14 +186,7 @@ func (client *TbaClient) GetTeam(teamNumber int) (*TbaTeam, error) {
 
 func (client *TbaClient) GetRobotName(teamNumber int, year int) (string, error) {
 	path := fmt.Sprintf("/api/v3/team/%s/robots", getTbaTeam(teamNumber))
-	resp, err := client.getRequest(path)
-	if err != nil {
-		return "", err
-	}
-
-	// Get the response and handle errors
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.getCachedRequest(path)
 	if err != nil {
 		return "", err
 	}
@@ -188,14 +206,7 @@ func (client *TbaClient) GetRobotName(teamNumber int, year int) (string, error)
 
 func (client *TbaClient) GetTeamAwards(teamNumber int) ([]*TbaAward, error) {
 	path := fmt.Sprintf("/api/v3/team/%s/awards", getTbaTeam(teamNumber))
-	resp, err := client.getRequest(path)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get the response and handle errors
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.getCachedRequest(path)
 	if err != nil {
 		return nil, err
 	}
@@ -221,14 +232,7 @@ func (client *TbaClient) GetTeamAwards(teamNumber int) ([]*TbaAward, error) {
 
 func (client *TbaClient) DownloadTeamAvatar(teamNumber, year int) error {
 	path := fmt.Sprintf("/api/v3/team/%s/media/%d", getTbaTeam(teamNumber), year)
-	resp, err := client.getRequest(path)
-	if err != nil {
-		return err
-	}
-
-	// Get the response and handle errors
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.getCachedRequest(path)
 	if err != nil {
 		return err
 	}
@@ -260,48 +264,98 @@ func (client *TbaClient) DownloadTeamAvatar(teamNumber, year int) error {
 	return fmt.Errorf("no avatar found for team %d in year %d", teamNumber, year)
 }
 
-// PublishTeams Uploads the event team list to The Blue Alliance.
-func (client *TbaClient) PublishTeams(database *model.Database) error {
-	teams, err := database.GetAllTeams()
+// GetEventTeamNumbers Returns the numbers of the teams registered for the event on The Blue Alliance.
+func (client *TbaClient) GetEventTeamNumbers() ([]int, error) {
+	path := fmt.Sprintf("/api/v3/event/%s/teams/keys", client.eventCode)
+	body, err := client.getCachedRequest(path)
 	if err != nil {
+		return nil, err
+	}
+
+	var teamKeys []string
+	if err = json.Unmarshal(body, &teamKeys); err != nil {
+		return nil, err
+	}
+	teamNumbers := make([]int, 0, len(teamKeys))
+	for _, teamKey := range teamKeys {
+		teamNumber, err := strconv.Atoi(strings.TrimPrefix(teamKey, "frc"))
+		if err != nil {
+			return nil, fmt.Errorf("could not interpret team key from TBA: %s", teamKey)
+		}
+		teamNumbers = append(teamNumbers, teamNumber)
+	}
+	return teamNumbers, nil
+}
+
+// PrefetchTeam Downloads all the data about the given team that is used to populate the team list, so that it is
+// available from the cache later if TBA can't be reached. Returns an error if TBA can't be reached now.
+func (client *TbaClient) PrefetchTeam(teamNumber, year int) error {
+	if client.Cache == nil {
+		return fmt.Errorf("TBA cache is not configured")
+	}
+	if _, err := client.GetTeam(teamNumber); err != nil {
+		return err
+	}
+	if _, err := client.GetRobotName(teamNumber, year); err != nil {
+		return err
+	}
+	if _, err := client.GetTeamAwards(teamNumber); err != nil {
 		return err
 	}
 
-	// Build a JSON array of TBA-format team keys (e.g. "frc254").
-	teamKeys := make([]string, len(teams))
-	for i, team := range teams {
-		teamKeys[i] = getTbaTeam(team.Id)
+	// Not every team has an avatar, so a missing one isn't an error.
+	client.DownloadTeamAvatar(teamNumber, year)
+	if client.IsOffline() {
+		return fmt.Errorf("could not reach TBA to fetch data for team %d", teamNumber)
 	}
-	jsonBody, err := json.Marshal(teamKeys)
+	return nil
+}
+
+// PublishTeams Uploads the event team list to The Blue Alliance.
+func (client *TbaClient) PublishTeams(database *model.Database) error {
+	teamKeys, err := buildTbaTeams(database)
 	if err != nil {
 		return err
 	}
+	return client.postJson("team_list", "update", teamKeys)
+}
 
-	resp, err := client.postRequest("team_list", "update", jsonBody)
+// Builds the event team list as an array of TBA-format team keys (e.g. "frc254").
+func buildTbaTeams(database *model.Database) ([]string, error) {
+	teams, err := database.GetAllTeams()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("got status code %d from TBA: %s", resp.StatusCode, body)
+
+	teamKeys := make([]string, len(teams))
+	for i, team := range teams {
+		teamKeys[i] = getTbaTeam(team.Id)
 	}
-	return nil
+	return teamKeys, nil
 }
 
 // PublishMatches Uploads the qualification and elimination match schedule and results to The Blue Alliance.
 func (client *TbaClient) PublishMatches(database *model.Database) error {
-	qualMatches, err := database.GetMatchesByType("qualification")
+	tbaMatches, err := buildTbaMatches(database)
 	if err != nil {
 		return err
 	}
+	return client.postJson("matches", "update", tbaMatches)
+}
+
+// Builds the qualification and elimination match schedule and results as an array of TBA-format matches.
+func buildTbaMatches(database *model.Database) ([]TbaMatch, error) {
+	qualMatches, err := database.GetMatchesByType("qualification")
+	if err != nil {
+		return nil, err
+	}
 	elimMatches, err := database.GetMatchesByType("elimination")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	eventSettings, err := database.GetEventSettings()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	matches := append(qualMatches, elimMatches...)
 	tbaMatches := make([]TbaMatch, len(matches))
@@ -313,10 +367,11 @@ func (client *TbaClient) PublishMatches(database *model.Database) error {
 		// Fill in scores if the match has been played.
 		var redScoreSummary, blueScoreSummary int
 		var redScore, blueScore *int
+		var scoreBreakdown map[string]*TbaScoreBreakdown
 		if match.IsComplete() {
 			matchResult, err := database.GetMatchResultForMatch(match.Id)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			if matchResult != nil {
 				redScoreSummary = matchResult.RedScore.AutoPoints +
@@ -327,6 +382,10 @@ func (client *TbaClient) PublishMatches(database *model.Database) error {
 					matchResult.BlueScore.EndgamePoints
 				redScore = &redScoreSummary
 				blueScore = &blueScoreSummary
+				scoreBreakdown = map[string]*TbaScoreBreakdown{
+					"red":  createTbaScoreBreakdown(matchResult.RedScoreSummary()),
+					"blue": createTbaScoreBreakdown(matchResult.BlueScoreSummary()),
+				}
 			}
 		}
 		alliances := make(map[string]*TbaAlliance)
@@ -336,39 +395,35 @@ func (client *TbaClient) PublishMatches(database *model.Database) error {
 			[3]bool{match.Blue1IsSurrogate, match.Blue2IsSurrogate, match.Blue3IsSurrogate}, blueScore)
 
 		tbaMatches[i] = TbaMatch{
-			CompLevel:   "qm",
-			SetNumber:   0,
-			MatchNumber: matchNumber,
-			Alliances:   alliances,
-			TimeString:  match.Time.Local().Format("3:04 PM"),
-			TimeUtc:     match.Time.UTC().Format("2006-01-02T15:04:05"),
+			CompLevel:      "qm",
+			SetNumber:      0,
+			MatchNumber:    matchNumber,
+			Alliances:      alliances,
+			ScoreBreakdown: scoreBreakdown,
+			TimeString:     match.Time.Local().Format("3:04 PM"),
+			TimeUtc:        match.Time.UTC().Format("2006-01-02T15:04:05"),
 		}
 		if match.Type == "elimination" {
 			setElimMatchKey(&tbaMatches[i], &match, eventSettings.ElimType)
 		}
 	}
-	jsonBody, err := json.Marshal(tbaMatches)
-	if err != nil {
-		return err
-	}
+	return tbaMatches, nil
+}
 
-	resp, err := client.postRequest("matches", "update", jsonBody)
+// Uploads the team standings to The Blue Alliance.
+func (client *TbaClient) PublishRankings(database *model.Database) error {
+	tbaRankings, err := buildTbaRankings(database)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("got status code %d from TBA: %s", resp.StatusCode, body)
-	}
-	return nil
+	return client.postJson("rankings", "update", tbaRankings)
 }
 
-// Uploads the team standings to The Blue Alliance.
-func (client *TbaClient) PublishRankings(database *model.Database) error {
+// Builds the team standings as a TBA-format rankings object.
+func buildTbaRankings(database *model.Database) (*TbaRankings, error) {
 	rankings, err := database.GetAllRankings()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Build a JSON object of TBA-format rankings.
@@ -388,28 +443,23 @@ func (client *TbaClient) PublishRankings(database *model.Database) error {
 			Played:  ranking.Played,
 		}
 	}
-	jsonBody, err := json.Marshal(TbaRankings{breakdowns, tbaRankings})
-	if err != nil {
-		return err
-	}
+	return &TbaRankings{breakdowns, tbaRankings}, nil
+}
 
-	resp, err := client.postRequest("rankings", "update", jsonBody)
+// Uploads the alliances selection results to The Blue Alliance.
+func (client *TbaClient) PublishAlliances(database *model.Database) error {
+	tbaAlliances, err := buildTbaAlliances(database)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("got status code %d from TBA: %s", resp.StatusCode, body)
-	}
-	return nil
+	return client.postJson("alliance_selections", "update", tbaAlliances)
 }
 
-// Uploads the alliances selection results to The Blue Alliance.
-func (client *TbaClient) PublishAlliances(database *model.Database) error {
+// Builds the alliance selection results as an array of TBA-format team key lists.
+func buildTbaAlliances(database *model.Database) ([][]string, error) {
 	alliances, err := database.GetAllAlliances()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Build a JSON object of TBA-format alliances.
@@ -419,21 +469,73 @@ func (client *TbaClient) PublishAlliances(database *model.Database) error {
 			tbaAlliances[i] = append(tbaAlliances[i], getTbaTeam(allianceTeamId))
 		}
 	}
-	jsonBody, err := json.Marshal(tbaAlliances)
+	return tbaAlliances, nil
+}
+
+// PublishMatchVideos Uploads the YouTube videos for each match to The Blue Alliance.
+func (client *TbaClient) PublishMatchVideos(database *model.Database) error {
+	matchVideos, err := buildTbaMatchVideos(database)
 	if err != nil {
 		return err
 	}
+	if len(matchVideos) == 0 {
+		return nil
+	}
+	return client.postJson("match_videos", "add", matchVideos)
+}
 
-	resp, err := client.postRequest("alliance_selections", "update", jsonBody)
+// Builds a map of TBA partial match keys (e.g. "qm12" or "sf2m1") to YouTube video IDs for every match that has a video
+// URL or an offset into the event livestream VOD. A VOD offset is given as a "?t=" parameter on the video ID.
+func buildTbaMatchVideos(database *model.Database) (map[string]string, error) {
+	qualMatches, err := database.GetMatchesByType("qualification")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("got status code %d from TBA: %s", resp.StatusCode, body)
+	elimMatches, err := database.GetMatchesByType("elimination")
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	eventSettings, err := database.GetEventSettings()
+	if err != nil {
+		return nil, err
+	}
+	vodVideoId, hasVod := ParseYoutubeVideoId(eventSettings.LivestreamVodUrl)
+
+	matchVideos := make(map[string]string)
+	for _, match := range append(qualMatches, elimMatches...) {
+		if match.VideoUrl != "" {
+			if videoId, ok := ParseYoutubeVideoId(match.VideoUrl); ok {
+				matchVideos[getTbaMatchKey(&match, eventSettings.ElimType)] = videoId
+			}
+		} else if match.VodOffsetSec > 0 && hasVod {
+			matchVideos[getTbaMatchKey(&match, eventSettings.ElimType)] =
+				fmt.Sprintf("%s?t=%d", vodVideoId, match.VodOffsetSec)
+		}
+	}
+	return matchVideos, nil
+}
+
+// ParseYoutubeVideoId Extracts the video ID from the given YouTube URL, returning false if it isn't a YouTube video URL.
+func ParseYoutubeVideoId(videoUrl string) (string, bool) {
+	parsedUrl, err := url.Parse(strings.TrimSpace(videoUrl))
+	if err != nil {
+		return "", false
+	}
+	var videoId string
+	switch strings.TrimPrefix(strings.TrimPrefix(parsedUrl.Host, "www."), "m.") {
+	case "youtu.be":
+		videoId = strings.TrimPrefix(parsedUrl.Path, "/")
+	case "youtube.com":
+		if parsedUrl.Path == "/watch" {
+			videoId = parsedUrl.Query().Get("v")
+		} else if strings.HasPrefix(parsedUrl.Path, "/live/") || strings.HasPrefix(parsedUrl.Path, "/embed/") {
+			videoId = parsedUrl.Path[strings.LastIndex(parsedUrl.Path, "/")+1:]
+		}
+	}
+	if !youtubeVideoIdRe.MatchString(videoId) {
+		return "", false
+	}
+	return videoId, true
 }
 
 // Clears out the existing match data on The Blue Alliance for the event.
@@ -452,14 +554,7 @@ func (client *TbaClient) DeletePublishedMatches() error {
 
 func (client *TbaClient) getEventName(eventCode string) (string, error) {
 	path := fmt.Sprintf("/api/v3/event/%s", eventCode)
-	resp, err := client.getRequest(path)
-	if err != nil {
-		return "", err
-	}
-
-	// Get the response and handle errors
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.getCachedRequest(path)
 	if err != nil {
 		return "", err
 	}
@@ -473,6 +568,19 @@ func (client *TbaClient) getEventName(eventCode string) (string, error) {
 	return event.Name, err
 }
 
+// Returns the partial match key (i.e. without the event code) TBA uses to identify the given match.
+func getTbaMatchKey(match *model.Match, elimType string) string {
+	matchNumber, _ := strconv.Atoi(match.DisplayName)
+	tbaMatch := TbaMatch{CompLevel: "qm", MatchNumber: matchNumber}
+	if match.Type == "elimination" {
+		setElimMatchKey(&tbaMatch, match, elimType)
+	}
+	if tbaMatch.CompLevel == "qm" {
+		return fmt.Sprintf("qm%d", tbaMatch.MatchNumber)
+	}
+	return fmt.Sprintf("%s%dm%d", tbaMatch.CompLevel, tbaMatch.SetNumber, tbaMatch.MatchNumber)
+}
+
 // Converts an integer team number into the "frcXXXX" format TBA expects.
 func getTbaTeam(team int) string {
 	return fmt.Sprintf("frc%d", team)
@@ -492,6 +600,80 @@ func (client *TbaClient) getRequest(path string) (*http.Response, error) {
 	return httpClient.Do(req)
 }
 
+// Sends a GET request to the TBA API and returns the response body, storing it in the cache if one is configured. If
+// TBA can't be reached or doesn't respond successfully, the last cached response for the same path is returned instead.
+func (client *TbaClient) getCachedRequest(path string) ([]byte, error) {
+	body, statusCode, err := client.getRequestBody(path)
+	if err == nil && statusCode == 200 {
+		client.setOffline(false)
+		if client.Cache != nil {
+			if err = client.Cache.Put(path, body); err != nil {
+				log.Printf("Failed to cache TBA response for %s: %v", path, err)
+			}
+		}
+		return body, nil
+	}
+
+	// Error responses are as likely as a failed connection to come from a TBA outage or a captive portal rather than
+	// from TBA itself, so prefer any cached response to them.
+	if client.Cache != nil {
+		if cachedBody, fetchedAt, ok := client.Cache.Get(path); ok {
+			reason := fmt.Sprintf("got status code %d", statusCode)
+			if err != nil {
+				reason = err.Error()
+			}
+			log.Printf("Failed to reach TBA (%s); using cached response for %s from %s.", reason, path,
+				fetchedAt.Format(time.RFC3339))
+			client.setOffline(true)
+			return cachedBody, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.setOffline(false)
+	return body, nil
+}
+
+func (client *TbaClient) setOffline(offline bool) {
+	client.offlineMutex.Lock()
+	defer client.offlineMutex.Unlock()
+	client.offline = offline
+}
+
+// Sends a GET request to the TBA API and reads the full response body.
+func (client *TbaClient) getRequestBody(path string) ([]byte, int, error) {
+	resp, err := client.getRequest(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+// Sends the given payload to the TBA trusted API as the given action on the given resource.
+func (client *TbaClient) postJson(resource, action string, payload interface{}) error {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.postRequest(resource, action, jsonBody)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("got status code %d from TBA: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
 // Signs the request and sends it to the TBA API.
 func (client *TbaClient) postRequest(resource string, action string, body []byte) (*http.Response, error) {
 	path := fmt.Sprintf("/api/trusted/v1/event/%s/%s/%s", client.eventCode, resource, action)
@@ -507,6 +689,17 @@ func (client *TbaClient) postRequest(resource string, action string, body []byte
 	return httpClient.Do(request)
 }
 
+// Converts the given score summary into the per-period breakdown format TBA expects. This game doesn't assess fouls,
+// so the foul points are always zero.
+func createTbaScoreBreakdown(summary *game.ScoreSummary) *TbaScoreBreakdown {
+	return &TbaScoreBreakdown{
+		AutoPoints:    summary.AutoPoints,
+		TeleopPoints:  summary.TeleopPoints,
+		EndgamePoints: summary.EndgamePoints,
+		TotalPoints:   summary.Score,
+	}
+}
+
 func createTbaAlliance(teamIds [3]int, surrogates [3]bool, score *int) *TbaAlliance {
 	alliance := TbaAlliance{Surrogates: []string{}, Dqs: []string{}, Score: score}
 	for i, teamId := range teamIds {
@@ -522,10 +715,19 @@ func createTbaAlliance(teamIds [3]int, surrogates [3]bool, score *int) *TbaAllia
 
 // Uploads the awards to The Blue Alliance.
 func (client *TbaClient) PublishAwards(database *model.Database) error {
-	awards, err := database.GetAllAwards()
+	tbaAwards, err := buildTbaAwards(database)
 	if err != nil {
 		return err
 	}
+	return client.postJson("awards", "update", tbaAwards)
+}
+
+// Builds the awards as an array of TBA-format award models.
+func buildTbaAwards(database *model.Database) ([]TbaPublishedAward, error) {
+	awards, err := database.GetAllAwards()
+	if err != nil {
+		return nil, err
+	}
 
 	// Build a JSON array of TBA-format award models.
 	tbaAwards := make([]TbaPublishedAward, len(awards))
@@ -534,21 +736,7 @@ func (client *TbaClient) PublishAwards(database *model.Database) error {
 		tbaAwards[i].TeamKey = getTbaTeam(award.TeamId)
 		tbaAwards[i].Awardee = award.PersonName
 	}
-	jsonBody, err := json.Marshal(tbaAwards)
-	if err != nil {
-		return err
-	}
-
-	resp, err := client.postRequest("awards", "update", jsonBody)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("got status code %d from TBA: %s", resp.StatusCode, body)
-	}
-	return nil
+	return tbaAwards, nil
 }
 
 // Sets the match key attributes on TbaMatch based on the match and bracket type.
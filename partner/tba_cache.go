@@ -0,0 +1,83 @@
+// On-disk cache of responses from The Blue Alliance read API, for looking up team data without a network connection.
+
+package partner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Age after which cached TBA data is flagged as stale in the UI.
+const TbaCacheStaleAge = 7 * 24 * time.Hour
+
+type TbaCache struct {
+	dir string
+}
+
+type tbaCacheEntry struct {
+	Path      string
+	Body      string
+	FetchedAt time.Time
+}
+
+// NewTbaCache Creates a cache that stores its entries as files in the given directory.
+func NewTbaCache(dir string) *TbaCache {
+	return &TbaCache{dir: dir}
+}
+
+// Get Returns the cached response body for the given API path and the time it was fetched, or false if it isn't cached.
+func (cache *TbaCache) Get(path string) ([]byte, time.Time, bool) {
+	data, err := os.ReadFile(cache.filePath(path))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var entry tbaCacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil || entry.Path != path {
+		return nil, time.Time{}, false
+	}
+	return []byte(entry.Body), entry.FetchedAt, true
+}
+
+// Put Stores the given response body for the given API path, timestamped with the current time.
+func (cache *TbaCache) Put(path string, body []byte) error {
+	if err := os.MkdirAll(cache.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(tbaCacheEntry{Path: path, Body: string(body), FetchedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a partially written entry is never read back.
+	tempPath := cache.filePath(path) + ".tmp"
+	if err = os.WriteFile(tempPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, cache.filePath(path))
+}
+
+// TeamFetchedAt Returns the time at which the given team's basic info was last fetched from TBA, or false if it isn't
+// cached.
+func (cache *TbaCache) TeamFetchedAt(teamNumber int) (time.Time, bool) {
+	_, fetchedAt, ok := cache.Get(getTbaTeamPath(teamNumber))
+	return fetchedAt, ok
+}
+
+// Clear Deletes all cached entries.
+func (cache *TbaCache) Clear() error {
+	return os.RemoveAll(cache.dir)
+}
+
+// Returns the name of the file in which the entry for the given API path is stored.
+func (cache *TbaCache) filePath(path string) string {
+	return filepath.Join(cache.dir, strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")+".json")
+}
+
+// Returns the API path for the given team's basic info.
+func getTbaTeamPath(teamNumber int) string {
+	return fmt.Sprintf("/api/v3/team/%s", getTbaTeam(teamNumber))
+}
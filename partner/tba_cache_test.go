@@ -0,0 +1,147 @@
+package partner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestTbaCache(t *testing.T) *TbaCache {
+	return NewTbaCache(t.TempDir())
+}
+
+func TestTbaCachePutGet(t *testing.T) {
+	cache := setupTestTbaCache(t)
+
+	_, _, ok := cache.Get("/api/v3/team/frc254")
+	assert.False(t, ok)
+	_, ok = cache.TeamFetchedAt(254)
+	assert.False(t, ok)
+
+	assert.Nil(t, cache.Put("/api/v3/team/frc254", []byte(`{"team_number": 254}`)))
+	body, fetchedAt, ok := cache.Get("/api/v3/team/frc254")
+	assert.True(t, ok)
+	assert.Equal(t, `{"team_number": 254}`, string(body))
+	assert.WithinDuration(t, time.Now(), fetchedAt, time.Minute)
+	teamFetchedAt, ok := cache.TeamFetchedAt(254)
+	assert.True(t, ok)
+	assert.Equal(t, fetchedAt, teamFetchedAt)
+	_, _, ok = cache.Get("/api/v3/team/frc254/robots")
+	assert.False(t, ok)
+
+	assert.Nil(t, cache.Clear())
+	_, _, ok = cache.Get("/api/v3/team/frc254")
+	assert.False(t, ok)
+}
+
+func TestTbaClientServesFromCacheWhenOffline(t *testing.T) {
+	serverError := false
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serverError {
+			http.Error(w, "Service Unavailable", 503)
+		} else if strings.Contains(r.RequestURI, "robots") {
+			fmt.Fprintln(w, `[{"robot_name": "Barrage", "year": 2023}]`)
+		} else if strings.Contains(r.RequestURI, "frc1114") {
+			http.Error(w, `{"Errors": ["team not found"]}`, 404)
+		} else {
+			fmt.Fprintln(w, `{"team_number": 254, "nickname": "The Cheesy Poofs"}`)
+		}
+	}))
+	client := NewTbaClient("my_event_code", "my_secret_id", "my_secret")
+	client.BaseUrl = tbaServer.URL
+	client.Cache = setupTestTbaCache(t)
+
+	team, err := client.GetTeam(254)
+	assert.Nil(t, err)
+	assert.Equal(t, "The Cheesy Poofs", team.Nickname)
+	robotName, err := client.GetRobotName(254, 2023)
+	assert.Nil(t, err)
+	assert.Equal(t, "Barrage", robotName)
+	assert.False(t, client.IsOffline())
+
+	// Error responses shouldn't be cached.
+	_, err = client.GetTeam(1114)
+	assert.Nil(t, err)
+	_, ok := client.Cache.TeamFetchedAt(1114)
+	assert.False(t, ok)
+
+	// Check that the cached responses are used when TBA returns errors.
+	serverError = true
+	team, err = client.GetTeam(254)
+	assert.Nil(t, err)
+	assert.Equal(t, "The Cheesy Poofs", team.Nickname)
+	assert.True(t, client.IsOffline())
+	serverError = false
+	client.GetTeam(254)
+	assert.False(t, client.IsOffline())
+
+	// Take TBA offline and check that the cached responses are used.
+	tbaServer.Close()
+	team, err = client.GetTeam(254)
+	assert.Nil(t, err)
+	assert.Equal(t, "The Cheesy Poofs", team.Nickname)
+	robotName, err = client.GetRobotName(254, 2023)
+	assert.Nil(t, err)
+	assert.Equal(t, "Barrage", robotName)
+	assert.True(t, client.IsOffline())
+	_, err = client.GetTeam(1114)
+	assert.NotNil(t, err)
+
+	// Check that nothing is served from the cache if it isn't configured.
+	client.Cache = nil
+	_, err = client.GetTeam(254)
+	assert.NotNil(t, err)
+}
+
+func TestTbaClientPrefetch(t *testing.T) {
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.RequestURI, "teams/keys") {
+			fmt.Fprintln(w, `["frc254", "frc1114"]`)
+		} else if strings.Contains(r.RequestURI, "robots") {
+			fmt.Fprintln(w, `[]`)
+		} else if strings.Contains(r.RequestURI, "awards") {
+			fmt.Fprintln(w, `[{"name": "Chairman's Award", "event_key": "2023cmptx", "year": 2023}]`)
+		} else if strings.Contains(r.RequestURI, "media") {
+			fmt.Fprintln(w, `[]`)
+		} else if strings.Contains(r.RequestURI, "event") {
+			fmt.Fprintln(w, `{"name": "Championship"}`)
+		} else {
+			fmt.Fprintln(w, `{"team_number": 254}`)
+		}
+	}))
+	client := NewTbaClient("2023cmptx", "my_secret_id", "my_secret")
+	client.BaseUrl = tbaServer.URL
+	assert.NotNil(t, client.PrefetchTeam(254, 2023))
+	client.Cache = setupTestTbaCache(t)
+
+	teamNumbers, err := client.GetEventTeamNumbers()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{254, 1114}, teamNumbers)
+	assert.Nil(t, client.PrefetchTeam(254, 2023))
+	for _, path := range []string{"/api/v3/team/frc254", "/api/v3/team/frc254/robots", "/api/v3/team/frc254/awards",
+		"/api/v3/team/frc254/media/2023", "/api/v3/event/2023cmptx"} {
+		_, _, ok := client.Cache.Get(path)
+		assert.True(t, ok, path)
+	}
+
+	// Check that the awards can be looked up offline, including the event names, but that prefetching fails.
+	tbaServer.Close()
+	cache := client.Cache
+	client = NewTbaClient("2023cmptx", "my_secret_id", "my_secret")
+	client.BaseUrl = tbaServer.URL
+	client.Cache = cache
+	awards, err := client.GetTeamAwards(254)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(awards)) {
+		assert.Equal(t, "Championship", awards[0].EventName)
+	}
+	err = client.PrefetchTeam(254, 2023)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not reach TBA")
+	}
+}
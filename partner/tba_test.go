@@ -58,6 +58,14 @@ func TestPublishMatches(t *testing.T) {
 		assert.Equal(t, 2, len(matches))
 		assert.Equal(t, "qm", matches[0].CompLevel)
 		assert.Equal(t, "sf", matches[1].CompLevel)
+		if assert.NotNil(t, matches[0].ScoreBreakdown["red"]) {
+			redSummary := matchResult1.RedScoreSummary()
+			assert.Equal(t, redSummary.AutoPoints, matches[0].ScoreBreakdown["red"].AutoPoints)
+			assert.Equal(t, redSummary.EndgamePoints, matches[0].ScoreBreakdown["red"].EndgamePoints)
+			assert.Equal(t, redSummary.Score, matches[0].ScoreBreakdown["red"].TotalPoints)
+			assert.Equal(t, *matches[0].Alliances["red"].Score, matches[0].ScoreBreakdown["red"].TotalPoints)
+		}
+		assert.Nil(t, matches[1].ScoreBreakdown)
 	}))
 	defer tbaServer.Close()
 	client := NewTbaClient("my_event_code", "my_secret_id", "my_secret")
@@ -66,6 +74,57 @@ func TestPublishMatches(t *testing.T) {
 	assert.Nil(t, client.PublishMatches(database))
 }
 
+func TestPublishMatchVideos(t *testing.T) {
+	database := setupTestDb(t)
+	eventSettings, _ := database.GetEventSettings()
+	eventSettings.ElimType = "single"
+	eventSettings.LivestreamVodUrl = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	database.UpdateEventSettings(eventSettings)
+
+	database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2", VideoUrl: "https://youtu.be/abcdefghijk"})
+	database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "3", VodOffsetSec: 3725})
+	database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "4"})
+	database.CreateMatch(&model.Match{Type: "elimination", DisplayName: "SF2-2", ElimRound: 3, ElimGroup: 2,
+		ElimInstance: 2, VideoUrl: "https://www.youtube.com/live/ABCDEFGHIJK?feature=share"})
+
+	// Mock the TBA server.
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.String(), "event/my_event_code/match_videos/add")
+		var matchVideos map[string]string
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&matchVideos))
+		assert.Equal(
+			t,
+			map[string]string{"qm2": "abcdefghijk", "qm3": "dQw4w9WgXcQ?t=3725", "sf2m2": "ABCDEFGHIJK"},
+			matchVideos,
+		)
+	}))
+	defer tbaServer.Close()
+	client := NewTbaClient("my_event_code", "my_secret_id", "my_secret")
+	client.BaseUrl = tbaServer.URL
+
+	assert.Nil(t, client.PublishMatchVideos(database))
+}
+
+func TestParseYoutubeVideoId(t *testing.T) {
+	for _, videoUrl := range []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://m.youtube.com/watch?v=dQw4w9WgXcQ&t=30",
+		"https://youtu.be/dQw4w9WgXcQ",
+		"https://www.youtube.com/live/dQw4w9WgXcQ",
+		"https://www.youtube.com/embed/dQw4w9WgXcQ",
+	} {
+		videoId, ok := ParseYoutubeVideoId(videoUrl)
+		assert.True(t, ok, videoUrl)
+		assert.Equal(t, "dQw4w9WgXcQ", videoId)
+	}
+	for _, videoUrl := range []string{
+		"", "https://www.twitch.tv/videos/12345", "https://www.youtube.com/watch?v=short", "https://youtube.com/about",
+	} {
+		_, ok := ParseYoutubeVideoId(videoUrl)
+		assert.False(t, ok, videoUrl)
+	}
+}
+
 func TestPublishRankings(t *testing.T) {
 	database := setupTestDb(t)
 
@@ -0,0 +1,124 @@
+// Publisher that posts event data as JSON to an arbitrary webhook URL.
+
+package partner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type WebhookPublisher struct {
+	Url       string
+	eventName string
+}
+
+// WebhookPayload The body of each request sent to the webhook. The data is in the same format that is sent to The Blue
+// Alliance for the given resource.
+type WebhookPayload struct {
+	EventName string          `json:"event_name"`
+	Resource  PublishResource `json:"resource"`
+	Action    string          `json:"action"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      interface{}     `json:"data"`
+}
+
+func NewWebhookPublisher(url, eventName string) *WebhookPublisher {
+	return &WebhookPublisher{Url: url, eventName: eventName}
+}
+
+func (publisher *WebhookPublisher) Name() string {
+	return "webhook"
+}
+
+// PublishTeams Posts the event team list to the webhook.
+func (publisher *WebhookPublisher) PublishTeams(database *model.Database) error {
+	teamKeys, err := buildTbaTeams(database)
+	if err != nil {
+		return err
+	}
+	return publisher.post(TeamsResource, "update", teamKeys)
+}
+
+// PublishMatches Posts the match schedule and results to the webhook.
+func (publisher *WebhookPublisher) PublishMatches(database *model.Database) error {
+	tbaMatches, err := buildTbaMatches(database)
+	if err != nil {
+		return err
+	}
+	return publisher.post(MatchesResource, "update", tbaMatches)
+}
+
+// PublishRankings Posts the team standings to the webhook.
+func (publisher *WebhookPublisher) PublishRankings(database *model.Database) error {
+	tbaRankings, err := buildTbaRankings(database)
+	if err != nil {
+		return err
+	}
+	return publisher.post(RankingsResource, "update", tbaRankings)
+}
+
+// PublishAlliances Posts the alliance selection results to the webhook.
+func (publisher *WebhookPublisher) PublishAlliances(database *model.Database) error {
+	tbaAlliances, err := buildTbaAlliances(database)
+	if err != nil {
+		return err
+	}
+	return publisher.post(AlliancesResource, "update", tbaAlliances)
+}
+
+// PublishAwards Posts the awards to the webhook.
+func (publisher *WebhookPublisher) PublishAwards(database *model.Database) error {
+	tbaAwards, err := buildTbaAwards(database)
+	if err != nil {
+		return err
+	}
+	return publisher.post(AwardsResource, "update", tbaAwards)
+}
+
+// PublishMatchVideos Posts the match video IDs to the webhook.
+func (publisher *WebhookPublisher) PublishMatchVideos(database *model.Database) error {
+	matchVideos, err := buildTbaMatchVideos(database)
+	if err != nil {
+		return err
+	}
+	return publisher.post(VideosResource, "update", matchVideos)
+}
+
+// DeletePublishedMatches Tells the webhook that all previously published matches should be discarded.
+func (publisher *WebhookPublisher) DeletePublishedMatches() error {
+	return publisher.post(MatchesResource, "delete_all", nil)
+}
+
+// Sends the given data to the webhook, returning an error if it doesn't respond with a success status code.
+func (publisher *WebhookPublisher) post(resource PublishResource, action string, data interface{}) error {
+	jsonBody, err := json.Marshal(WebhookPayload{
+		EventName: publisher.eventName,
+		Resource:  resource,
+		Action:    action,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	httpClient := &http.Client{Timeout: webhookTimeout}
+	resp, err := httpClient.Post(publisher.Url, "application/json", bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("got status code %d from webhook: %s", resp.StatusCode, body)
+	}
+	return nil
+}
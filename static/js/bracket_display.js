@@ -5,7 +5,7 @@
 
 var websocket;
 
-// Handles a websocket message to load a new match.
+// Handles a websocket message to load a new match or refresh the bracket after a result or coupon change.
 const handleMatchLoad = function(data) {
   $("#bracketSvg").attr("src", "/api/bracket/svg?activeMatch=current&v=" + new Date().getTime());
 };
@@ -14,5 +14,6 @@ $(function() {
   // Set up the websocket back to the server.
   websocket = new CheesyWebsocket("/displays/bracket/websocket", {
     matchLoad: function(event) { handleMatchLoad(event.data); },
+    scorePosted: function(event) { handleMatchLoad(event.data); },
   });
 });
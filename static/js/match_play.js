@@ -12,6 +12,14 @@ var substituteTeam = function(team, position) {
   websocket.send("substituteTeam", { team: parseInt(team), position: position })
 };
 
+// Sends a websocket message to call the next available backup team into the given alliance.
+var invokeBackup = function(alliance) {
+  var team = parseInt($("#" + alliance + "BackupReplacedTeam").val());
+  if (confirm("Are you sure you want to replace team " + team + " with the next available backup team?")) {
+    websocket.send("invokeBackup", { alliance: alliance, team: team });
+  }
+};
+
 // Sends a websocket message to toggle the bypass status for an alliance station.
 var toggleBypass = function(station) {
   websocket.send("toggleBypass", station);
@@ -74,7 +82,7 @@ var startTimeout = function() {
   if (duration.length > 1) {
     durationSec = durationSec * 60 + parseFloat(duration[1]);
   }
-  websocket.send("startTimeout", durationSec);
+  websocket.send("startTimeout", {durationSec: durationSec, alliance: $("#timeoutAlliance").val()});
 };
 
 // Sends a websocket message to update the realtime score
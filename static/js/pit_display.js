@@ -0,0 +1,48 @@
+// Client-side logic for the per-team pit display.
+
+var websocket;
+var firstMessageReceived = {};
+
+// Handles a websocket message indicating that a match has been loaded or scored, by reloading to pick up the changes.
+var handleMatchChange = function(messageType) {
+  // Since the server always sends each message upon establishing the websocket connection, ignore the first ones.
+  if (firstMessageReceived[messageType]) {
+    location.reload();
+  }
+  firstMessageReceived[messageType] = true;
+};
+
+// Handles a websocket message to update the event status message.
+var handleEventStatus = function(data) {
+  $("#earlyLateMessage").text(data.EarlyLateMessage);
+};
+
+// Updates the countdown until the team's next match should be queued.
+var updateQueueCountdown = function() {
+  var countdown = $("#queueCountdown");
+  if (countdown.length === 0) {
+    return;
+  }
+  var countdownSec = Math.floor((countdown.data("queue-time") - new Date().getTime()) / 1000);
+  if (countdownSec <= 0) {
+    countdown.text("now").addClass("overdue");
+    return;
+  }
+  var seconds = String(countdownSec % 60);
+  if (seconds.length === 1) {
+    seconds = "0" + seconds;
+  }
+  countdown.text(Math.floor(countdownSec / 60) + ":" + seconds);
+};
+
+$(function() {
+  // Set up the websocket back to the server.
+  websocket = new CheesyWebsocket("/displays/pit/websocket", {
+    eventStatus: function(event) { handleEventStatus(event.data); },
+    matchLoad: function(event) { handleMatchChange("matchLoad"); },
+    scorePosted: function(event) { handleMatchChange("scorePosted"); },
+  });
+
+  updateQueueCountdown();
+  setInterval(updateQueueCountdown, 1000);
+});
@@ -0,0 +1,44 @@
+// Client-side logic for the live match view of the public portal.
+
+var websocket;
+
+// Handles a websocket message to update the teams for the current match.
+var handleMatchLoad = function(data) {
+  if (data.Match.Type === "test") {
+    $("#liveMatch").addClass("hidden");
+    $("#noMatch").removeClass("hidden");
+    return;
+  }
+  $("#liveMatch").removeClass("hidden");
+  $("#noMatch").addClass("hidden");
+  $("#matchName").text(data.MatchName);
+  var joinTeams = function(teams) {
+    return $.grep(teams, function(team) { return team > 0; }).join(" ");
+  };
+  $("#redTeams").text(joinTeams([data.Match.Red1, data.Match.Red2, data.Match.Red3]));
+  $("#blueTeams").text(joinTeams([data.Match.Blue1, data.Match.Blue2, data.Match.Blue3]));
+};
+
+// Handles a websocket message to update the match time countdown.
+var handleMatchTime = function(data) {
+  translateMatchTime(data, function(matchState, matchStateText, countdownSec) {
+    $("#matchState").text(matchStateText);
+    $("#matchTime").text(countdownSec);
+  });
+};
+
+// Handles a websocket message to update the realtime scoring fields.
+var handleRealtimeScore = function(data) {
+  $("#redScore").text(data.Red.ScoreSummary.Score);
+  $("#blueScore").text(data.Blue.ScoreSummary.Score);
+};
+
+$(function() {
+  // Set up the websocket back to the server.
+  websocket = new CheesyWebsocket("/public/websocket", {
+    matchLoad: function(event) { handleMatchLoad(event.data); },
+    matchTime: function(event) { handleMatchTime(event.data); },
+    matchTiming: function(event) { handleMatchTiming(event.data); },
+    realtimeScore: function(event) { handleRealtimeScore(event.data); },
+  });
+});
@@ -27,6 +27,10 @@ func CalculateRankings(database *model.Database, preservePreviousRank bool) (gam
 		if err != nil {
 			return nil, err
 		}
+		if matchResult == nil {
+			// The match is inconsistent and is left for the consistency checker to report.
+			continue
+		}
 		if !match.Red1IsSurrogate {
 			addMatchResultToRankings(rankings, match.Red1, matchResult, true)
 		}
@@ -92,6 +92,18 @@ func BuildRandomSchedule(teams []model.Team, scheduleBlocks []model.ScheduleBloc
 	return matches, nil
 }
 
+// AssignFields Alternates the given matches between the given number of fields in schedule order. Leaves the matches
+// unassigned, and hence playable on any field, if there is only one.
+func AssignFields(matches []model.Match, numFields int) {
+	for i := range matches {
+		if numFields > 1 {
+			matches[i].FieldId = i%numFields + 1
+		} else {
+			matches[i].FieldId = 0
+		}
+	}
+}
+
 // Returns the total number of matches that can be run within the given schedule blocks.
 func countMatches(scheduleBlocks []model.ScheduleBlock) int {
 	numMatches := 0
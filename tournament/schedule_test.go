@@ -95,6 +95,19 @@ func TestScheduleTiming(t *testing.T) {
 	assert.Equal(t, time.Unix(100406, 0).UTC(), matches[29].Time)
 }
 
+func TestScheduleAssignFields(t *testing.T) {
+	matches := make([]model.Match, 5)
+	AssignFields(matches, 2)
+	for i, fieldId := range []int{1, 2, 1, 2, 1} {
+		assert.Equal(t, fieldId, matches[i].FieldId)
+	}
+
+	AssignFields(matches, 1)
+	for _, match := range matches {
+		assert.Equal(t, 0, match.FieldId)
+	}
+}
+
 func TestScheduleSurrogates(t *testing.T) {
 	numTeams := 38
 	teams := make([]model.Team, numTeams)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/BotDogs4645/da/game"
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 )
 
 type RankedTeam struct {
@@ -26,7 +27,7 @@ var cachedRankedTeams []*RankedTeam
 
 // Shows the alliance selection page.
 func (web *Web) allianceSelectionGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -35,7 +36,7 @@ func (web *Web) allianceSelectionGetHandler(w http.ResponseWriter, r *http.Reque
 
 // Updates the cache with the latest input from the client.
 func (web *Web) allianceSelectionPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -92,7 +93,7 @@ func (web *Web) allianceSelectionPostHandler(w http.ResponseWriter, r *http.Requ
 
 // Sets up the empty alliances and populates the ranked team list.
 func (web *Web) allianceSelectionStartHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -133,7 +134,7 @@ func (web *Web) allianceSelectionStartHandler(w http.ResponseWriter, r *http.Req
 
 // Resets the alliance selection process back to the starting point.
 func (web *Web) allianceSelectionResetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -166,6 +167,10 @@ func (web *Web) allianceSelectionResetHandler(w http.ResponseWriter, r *http.Req
 		handleWebErr(w, err)
 		return
 	}
+	if err = web.syncPlayoffBrackets(web.arena); err != nil {
+		handleWebErr(w, err)
+		return
+	}
 
 	web.arena.AllianceSelectionAlliances = []model.Alliance{}
 	cachedRankedTeams = []*RankedTeam{}
@@ -175,7 +180,7 @@ func (web *Web) allianceSelectionResetHandler(w http.ResponseWriter, r *http.Req
 
 // Saves the selected alliances to the database and generates the first round of elimination matches.
 func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -225,6 +230,10 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 		handleWebErr(w, err)
 		return
 	}
+	if err = web.syncPlayoffBrackets(web.arena); err != nil {
+		handleWebErr(w, err)
+		return
+	}
 
 	// Back up the database.
 	err = web.arena.Database.Backup(web.arena.EventSettings.Name, "post_alliance_selection")
@@ -233,18 +242,16 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if web.arena.EventSettings.TbaPublishingEnabled {
-		// Publish alliances and schedule to The Blue Alliance.
-		err = web.arena.TbaClient.PublishAlliances(web.arena.Database)
-		if err != nil {
-			web.renderAllianceSelection(w, fmt.Sprintf("Failed to publish alliances: %s", err.Error()))
-			return
-		}
-		err = web.arena.TbaClient.PublishMatches(web.arena.Database)
-		if err != nil {
-			web.renderAllianceSelection(w, fmt.Sprintf("Failed to publish matches: %s", err.Error()))
-			return
-		}
+	// Publish alliances and schedule to all the enabled results publishers.
+	err = web.arena.Publish(partner.AlliancesResource)
+	if err != nil {
+		web.renderAllianceSelection(w, fmt.Sprintf("Failed to publish alliances: %s", err.Error()))
+		return
+	}
+	err = web.arena.Publish(partner.MatchesResource)
+	if err != nil {
+		web.renderAllianceSelection(w, fmt.Sprintf("Failed to publish matches: %s", err.Error()))
+		return
 	}
 
 	// Signal displays of the bracket to update themselves.
@@ -261,11 +268,11 @@ func (web *Web) allianceSelectionFinalizeHandler(w http.ResponseWriter, r *http.
 
 // Publishes the alliances to the web.
 func (web *Web) allianceSelectionPublishHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
-	err := web.arena.TbaClient.PublishAlliances(web.arena.Database)
+	err := web.arena.Publish(partner.AlliancesResource)
 	if err != nil {
 		http.Error(w, "Failed to publish alliances: "+err.Error(), 500)
 		return
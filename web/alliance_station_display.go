@@ -14,10 +14,12 @@ import (
 
 // Renders the team number and status display shown above each alliance station.
 func (web *Web) allianceStationDisplayHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.enforceDisplayConfiguration(w, r, map[string]string{"station": "R1"}) {
+	if !web.enforceDisplayConfiguration(w, r, web.withFieldDefault(map[string]string{"station": "R1"})) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	template, err := web.parseFiles("templates/alliance_station_display.html")
 	if err != nil {
 		handleWebErr(w, err)
@@ -26,7 +28,7 @@ func (web *Web) allianceStationDisplayHandler(w http.ResponseWriter, r *http.Req
 
 	data := struct {
 		*model.EventSettings
-	}{web.arena.EventSettings}
+	}{arena.EventSettings}
 	err = template.ExecuteTemplate(w, "alliance_station_display.html", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -36,6 +38,8 @@ func (web *Web) allianceStationDisplayHandler(w http.ResponseWriter, r *http.Req
 
 // The websocket endpoint for the alliance station display client to receive status updates.
 func (web *Web) allianceStationDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	arena := web.arenaForRequest(r)
+
 	display, err := web.registerDisplay(r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -51,7 +55,7 @@ func (web *Web) allianceStationDisplayWebsocketHandler(w http.ResponseWriter, r
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(display.Notifier, web.arena.MatchTimingNotifier, web.arena.AllianceStationDisplayModeNotifier,
-		web.arena.ArenaStatusNotifier, web.arena.MatchLoadNotifier, web.arena.MatchTimeNotifier,
-		web.arena.RealtimeScoreNotifier, web.arena.ReloadDisplaysNotifier)
+	ws.HandleNotifiers(display.Notifier, arena.MatchTimingNotifier, arena.AllianceStationDisplayModeNotifier,
+		arena.ArenaStatusNotifier, arena.MatchLoadNotifier, arena.MatchTimeNotifier,
+		arena.RealtimeScoreNotifier, arena.ReloadDisplaysNotifier)
 }
@@ -14,10 +14,12 @@ import (
 
 // Renders the announcer display which shows team info and scores for the current match.
 func (web *Web) announcerDisplayHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.enforceDisplayConfiguration(w, r, nil) {
+	if !web.enforceDisplayConfiguration(w, r, web.withFieldDefault(nil)) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	template, err := web.parseFiles("templates/announcer_display.html", "templates/base.html")
 	if err != nil {
 		handleWebErr(w, err)
@@ -26,7 +28,7 @@ func (web *Web) announcerDisplayHandler(w http.ResponseWriter, r *http.Request)
 
 	data := struct {
 		*model.EventSettings
-	}{web.arena.EventSettings}
+	}{arena.EventSettings}
 	err = template.ExecuteTemplate(w, "base_no_navbar", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -36,6 +38,8 @@ func (web *Web) announcerDisplayHandler(w http.ResponseWriter, r *http.Request)
 
 // The websocket endpoint for the announcer display client to send control commands and receive status updates.
 func (web *Web) announcerDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	arena := web.arenaForRequest(r)
+
 	display, err := web.registerDisplay(r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -51,7 +55,7 @@ func (web *Web) announcerDisplayWebsocketHandler(w http.ResponseWriter, r *http.
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(display.Notifier, web.arena.MatchTimingNotifier, web.arena.MatchLoadNotifier,
-		web.arena.MatchTimeNotifier, web.arena.RealtimeScoreNotifier, web.arena.ScorePostedNotifier,
-		web.arena.AudienceDisplayModeNotifier, web.arena.ReloadDisplaysNotifier)
+	ws.HandleNotifiers(display.Notifier, arena.MatchTimingNotifier, arena.MatchLoadNotifier,
+		arena.MatchTimeNotifier, arena.RealtimeScoreNotifier, arena.ScorePostedNotifier,
+		arena.AudienceDisplayModeNotifier, arena.ReloadDisplaysNotifier)
 }
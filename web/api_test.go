@@ -157,3 +157,21 @@ func TestBracketSvgApiDoubleElimination(t *testing.T) {
 	assert.Equal(t, "image/svg+xml", recorder.Header()["Content-Type"][0])
 	assert.Contains(t, recorder.Body.String(), "Best-of-3")
 }
+
+func TestBracketSvgApiBackupTeam(t *testing.T) {
+	web := setupTestWeb(t)
+	tournament.CreateTestAlliances(web.arena.Database, 2)
+	alliance, _ := web.arena.Database.GetAllianceById(1)
+	alliance.TeamIds = append(alliance.TeamIds, 999)
+	alliance.Backup = &model.AllianceBackup{TeamId: 999, ReplacedTeamId: 101}
+	web.arena.Database.UpdateAlliance(alliance)
+	web.arena.EventSettings.NumElimAlliances = 2
+	web.arena.CreatePlayoffBracket()
+
+	// Check that the backup is shown even though it follows all four of the alliance's original teams.
+	recorder := web.getHttpResponse("/api/bracket/svg?activeMatch=current")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Regexp(t, `class="teamnum [rb] backup">999<`, recorder.Body.String())
+	assert.NotContains(t, recorder.Body.String(), ">104<")
+	assert.Contains(t, recorder.Body.String(), ">204<")
+}
@@ -15,8 +15,10 @@ import (
 
 // Renders the audience display to be chroma keyed over the video feed.
 func (web *Web) audienceDisplayHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.enforceDisplayConfiguration(w, r, map[string]string{"background": "#0f0", "reversed": "false",
-		"overlayLocation": "bottom"}) {
+	arena := web.arenaForRequest(r)
+
+	if !web.enforceDisplayConfiguration(w, r, web.withFieldDefault(map[string]string{"background": "#0f0",
+		"reversed": "false", "overlayLocation": "bottom"})) {
 		return
 	}
 
@@ -29,7 +31,7 @@ func (web *Web) audienceDisplayHandler(w http.ResponseWriter, r *http.Request) {
 	data := struct {
 		*model.EventSettings
 		MatchSounds []*game.MatchSound
-	}{web.arena.EventSettings, game.MatchSounds}
+	}{arena.EventSettings, game.MatchSounds}
 	err = template.ExecuteTemplate(w, "audience_display.html", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -39,6 +41,8 @@ func (web *Web) audienceDisplayHandler(w http.ResponseWriter, r *http.Request) {
 
 // The websocket endpoint for the audience display client to receive status updates.
 func (web *Web) audienceDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	arena := web.arenaForRequest(r)
+
 	display, err := web.registerDisplay(r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -54,8 +58,8 @@ func (web *Web) audienceDisplayWebsocketHandler(w http.ResponseWriter, r *http.R
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(display.Notifier, web.arena.MatchTimingNotifier, web.arena.AudienceDisplayModeNotifier,
-		web.arena.MatchLoadNotifier, web.arena.MatchTimeNotifier, web.arena.RealtimeScoreNotifier,
-		web.arena.PlaySoundNotifier, web.arena.ScorePostedNotifier, web.arena.AllianceSelectionNotifier,
-		web.arena.LowerThirdNotifier, web.arena.ReloadDisplaysNotifier)
+	ws.HandleNotifiers(display.Notifier, arena.MatchTimingNotifier, arena.AudienceDisplayModeNotifier,
+		arena.MatchLoadNotifier, arena.MatchTimeNotifier, arena.RealtimeScoreNotifier,
+		arena.PlaySoundNotifier, arena.ScorePostedNotifier, arena.AllianceSelectionNotifier,
+		arena.LowerThirdNotifier, arena.ReloadDisplaysNotifier)
 }
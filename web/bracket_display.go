@@ -14,10 +14,12 @@ import (
 
 // Renders the display which shows the playoff bracket.
 func (web *Web) bracketDisplayHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.enforceDisplayConfiguration(w, r, nil) {
+	if !web.enforceDisplayConfiguration(w, r, web.withFieldDefault(nil)) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	template, err := web.parseFiles("templates/bracket_display.html")
 	if err != nil {
 		handleWebErr(w, err)
@@ -25,7 +27,7 @@ func (web *Web) bracketDisplayHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	data := struct {
 		*model.EventSettings
-	}{web.arena.EventSettings}
+	}{arena.EventSettings}
 	err = template.ExecuteTemplate(w, "bracket_display.html", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -35,6 +37,8 @@ func (web *Web) bracketDisplayHandler(w http.ResponseWriter, r *http.Request) {
 
 // The websocket endpoint for the bracket display.
 func (web *Web) bracketDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	arena := web.arenaForRequest(r)
+
 	display, err := web.registerDisplay(r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -50,5 +54,7 @@ func (web *Web) bracketDisplayWebsocketHandler(w http.ResponseWriter, r *http.Re
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(display.Notifier, web.arena.MatchLoadNotifier, web.arena.ReloadDisplaysNotifier)
+	ws.HandleNotifiers(
+		display.Notifier, arena.MatchLoadNotifier, arena.ScorePostedNotifier, arena.ReloadDisplaysNotifier,
+	)
 }
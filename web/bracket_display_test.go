@@ -32,4 +32,5 @@ func TestBracketDisplayWebsocket(t *testing.T) {
 	// Should get a few status updates right after connection.
 	readWebsocketType(t, ws, "displayConfiguration")
 	readWebsocketType(t, ws, "matchLoad")
+	readWebsocketType(t, ws, "scorePosted")
 }
@@ -17,11 +17,14 @@ import (
 
 // Renders the field monitor display.
 func (web *Web) fieldMonitorDisplayHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Query().Get("fta") == "true" && !web.userIsAdmin(w, r) {
+	if r.URL.Query().Get("fta") == "true" && !web.userHasRole(w, r, model.RoleFta) {
 		return
 	}
 
-	if !web.enforceDisplayConfiguration(w, r, map[string]string{"reversed": "false", "fta": "false"}) {
+	arena := web.arenaForRequest(r)
+
+	defaults := map[string]string{"reversed": "false", "fta": "false"}
+	if !web.enforceDisplayConfiguration(w, r, web.withFieldDefault(defaults)) {
 		return
 	}
 
@@ -32,7 +35,7 @@ func (web *Web) fieldMonitorDisplayHandler(w http.ResponseWriter, r *http.Reques
 	}
 	data := struct {
 		*model.EventSettings
-	}{web.arena.EventSettings}
+	}{arena.EventSettings}
 	err = template.ExecuteTemplate(w, "field_monitor_display.html", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -42,8 +45,10 @@ func (web *Web) fieldMonitorDisplayHandler(w http.ResponseWriter, r *http.Reques
 
 // The websocket endpoint for the field monitor display client to receive status updates.
 func (web *Web) fieldMonitorDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	arena := web.arenaForRequest(r)
+
 	isFta := r.URL.Query().Get("fta") == "true"
-	if isFta && !web.userIsAdmin(w, r) {
+	if isFta && !web.userHasRole(w, r, model.RoleFta) {
 		return
 	}
 
@@ -62,8 +67,8 @@ func (web *Web) fieldMonitorDisplayWebsocketHandler(w http.ResponseWriter, r *ht
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client, in a separate goroutine.
-	go ws.HandleNotifiers(display.Notifier, web.arena.ArenaStatusNotifier, web.arena.EventStatusNotifier,
-		web.arena.ReloadDisplaysNotifier)
+	go ws.HandleNotifiers(display.Notifier, arena.ArenaStatusNotifier, arena.EventStatusNotifier,
+		arena.ReloadDisplaysNotifier)
 
 	// Loop, waiting for commands and responding to them, until the client closes the connection.
 	for {
@@ -79,6 +84,9 @@ func (web *Web) fieldMonitorDisplayWebsocketHandler(w http.ResponseWriter, r *ht
 
 		if command == "updateTeamNotes" {
 			if isFta {
+				if !web.websocketUserHasRole(ws, r, model.RoleFta) {
+					return
+				}
 				args := struct {
 					Station string
 					Notes   string
@@ -89,13 +97,13 @@ func (web *Web) fieldMonitorDisplayWebsocketHandler(w http.ResponseWriter, r *ht
 					continue
 				}
 
-				if allianceStation, ok := web.arena.AllianceStations[args.Station]; ok {
+				if allianceStation, ok := arena.AllianceStations[args.Station]; ok {
 					if allianceStation.Team != nil {
 						allianceStation.Team.FtaNotes = args.Notes
-						if err := web.arena.Database.UpdateTeam(allianceStation.Team); err != nil {
+						if err := arena.Database.UpdateTeam(allianceStation.Team); err != nil {
 							ws.WriteError(err.Error())
 						}
-						web.arena.ArenaStatusNotifier.Notify()
+						arena.ArenaStatusNotifier.Notify()
 					} else {
 						ws.WriteError("No team present")
 					}
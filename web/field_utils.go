@@ -0,0 +1,84 @@
+// Common utility methods for web routes that operate on a particular field when more than one is being run.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/BotDogs4645/da/field"
+	"github.com/BotDogs4645/da/model"
+)
+
+// Returns the arena for the field given by the "field" query parameter, or that of the first field if the parameter is
+// absent or invalid.
+func (web *Web) arenaForRequest(r *http.Request) *field.Arena {
+	fieldId, _ := strconv.Atoi(r.URL.Query().Get("field"))
+	if arena := web.arena.FieldArena(fieldId); arena != nil {
+		return arena
+	}
+	return web.arena
+}
+
+// Returns the arena that is playing or is due to play the given match, falling back to that of the first field.
+func (web *Web) arenaForMatch(match *model.Match) *field.Arena {
+	for _, arena := range web.arena.FieldArenas() {
+		if arena.CurrentMatch != nil && arena.CurrentMatch.Id == match.Id {
+			return arena
+		}
+	}
+	if arena := web.arena.FieldArena(match.FieldId); arena != nil {
+		return arena
+	}
+	return web.arena
+}
+
+// Returns the query string that selects the given arena's field in URLs, or an empty string for the first field so that
+// single-field URLs are unchanged.
+func fieldQuery(arena *field.Arena) string {
+	if arena.FieldId == 1 {
+		return ""
+	}
+	return fmt.Sprintf("?field=%d", arena.FieldId)
+}
+
+// Returns the given display defaults plus the field that the display follows, if there is more than one field.
+func (web *Web) withFieldDefault(defaults map[string]string) map[string]string {
+	if len(web.arena.FieldArenas()) < 2 {
+		return defaults
+	}
+	fieldDefaults := map[string]string{"field": "1"}
+	for key, value := range defaults {
+		fieldDefaults[key] = value
+	}
+	return fieldDefaults
+}
+
+// Reloads the settings of every field's arena, e.g. after the event settings or the database itself have changed.
+func (web *Web) loadArenaSettings() error {
+	for _, arena := range web.arena.FieldArenas() {
+		arena.Database = web.arena.Database
+		if err := arena.LoadSettings(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuilds the in-memory playoff bracket of every field other than the given one from the database, so that all the
+// fields agree after the given one has changed it. Rebuilds those of all the fields if the given arena is nil.
+func (web *Web) syncPlayoffBrackets(arena *field.Arena) error {
+	for _, fieldArena := range web.arena.FieldArenas() {
+		if fieldArena == arena {
+			continue
+		}
+		if err := fieldArena.CreatePlayoffBracket(); err != nil {
+			return err
+		}
+		if err := fieldArena.UpdatePlayoffBracket(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
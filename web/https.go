@@ -0,0 +1,233 @@
+// Serving of the web interface over HTTPS using certificates issued by a locally generated certificate authority.
+
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+)
+
+const (
+	caCertFile        = "ca.crt"
+	caKeyFile         = "ca.key"
+	serverCertFile    = "server.crt"
+	serverKeyFile     = "server.key"
+	caValidity        = 10 * 365 * 24 * time.Hour
+	serverValidity    = 825 * 24 * time.Hour // The longest that Apple devices accept.
+	certRenewalPeriod = 30 * 24 * time.Hour
+)
+
+var certificatesDir = "certificates" // Mutable for testing
+
+// ServeSecureWebInterface Starts a webserver that serves the same pages as ServeWebInterface over HTTPS, and blocks.
+// Does nothing if HTTPS is disabled in the event settings.
+func (web *Web) ServeSecureWebInterface(port int) {
+	if !web.arena.EventSettings.HttpsEnabled {
+		return
+	}
+
+	hosts, err := localHosts()
+	if err != nil {
+		log.Printf("Failed to determine the local addresses to serve HTTPS on: %v", err)
+		return
+	}
+	certificate, err := loadOrCreateServerCertificate(certificatesPath(), hosts, time.Now())
+	if err != nil {
+		log.Printf("Failed to set up the HTTPS certificate: %v", err)
+		return
+	}
+	server := http.Server{
+		Addr:      fmt.Sprintf(":%d", port),
+		Handler:   web.newServeMux(),
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{certificate}},
+	}
+	log.Printf("Serving HTTPS requests on port %d for %v", port, hosts)
+
+	if err = server.ListenAndServeTLS("", ""); err != nil {
+		log.Printf("Failed to serve HTTPS requests: %v", err)
+	}
+}
+
+// Sends the certificate of the local certificate authority to the client, so that it can be installed on devices that
+// need to trust the HTTPS interface.
+func (web *Web) caCertificateHandler(w http.ResponseWriter, r *http.Request) {
+	caCert, _, err := loadOrCreateCa(certificatesPath(), time.Now())
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+	w.Header().Set("Content-Disposition", "attachment; filename=cheesy_arena_ca.crt")
+	if err = pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+func certificatesPath() string {
+	return filepath.Join(model.BaseDir, certificatesDir)
+}
+
+// Returns the hostnames and IP addresses that clients might use to reach this server.
+func localHosts() ([]string, error) {
+	hosts := []string{"localhost"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		hosts = append(hosts, hostname)
+	}
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if ipNet, ok := address.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			hosts = append(hosts, ipNet.IP.String())
+		}
+	}
+	return hosts, nil
+}
+
+// Returns the certificate and key of the certificate authority stored in the given directory, first generating them
+// if they don't exist or the certificate has expired.
+func loadOrCreateCa(dir string, now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath := filepath.Join(dir, caCertFile)
+	keyPath := filepath.Join(dir, caKeyFile)
+	if caKeyPair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		caCert, err := x509.ParseCertificate(caKeyPair.Certificate[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		caKey, ok := caKeyPair.PrivateKey.(*ecdsa.PrivateKey)
+		if ok && now.Before(caCert.NotAfter) {
+			return caCert, caKey, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to load certificate authority: %v", err)
+	}
+
+	template := x509.Certificate{
+		Subject:               pkix.Name{Organization: []string{"Cheesy Arena"}, CommonName: "Cheesy Arena Local CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caKey, caCertDer, err := createCertificate(&template, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = writeKeyPair(certPath, keyPath, caCertDer, caKey); err != nil {
+		return nil, nil, err
+	}
+	caCert, err := x509.ParseCertificate(caCertDer)
+	return caCert, caKey, err
+}
+
+// Returns the server certificate stored in the given directory, first issuing a new one from the certificate authority
+// if it doesn't exist, is about to expire, doesn't cover all the given hosts or was issued by a different authority.
+func loadOrCreateServerCertificate(dir string, hosts []string, now time.Time) (tls.Certificate, error) {
+	caCert, caKey, err := loadOrCreateCa(dir, now)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certPath := filepath.Join(dir, serverCertFile)
+	keyPath := filepath.Join(dir, serverKeyFile)
+	if keyPair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		if cert, err := x509.ParseCertificate(keyPair.Certificate[0]); err == nil &&
+			serverCertificateIsValid(cert, caCert, hosts, now) {
+			return keyPair, nil
+		}
+	}
+
+	template := x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{"Cheesy Arena"}, CommonName: hosts[0]},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(serverValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	key, certDer, err := createCertificate(&template, caCert, caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err = writeKeyPair(certPath, keyPath, certDer, key); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.LoadX509KeyPair(certPath, keyPath)
+}
+
+// Returns true if the given server certificate was issued by the given authority, covers all the given hosts and isn't
+// due for renewal.
+func serverCertificateIsValid(cert, caCert *x509.Certificate, hosts []string, now time.Time) bool {
+	if cert.CheckSignatureFrom(caCert) != nil || now.Add(certRenewalPeriod).After(cert.NotAfter) {
+		return false
+	}
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Generates a new key and a certificate for it from the given template, signed by the given parent or self-signed if
+// there is none. Returns the key and the DER-encoded certificate.
+func createCertificate(
+	template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+) (*ecdsa.PrivateKey, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	return key, certDer, err
+}
+
+// Writes the given DER-encoded certificate and private key to the given paths in PEM format, keeping the key private.
+func writeKeyPair(certPath, keyPath string, certDer []byte, key *ecdsa.PrivateKey) error {
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(certPath), 0700); err != nil {
+		return err
+	}
+	err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0644)
+}
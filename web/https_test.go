@@ -0,0 +1,100 @@
+package web
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOrCreateServerCertificate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	certificate, err := loadOrCreateServerCertificate(dir, []string{"localhost", "arena", "10.0.100.5"}, now)
+	assert.Nil(t, err)
+	caCert, _, err := loadOrCreateCa(dir, now)
+	assert.Nil(t, err)
+	assert.True(t, caCert.IsCA)
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	serverCert, _ := x509.ParseCertificate(certificate.Certificate[0])
+	for _, host := range []string{"localhost", "arena", "10.0.100.5"} {
+		_, err = serverCert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.Nil(t, err, host)
+	}
+	keyInfo, _ := os.Stat(filepath.Join(dir, serverKeyFile))
+	assert.Equal(t, os.FileMode(0600), keyInfo.Mode().Perm())
+
+	// Check that the stored certificates are reused while they cover the hosts and aren't close to expiry.
+	certificate2, err := loadOrCreateServerCertificate(dir, []string{"localhost", "10.0.100.5"}, now)
+	assert.Nil(t, err)
+	assert.Equal(t, certificate.Certificate, certificate2.Certificate)
+	caCert2, _, _ := loadOrCreateCa(dir, now)
+	assert.Equal(t, caCert.Raw, caCert2.Raw)
+
+	// Check that a new server certificate is issued by the same authority when the hosts change.
+	certificate2, err = loadOrCreateServerCertificate(dir, []string{"localhost", "10.0.100.6"}, now)
+	assert.Nil(t, err)
+	assert.NotEqual(t, certificate.Certificate, certificate2.Certificate)
+	serverCert, _ = x509.ParseCertificate(certificate2.Certificate[0])
+	_, err = serverCert.Verify(x509.VerifyOptions{DNSName: "10.0.100.6", Roots: roots})
+	assert.Nil(t, err)
+
+	// Check that the server certificate is renewed before it expires.
+	later := now.Add(serverValidity - certRenewalPeriod/2)
+	certificate3, err := loadOrCreateServerCertificate(dir, []string{"localhost", "10.0.100.6"}, later)
+	assert.Nil(t, err)
+	assert.NotEqual(t, certificate2.Certificate, certificate3.Certificate)
+	caCert2, _, _ = loadOrCreateCa(dir, later)
+	assert.Equal(t, caCert.Raw, caCert2.Raw)
+}
+
+func TestCaCertificateDownload(t *testing.T) {
+	web := setupTestWeb(t)
+	certificatesDir = "test_certificates"
+	defer func() {
+		os.RemoveAll(certificatesPath())
+		certificatesDir = "certificates"
+	}()
+
+	recorder := web.getHttpResponse("/https/ca.crt")
+	assert.Equal(t, 200, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "application/x-x509-ca-cert", recorder.Header()["Content-Type"][0])
+	block, _ := pem.Decode(recorder.Body.Bytes())
+	if assert.NotNil(t, block) {
+		caCert, err := x509.ParseCertificate(block.Bytes)
+		assert.Nil(t, err)
+		assert.True(t, caCert.IsCA)
+	}
+	storedCert, _ := os.ReadFile(filepath.Join(certificatesPath(), caCertFile))
+	assert.Equal(t, storedCert, recorder.Body.Bytes())
+}
+
+func TestSecureSessionCookie(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.AdminPassword = "admin"
+	login := func(isTls bool) string {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/login", strings.NewReader("username=admin&password=admin"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if isTls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		web.newHandler().ServeHTTP(recorder, req)
+		return recorder.Header().Get("Set-Cookie")
+	}
+
+	assert.NotContains(t, login(false), "Secure")
+	assert.Contains(t, login(true), "Secure")
+	sessions, _ := web.arena.Database.GetAllUserSessions()
+	assert.Equal(t, 2, len(sessions))
+}
@@ -0,0 +1,145 @@
+// Web routes for the team check-in and robot inspection workflow.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/gorilla/mux"
+)
+
+// Shows the check-in and inspection status of every team.
+func (web *Web) inspectionGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// Tally up the teams in each state for the summary at the top of the page.
+	numCheckedIn := 0
+	numWeighed := 0
+	statusCounts := make(map[string]int)
+	for _, team := range teams {
+		if team.CheckedIn {
+			numCheckedIn++
+		}
+		if team.RobotWeightLb > 0 {
+			numWeighed++
+		}
+		statusCounts[team.Inspection]++
+	}
+
+	template, err := web.parseFiles("templates/inspection.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Teams        []model.Team
+		NumCheckedIn int
+		NumWeighed   int
+		StatusCounts map[string]int
+	}{web.arena.EventSettings, teams, numCheckedIn, numWeighed, statusCounts}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Shows the page to record a single team's check-in and inspection.
+func (web *Web) inspectionTeamGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	team, ok := web.getInspectionTeam(w, r)
+	if !ok {
+		return
+	}
+	web.renderInspectionTeam(w, team, "")
+}
+
+// Saves a single team's check-in and inspection status.
+func (web *Web) inspectionTeamPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	team, ok := web.getInspectionTeam(w, r)
+	if !ok {
+		return
+	}
+
+	team.CheckedIn = r.PostFormValue("checkedIn") == "on"
+	team.RobotWeightLb, _ = strconv.ParseFloat(r.PostFormValue("robotWeightLb"), 64)
+	team.Inspection = r.PostFormValue("inspection")
+	team.InspectionNotes = r.PostFormValue("inspectionNotes")
+	if _, ok := model.InspectionStatusNames[team.Inspection]; !ok {
+		web.renderInspectionTeam(w, team, fmt.Sprintf("Invalid inspection status '%s'.", team.Inspection))
+		return
+	}
+	if (team.Inspection == model.InspectionFailed || team.Inspection == model.InspectionReinspect) &&
+		team.InspectionNotes == "" {
+		web.renderInspectionTeam(w, team, "A reason must be given when a robot fails or needs to be re-inspected.")
+		return
+	}
+
+	if err := web.arena.Database.UpdateTeam(team); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	web.arena.RefreshTeam(team)
+	http.Redirect(w, r, "/inspection", 303)
+}
+
+// Loads the team given in the request URL, writing an error response and returning false if it doesn't exist.
+func (web *Web) getInspectionTeam(w http.ResponseWriter, r *http.Request) (*model.Team, bool) {
+	vars := mux.Vars(r)
+	teamId, _ := strconv.Atoi(vars["id"])
+	team, err := web.arena.Database.GetTeamById(teamId)
+	if err != nil {
+		handleWebErr(w, err)
+		return nil, false
+	}
+	if team == nil {
+		http.Error(w, fmt.Sprintf("Error: No such team: %d", teamId), 400)
+		return nil, false
+	}
+	return team, true
+}
+
+func (web *Web) renderInspectionTeam(w http.ResponseWriter, team *model.Team, errorMessage string) {
+	template, err := web.parseFiles("templates/inspection_team.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Team                  *model.Team
+		InspectionStatusNames map[string]string
+		InspectionStatuses    []string
+		ErrorMessage          string
+	}{
+		web.arena.EventSettings,
+		team,
+		model.InspectionStatusNames,
+		[]string{model.InspectionPending, model.InspectionPassed, model.InspectionFailed, model.InspectionReinspect},
+		errorMessage,
+	}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
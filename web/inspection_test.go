@@ -0,0 +1,59 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspection(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, Nickname: "The Cheesy Poofs"})
+	web.arena.Database.CreateTeam(&model.Team{Id: 1114, Nickname: "Simbotics", CheckedIn: true,
+		Inspection: model.InspectionPassed})
+
+	recorder := web.getHttpResponse("/inspection")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "The Cheesy Poofs")
+	assert.Contains(t, recorder.Body.String(), "Not Inspected")
+	assert.Contains(t, recorder.Body.String(), "<b>1</b> of <b>2</b> teams checked in")
+
+	recorder = web.getHttpResponse("/inspection/254")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Team 254")
+
+	// Check that a failure must be accompanied by a reason.
+	recorder = web.postHttpResponse("/inspection/254", "checkedIn=on&robotWeightLb=121.5&inspection=failed")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "A reason must be given")
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.False(t, team.CheckedIn)
+
+	recorder = web.postHttpResponse("/inspection/254",
+		"checkedIn=on&robotWeightLb=121.5&inspection=failed&inspectionNotes=Overweight")
+	assert.Equal(t, 303, recorder.Code)
+	team, _ = web.arena.Database.GetTeamById(254)
+	assert.True(t, team.CheckedIn)
+	assert.Equal(t, 121.5, team.RobotWeightLb)
+	assert.Equal(t, model.InspectionFailed, team.Inspection)
+	assert.Equal(t, "Overweight", team.InspectionNotes)
+
+	recorder = web.postHttpResponse("/inspection/254", "checkedIn=on&robotWeightLb=119&inspection=passed")
+	assert.Equal(t, 303, recorder.Code)
+	team, _ = web.arena.Database.GetTeamById(254)
+	assert.True(t, team.PassedInspection())
+}
+
+func TestInspectionErrors(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+
+	recorder := web.getHttpResponse("/inspection/1114")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "No such team")
+
+	recorder = web.postHttpResponse("/inspection/254", "inspection=bogus")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Invalid inspection status")
+}
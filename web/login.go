@@ -7,34 +7,48 @@ package web
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/websocket"
 	"github.com/google/uuid"
 )
 
+// How often the last activity time of a session is updated as it is used.
+const sessionActivityInterval = time.Minute
+
 // Shows the login form.
 func (web *Web) loginHandler(w http.ResponseWriter, r *http.Request) {
-	web.renderLogin(w, "")
+	web.renderLogin(w, r, "")
 }
 
 // Processes the login request.
 func (web *Web) loginPostHandler(w http.ResponseWriter, r *http.Request) {
 	username := r.PostFormValue("username")
 	if err := web.checkAuthPassword(username, r.PostFormValue("password")); err != nil {
-		web.renderLogin(w, err.Error())
+		web.renderLogin(w, r, err.Error())
 		return
 	}
 
-	session := model.UserSession{Token: uuid.New().String(), Username: username, CreatedAt: time.Now()}
+	now := time.Now()
+	session := model.UserSession{
+		Token:         uuid.New().String(),
+		Username:      username,
+		CreatedAt:     now,
+		LastActiveAt:  now,
+		RemoteAddress: remoteAddress(r),
+		UserAgent:     r.UserAgent(),
+	}
 	if err := web.arena.Database.CreateUserSession(&session); err != nil {
 		handleWebErr(w, err)
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{Name: sessionTokenCookie, Value: session.Token})
+	// Only send the cookie back over HTTPS if that is how it was set, so that it can't be intercepted.
+	http.SetCookie(w, &http.Cookie{Name: sessionTokenCookie, Value: session.Token, Secure: r.TLS != nil})
 	redirectUrl := r.URL.Query().Get("redirect")
 	if redirectUrl == "" {
 		redirectUrl = "/"
@@ -42,7 +56,26 @@ func (web *Web) loginPostHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirectUrl, 303)
 }
 
-func (web *Web) renderLogin(w http.ResponseWriter, errorMessage string) {
+// Ends the current session, or all of the current user's sessions if requested.
+func (web *Web) logoutPostHandler(w http.ResponseWriter, r *http.Request) {
+	if session := web.getUserSessionFromCookie(r); session != nil {
+		var err error
+		if r.PostFormValue("everywhere") == "true" {
+			err = web.arena.Database.DeleteUserSessionsByUsername(session.Username)
+		} else {
+			err = web.arena.Database.DeleteUserSession(session.Id)
+		}
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionTokenCookie, Value: "", MaxAge: -1, Secure: r.TLS != nil})
+	http.Redirect(w, r, "/login", 303)
+}
+
+func (web *Web) renderLogin(w http.ResponseWriter, r *http.Request, errorMessage string) {
 	template, err := web.parseFiles("templates/login.html", "templates/base.html")
 	if err != nil {
 		handleWebErr(w, err)
@@ -50,8 +83,9 @@ func (web *Web) renderLogin(w http.ResponseWriter, errorMessage string) {
 	}
 	data := struct {
 		*model.EventSettings
+		Session      *model.UserSession
 		ErrorMessage string
-	}{web.arena.EventSettings, errorMessage}
+	}{web.arena.EventSettings, web.getUserSessionFromCookie(r), errorMessage}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -59,38 +93,112 @@ func (web *Web) renderLogin(w http.ResponseWriter, errorMessage string) {
 	}
 }
 
-// Returns true if the given user is authorized for admin operations. Used for HTTP cookie authentication.
-func (web *Web) userIsAdmin(w http.ResponseWriter, r *http.Request) bool {
+// Returns true if the user is logged in with one of the given roles or as an admin, who can do everything. Otherwise
+// redirects to the login page, or rejects the request if the user is logged in without the needed role. Used for HTTP
+// cookie authentication.
+func (web *Web) userHasRole(w http.ResponseWriter, r *http.Request, roles ...string) bool {
 	if web.arena.EventSettings.AdminPassword == "" {
 		// Disable auth if there is no password configured.
 		return true
 	}
+	role := web.userRole(r)
+	if roleIsAllowed(role, roles) {
+		return true
+	}
+	if role != "" {
+		http.Error(w, "Your account doesn't have access to this page.", 403)
+		return false
+	}
+	redirect := r.URL.Path
+	if r.URL.RawQuery != "" {
+		redirect += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, "/login?redirect="+url.QueryEscape(redirect), 307)
+	return false
+}
+
+// Returns true if the user who opened the given websocket with the given request still has one of the given roles,
+// writing an error to the websocket otherwise. Checked for each command, since the session may have been revoked or the
+// user deleted after the websocket was opened.
+func (web *Web) websocketUserHasRole(ws *websocket.Websocket, r *http.Request, roles ...string) bool {
+	if roleIsAllowed(web.userRole(r), roles) {
+		return true
+	}
+	ws.WriteError("Your session has ended or no longer has access to this page; please log in again.")
+	return false
+}
+
+// Returns the role of the user logged in with the request's session cookie, or the empty string if there isn't one.
+func (web *Web) userRole(r *http.Request) string {
+	if web.arena.EventSettings.AdminPassword == "" {
+		return model.RoleAdmin
+	}
 	session := web.getUserSessionFromCookie(r)
-	if session != nil && session.Username == adminUser {
+	if session == nil {
+		return ""
+	}
+	if session.Username == adminUser {
+		return model.RoleAdmin
+	}
+	user, _ := web.arena.Database.GetUserByUsername(session.Username)
+	if user == nil {
+		return ""
+	}
+	return user.Role
+}
+
+// Returns true if the given role is an admin or is one of the given roles.
+func roleIsAllowed(role string, roles []string) bool {
+	if role == model.RoleAdmin {
 		return true
-	} else {
-		redirect := r.URL.Path
-		if r.URL.RawQuery != "" {
-			redirect += "?" + r.URL.RawQuery
+	}
+	for _, allowedRole := range roles {
+		if role == allowedRole {
+			return true
 		}
-		http.Redirect(w, r, "/login?redirect="+url.QueryEscape(redirect), 307)
-		return false
 	}
+	return false
 }
 
+// Returns the session identified by the request's cookie, or nil if there isn't one or it has expired. Records the
+// session as having been used.
 func (web *Web) getUserSessionFromCookie(r *http.Request) *model.UserSession {
 	token, err := r.Cookie(sessionTokenCookie)
 	if err != nil {
 		return nil
 	}
 	session, _ := web.arena.Database.GetUserSessionByToken(token.Value)
+	if session == nil {
+		return nil
+	}
+
+	now := time.Now()
+	idleTimeout, maxAge := web.arena.EventSettings.SessionLimits()
+	if session.IsExpired(now, idleTimeout, maxAge) {
+		if err = web.arena.Database.DeleteUserSession(session.Id); err != nil {
+			log.Printf("Failed to delete expired session %d: %v", session.Id, err)
+		}
+		return nil
+	}
+	if now.Sub(session.LastActive()) > sessionActivityInterval {
+		// Only record activity periodically to avoid writing to the database on every request.
+		session.LastActiveAt = now
+		session.RemoteAddress = remoteAddress(r)
+		session.UserAgent = r.UserAgent()
+		if err = web.arena.Database.UpdateUserSession(session); err != nil {
+			log.Printf("Failed to record activity for session %d: %v", session.Id, err)
+		}
+	}
 	return session
 }
 
-func (web *Web) checkAuthPassword(user, password string) error {
-	if user == adminUser && password == web.arena.EventSettings.AdminPassword {
+func (web *Web) checkAuthPassword(username, password string) error {
+	if username == adminUser {
+		if password == web.arena.EventSettings.AdminPassword {
+			return nil
+		}
+	} else if user, _ := web.arena.Database.GetUserByUsername(username); user != nil && user.CheckPassword(password) {
 		return nil
-	} else {
-		return fmt.Errorf("invalid login credentials")
 	}
+	return fmt.Errorf("invalid login credentials")
 }
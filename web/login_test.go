@@ -4,6 +4,7 @@
 package web
 
 import (
+	"github.com/BotDogs4645/da/model"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -45,3 +46,34 @@ func TestLoginDisplay(t *testing.T) {
 	recorder = web.getHttpResponseWithHeaders("/match_play?p1=v1&p2=v2", map[string]string{"Cookie": cookie})
 	assert.Equal(t, 200, recorder.Code)
 }
+
+func TestLoginWithRole(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.AdminPassword = "admin"
+	user, _ := model.NewUser("bertha", "refpass", model.RoleHeadRef)
+	web.arena.Database.CreateUser(user)
+
+	recorder := web.postHttpResponse("/login?redirect=%2Fmatch_review", "username=bertha&password=blorpy")
+	assert.Contains(t, recorder.Body.String(), "invalid login credentials")
+	recorder = web.postHttpResponse("/login?redirect=%2Fmatch_review", "username=bertha&password=refpass")
+	assert.Equal(t, 303, recorder.Code)
+	cookie := recorder.Header().Get("Set-Cookie")
+	assert.Contains(t, cookie, "session_token=")
+	headers := map[string]string{"Cookie": cookie}
+
+	// Check that the pages for the role are allowed and the others aren't.
+	recorder = web.getHttpResponseWithHeaders("/match_play", headers)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/match_review/1/edit", headers)
+	assert.NotEqual(t, 307, recorder.Code)
+	assert.NotEqual(t, 403, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/setup/settings", headers)
+	assert.Equal(t, 403, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/scoring_panel", headers)
+	assert.Equal(t, 403, recorder.Code)
+
+	// Check that deleting the account revokes access.
+	web.arena.Database.DeleteUser(user.Id)
+	recorder = web.getHttpResponseWithHeaders("/match_play", headers)
+	assert.Equal(t, 307, recorder.Code)
+}
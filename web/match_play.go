@@ -18,6 +18,7 @@ import (
 	"github.com/BotDogs4645/da/field"
 	"github.com/BotDogs4645/da/game"
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 	"github.com/BotDogs4645/da/tournament"
 	"github.com/BotDogs4645/da/websocket"
 	"github.com/gorilla/mux"
@@ -36,21 +37,23 @@ type MatchPlayList []MatchPlayListItem
 
 // Shows the match play control interface.
 func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta, model.RoleHeadRef) {
 		return
 	}
 
-	practiceMatches, err := web.buildMatchPlayList("practice")
+	arena := web.arenaForRequest(r)
+
+	practiceMatches, err := web.buildMatchPlayList(arena, "practice")
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	qualificationMatches, err := web.buildMatchPlayList("qualification")
+	qualificationMatches, err := web.buildMatchPlayList(arena, "qualification")
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	eliminationMatches, err := web.buildMatchPlayList("elimination")
+	eliminationMatches, err := web.buildMatchPlayList(arena, "elimination")
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -63,21 +66,37 @@ func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	matchesByType := map[string]MatchPlayList{"practice": practiceMatches,
 		"qualification": qualificationMatches, "elimination": eliminationMatches}
-	currentMatchType := web.arena.CurrentMatch.Type
+	currentMatchType := arena.CurrentMatch.Type
 	if currentMatchType == "test" {
 		currentMatchType = "practice"
 	}
-	redOffFieldTeams, blueOffFieldTeams, err := web.arena.Database.GetOffFieldTeamIds(web.arena.CurrentMatch)
+	redOffFieldTeams, blueOffFieldTeams, err := arena.Database.GetOffFieldTeamIds(arena.CurrentMatch)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	matchResult, err := web.arena.Database.GetMatchResultForMatch(web.arena.CurrentMatch.Id)
+	matchResult, err := arena.Database.GetMatchResultForMatch(arena.CurrentMatch.Id)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
 	isReplay := matchResult != nil
+	var redAlliance, blueAlliance *model.Alliance
+	nextBackupTeamId := 0
+	if arena.CurrentMatch.Type == "elimination" {
+		if redAlliance, err = arena.Database.GetAllianceById(arena.CurrentMatch.ElimRedAlliance); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if blueAlliance, err = arena.Database.GetAllianceById(arena.CurrentMatch.ElimBlueAlliance); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if nextBackupTeamId, err = arena.Database.GetNextBackupTeamId(); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
 	data := struct {
 		*model.EventSettings
 		PlcIsEnabled          bool
@@ -86,6 +105,10 @@ func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
 		Match                 *model.Match
 		RedOffFieldTeams      []int
 		BlueOffFieldTeams     []int
+		RedAlliance           *model.Alliance
+		BlueAlliance          *model.Alliance
+		NextBackupTeamId      int
+		EarliestStartTime     time.Time
 		RedScore              *game.Score
 		BlueScore             *game.Score
 		AllowSubstitution     bool
@@ -93,21 +116,29 @@ func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
 		SavedMatchType        string
 		SavedMatch            *model.Match
 		PlcArmorBlockStatuses map[string]bool
+		FieldId               int
+		FieldQuery            string
 	}{
-		web.arena.EventSettings,
-		web.arena.Plc.IsEnabled(),
+		arena.EventSettings,
+		arena.Plc.IsEnabled(),
 		matchesByType,
 		currentMatchType,
-		web.arena.CurrentMatch,
+		arena.CurrentMatch,
 		redOffFieldTeams,
 		blueOffFieldTeams,
-		web.arena.RedScore,
-		web.arena.BlueScore,
-		web.arena.CurrentMatch.ShouldAllowSubstitution(),
+		redAlliance,
+		blueAlliance,
+		nextBackupTeamId,
+		arena.EarliestMatchStartTime,
+		arena.RedScore,
+		arena.BlueScore,
+		arena.CurrentMatch.ShouldAllowSubstitution(),
 		isReplay,
-		web.arena.SavedMatch.CapitalizedType(),
-		web.arena.SavedMatch,
-		web.arena.Plc.GetArmorBlockStatuses(),
+		arena.SavedMatch.CapitalizedType(),
+		arena.SavedMatch,
+		arena.Plc.GetArmorBlockStatuses(),
+		arena.FieldId,
+		fieldQuery(arena),
 	}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
@@ -118,18 +149,20 @@ func (web *Web) matchPlayHandler(w http.ResponseWriter, r *http.Request) {
 
 // Loads the given match onto the arena in preparation for playing it.
 func (web *Web) matchPlayLoadHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	vars := mux.Vars(r)
 	matchId, _ := strconv.Atoi(vars["matchId"])
 	var match *model.Match
 	var err error
 	if matchId == 0 {
-		err = web.arena.LoadTestMatch()
+		err = arena.LoadTestMatch()
 	} else {
-		match, err = web.arena.Database.GetMatchById(matchId)
+		match, err = arena.Database.GetMatchById(matchId)
 		if err != nil {
 			handleWebErr(w, err)
 			return
@@ -138,25 +171,27 @@ func (web *Web) matchPlayLoadHandler(w http.ResponseWriter, r *http.Request) {
 			handleWebErr(w, fmt.Errorf("invalid match ID %d", matchId))
 			return
 		}
-		err = web.arena.LoadMatch(match)
+		err = arena.LoadMatch(match)
 	}
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
 
-	http.Redirect(w, r, "/match_play", 303)
+	http.Redirect(w, r, "/match_play"+fieldQuery(arena), 303)
 }
 
 // Loads the results for the given match into the display buffer.
 func (web *Web) matchPlayShowResultHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta, model.RoleHeadRef) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	vars := mux.Vars(r)
 	matchId, _ := strconv.Atoi(vars["matchId"])
-	match, err := web.arena.Database.GetMatchById(matchId)
+	match, err := arena.Database.GetMatchById(matchId)
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -165,7 +200,7 @@ func (web *Web) matchPlayShowResultHandler(w http.ResponseWriter, r *http.Reques
 		handleWebErr(w, fmt.Errorf("invalid match ID %d", matchId))
 		return
 	}
-	matchResult, err := web.arena.Database.GetMatchResultForMatch(match.Id)
+	matchResult, err := arena.Database.GetMatchResultForMatch(match.Id)
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -175,41 +210,45 @@ func (web *Web) matchPlayShowResultHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	if match.ShouldUpdateRankings() {
-		web.arena.SavedRankings, err = web.arena.Database.GetAllRankings()
+		arena.SavedRankings, err = arena.Database.GetAllRankings()
 		if err != nil {
 			handleWebErr(w, err)
 			return
 		}
 	} else {
-		web.arena.SavedRankings = game.Rankings{}
+		arena.SavedRankings = game.Rankings{}
 	}
-	web.arena.SavedMatch = match
-	web.arena.SavedMatchResult = matchResult
-	web.arena.ScorePostedNotifier.Notify()
+	arena.SavedMatch = match
+	arena.SavedMatchResult = matchResult
+	arena.ScorePostedNotifier.Notify()
 
-	http.Redirect(w, r, "/match_play", 303)
+	http.Redirect(w, r, "/match_play"+fieldQuery(arena), 303)
 }
 
 // Clears the match results display buffer.
 func (web *Web) matchPlayClearResultHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta, model.RoleHeadRef) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	// Load an empty match to effectively clear the buffer.
-	web.arena.SavedMatch = &model.Match{}
-	web.arena.SavedMatchResult = model.NewMatchResult()
-	web.arena.ScorePostedNotifier.Notify()
+	arena.SavedMatch = &model.Match{}
+	arena.SavedMatchResult = model.NewMatchResult()
+	arena.ScorePostedNotifier.Notify()
 
-	http.Redirect(w, r, "/match_play", 303)
+	http.Redirect(w, r, "/match_play"+fieldQuery(arena), 303)
 }
 
 // The websocket endpoint for the match play client to send control commands and receive status updates.
 func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta, model.RoleHeadRef) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	ws, err := websocket.NewWebsocket(w, r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -218,9 +257,9 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client, in a separate goroutine.
-	go ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.ArenaStatusNotifier, web.arena.MatchTimeNotifier,
-		web.arena.RealtimeScoreNotifier, web.arena.AudienceDisplayModeNotifier,
-		web.arena.AllianceStationDisplayModeNotifier, web.arena.EventStatusNotifier)
+	go ws.HandleNotifiers(arena.MatchTimingNotifier, arena.ArenaStatusNotifier, arena.MatchTimeNotifier,
+		arena.RealtimeScoreNotifier, arena.AudienceDisplayModeNotifier,
+		arena.AllianceStationDisplayModeNotifier, arena.EventStatusNotifier)
 
 	// Loop, waiting for commands and responding to them, until the client closes the connection.
 	for {
@@ -234,6 +273,15 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 			return
 		}
 
+		if !web.websocketUserHasRole(ws, r, model.RoleFta, model.RoleHeadRef) {
+			return
+		}
+		if web.userRole(r) == model.RoleHeadRef && messageType != "commitResults" && messageType != "discardResults" {
+			// Head referees can only decide the outcome of a match, not run it.
+			ws.WriteError(fmt.Sprintf("Your account isn't allowed to send the '%s' command.", messageType))
+			continue
+		}
+
 		switch messageType {
 		case "substituteTeam":
 			args := struct {
@@ -245,22 +293,61 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.SubstituteTeam(args.Team, args.Position)
+			previousTeamId := 0
+			if allianceStation, ok := arena.AllianceStations[args.Position]; ok && allianceStation.Team != nil {
+				previousTeamId = allianceStation.Team.Id
+			}
+			err = arena.SubstituteTeam(args.Team, args.Position)
+			if err != nil {
+				ws.WriteError(err.Error())
+				continue
+			}
+			web.audit(r, auditTeamSubstitute, matchPlayAuditTarget(arena, args.Position), previousTeamId, args.Team)
+		case "invokeBackup":
+			args := struct {
+				Alliance string
+				Team     int
+			}{}
+			err = mapstructure.Decode(data, &args)
+			if err != nil {
+				ws.WriteError(err.Error())
+				continue
+			}
+			err = arena.InvokeBackupTeam(args.Alliance, args.Team)
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
+			web.audit(
+				r, auditBackupInvoke, fmt.Sprintf("%s alliance in %s", args.Alliance, matchPlayAuditTarget(arena, "")),
+				args.Team, nil,
+			)
+			web.arena.PublishAsync(partner.AlliancesResource, partner.MatchesResource)
+			arena.ScorePostedNotifier.Notify()
+			err = ws.WriteNotifier(arena.ReloadDisplaysNotifier)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			continue // Skip sending the status update, as the client is about to terminate and reload.
 		case "toggleBypass":
 			station, ok := data.(string)
 			if !ok {
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			if _, ok := web.arena.AllianceStations[station]; !ok {
+			if _, ok := arena.AllianceStations[station]; !ok {
 				ws.WriteError(fmt.Sprintf("Invalid alliance station '%s'.", station))
 				continue
 			}
-			web.arena.AllianceStations[station].Bypass = !web.arena.AllianceStations[station].Bypass
+			arena.AllianceStations[station].Bypass = !arena.AllianceStations[station].Bypass
+			web.audit(
+				r,
+				auditStationBypass,
+				matchPlayAuditTarget(arena, station),
+				!arena.AllianceStations[station].Bypass,
+				arena.AllianceStations[station].Bypass,
+			)
 		case "startMatch":
 			args := struct {
 				MuteMatchSounds bool
@@ -270,33 +357,34 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				ws.WriteError(err.Error())
 				continue
 			}
-			web.arena.MuteMatchSounds = args.MuteMatchSounds
-			err = web.arena.StartMatch()
+			arena.MuteMatchSounds = args.MuteMatchSounds
+			err = arena.StartMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
 		case "abortMatch":
-			err = web.arena.AbortMatch()
+			err = arena.AbortMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
+			web.audit(r, auditMatchAbort, matchPlayAuditTarget(arena, ""), nil, nil)
 		case "signalVolunteers":
-			if web.arena.MatchState != field.PostMatch {
+			if arena.MatchState != field.PostMatch {
 				// Don't allow clearing the field until the match is over.
 				continue
 			}
-			web.arena.FieldVolunteers = true
+			arena.FieldVolunteers = true
 			continue // Don't reload.
 		case "signalReset":
-			if web.arena.MatchState != field.PostMatch {
+			if arena.MatchState != field.PostMatch {
 				// Don't allow clearing the field until the match is over.
 				continue
 			}
-			web.arena.FieldReset = true
-			web.arena.AllianceStationDisplayMode = "fieldReset"
-			web.arena.AllianceStationDisplayModeNotifier.Notify()
+			arena.FieldReset = true
+			arena.AllianceStationDisplayMode = "fieldReset"
+			arena.AllianceStationDisplayModeNotifier.Notify()
 
 			// i am too lazy to come up with a better way for this
 			hardcoded := map[string]interface{}{
@@ -314,48 +402,48 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				return
 			}
 			web.saveLowerThird(&lowerThird)
-			web.arena.LowerThird = &lowerThird
-			web.arena.ShowLowerThird = true
-			web.arena.LowerThirdNotifier.Notify()
+			arena.LowerThird = &lowerThird
+			arena.ShowLowerThird = true
+			arena.LowerThirdNotifier.Notify()
 			continue // Don't reload.
 		case "commitResults":
-			err = web.commitCurrentMatchScore()
+			err = web.commitCurrentMatchScore(arena)
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.ResetMatch()
+			err = arena.ResetMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.LoadNextMatch()
+			err = arena.LoadNextMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			web.arena.ShowLowerThird = false
-			web.arena.LowerThirdNotifier.Notify()
-			err = ws.WriteNotifier(web.arena.ReloadDisplaysNotifier)
+			arena.ShowLowerThird = false
+			arena.LowerThirdNotifier.Notify()
+			err = ws.WriteNotifier(arena.ReloadDisplaysNotifier)
 			if err != nil {
 				log.Println(err)
 				return
 			}
 			continue // Skip sending the status update, as the client is about to terminate and reload.
 		case "discardResults":
-			err = web.arena.ResetMatch()
+			err = arena.ResetMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.LoadNextMatch()
+			err = arena.LoadNextMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			web.arena.ShowLowerThird = false
-			web.arena.LowerThirdNotifier.Notify()
-			err = ws.WriteNotifier(web.arena.ReloadDisplaysNotifier)
+			arena.ShowLowerThird = false
+			arena.LowerThirdNotifier.Notify()
+			err = ws.WriteNotifier(arena.ReloadDisplaysNotifier)
 			if err != nil {
 				log.Println(err)
 				return
@@ -367,7 +455,7 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			web.arena.SetAudienceDisplayMode(mode)
+			arena.SetAudienceDisplayMode(mode)
 			continue
 		case "setAllianceStationDisplay":
 			mode, ok := data.(string)
@@ -375,21 +463,34 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			web.arena.SetAllianceStationDisplayMode(mode)
+			arena.SetAllianceStationDisplayMode(mode)
 			continue
 		case "startTimeout":
-			durationSec, ok := data.(float64)
-			if !ok {
-				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
+			args := struct {
+				DurationSec float64
+				Alliance    string
+			}{}
+			err = mapstructure.Decode(data, &args)
+			if err != nil {
+				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.StartTimeout(int(durationSec))
+			err = arena.StartTimeout(int(args.DurationSec), args.Alliance)
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
+			if args.Alliance != "" {
+				// Reload the page to reflect the used timeout coupon.
+				err = ws.WriteNotifier(arena.ReloadDisplaysNotifier)
+				if err != nil {
+					log.Println(err)
+					return
+				}
+				continue
+			}
 		case "setTestMatchName":
-			if web.arena.CurrentMatch.Type != "test" {
+			if arena.CurrentMatch.Type != "test" {
 				// Don't allow changing the name of a non-test match.
 				continue
 			}
@@ -398,25 +499,25 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			web.arena.CurrentMatch.DisplayName = name
-			web.arena.MatchLoadNotifier.Notify()
+			arena.CurrentMatch.DisplayName = name
+			arena.MatchLoadNotifier.Notify()
 			continue
 		case "updateRealtimeScore":
 			args := data.(map[string]interface{})
-			web.arena.BlueScore.AutoPoints = int(args["blueAuto"].(float64))
-			web.arena.RedScore.AutoPoints = int(args["redAuto"].(float64))
-			web.arena.BlueScore.TeleopPoints = int(args["blueTeleop"].(float64))
-			web.arena.RedScore.TeleopPoints = int(args["redTeleop"].(float64))
-			web.arena.BlueScore.EndgamePoints = int(args["blueEndgame"].(float64))
-			web.arena.RedScore.EndgamePoints = int(args["redEndgame"].(float64))
-			web.arena.RealtimeScoreNotifier.Notify()
+			arena.BlueScore.AutoPoints = int(args["blueAuto"].(float64))
+			arena.RedScore.AutoPoints = int(args["redAuto"].(float64))
+			arena.BlueScore.TeleopPoints = int(args["blueTeleop"].(float64))
+			arena.RedScore.TeleopPoints = int(args["redTeleop"].(float64))
+			arena.BlueScore.EndgamePoints = int(args["blueEndgame"].(float64))
+			arena.RedScore.EndgamePoints = int(args["redEndgame"].(float64))
+			arena.RealtimeScoreNotifier.Notify()
 		default:
 			ws.WriteError(fmt.Sprintf("Invalid message type '%s'.", messageType))
 			continue
 		}
 
 		// Send out the status again after handling the command, as it most likely changed as a result.
-		err = ws.WriteNotifier(web.arena.ArenaStatusNotifier)
+		err = ws.WriteNotifier(arena.ArenaStatusNotifier)
 		if err != nil {
 			log.Println(err)
 			return
@@ -427,94 +528,44 @@ func (web *Web) matchPlayWebsocketHandler(w http.ResponseWriter, r *http.Request
 // Saves the given match and result to the database, supplanting any previous result for the match.
 func (web *Web) commitMatchScore(match *model.Match, matchResult *model.MatchResult, isMatchReviewEdit bool) error {
 	var updatedRankings game.Rankings
+	arena := web.arenaForMatch(match)
 
 	if match.Type != "test" {
-		if matchResult.PlayNumber == 0 {
-			// Determine the play number for this new match result.
-			prevMatchResult, err := web.arena.Database.GetMatchResultForMatch(match.Id)
-			if err != nil {
-				return err
-			}
-			if prevMatchResult != nil {
-				matchResult.PlayNumber = prevMatchResult.PlayNumber + 1
-			} else {
-				matchResult.PlayNumber = 1
-			}
-
-			// Save the match result record to the database.
-			err = web.arena.Database.CreateMatchResult(matchResult)
-			if err != nil {
-				return err
-			}
-		} else {
-			// We are updating a match result record that already exists.
-			err := web.arena.Database.UpdateMatchResult(matchResult)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Update and save the match record to the database.
-		match.ScoreCommittedAt = time.Now()
-		redScoreSummary := matchResult.RedScoreSummary()
-		blueScoreSummary := matchResult.BlueScoreSummary()
-		match.Status = game.DetermineMatchStatus(redScoreSummary, blueScoreSummary)
-		err := web.arena.Database.UpdateMatch(match)
-		if err != nil {
+		// Write the result and everything derived from it in a single transaction, so that a failure partway through
+		// can't leave the rankings or bracket out of sync with the results.
+		originalMatch, originalMatchResult := *match, *matchResult
+		err := web.arena.Database.RunInTransaction(func(database *model.Database) error {
+			var err error
+			updatedRankings, err = commitMatchScoreInTransaction(database, arena, match, matchResult, isMatchReviewEdit)
 			return err
-		}
-
-		if match.ShouldUpdateRankings() {
-			// Recalculate all the rankings.
-			rankings, err := tournament.CalculateRankings(web.arena.Database, isMatchReviewEdit)
-			if err != nil {
-				return err
+		})
+		if err != nil {
+			// Undo the changes made to the in-memory state along with those that were rolled back.
+			*match, *matchResult = originalMatch, originalMatchResult
+			if bracketErr := web.syncPlayoffBrackets(nil); bracketErr != nil {
+				log.Printf("Failed to rebuild playoff bracket: %v", bracketErr)
 			}
-			updatedRankings = rankings
+			return err
 		}
-
 		if match.ShouldUpdateEliminationMatches() {
-			if err = web.arena.Database.UpdateAllianceFromMatch(
-				match.ElimRedAlliance, [3]int{match.Red1, match.Red2, match.Red3},
-			); err != nil {
-				return err
-			}
-			if err = web.arena.Database.UpdateAllianceFromMatch(
-				match.ElimBlueAlliance, [3]int{match.Blue1, match.Blue2, match.Blue3},
-			); err != nil {
+			if err = web.syncPlayoffBrackets(arena); err != nil {
 				return err
 			}
+		}
 
-			// Generate any subsequent elimination matches.
-			nextMatchTime := time.Now().Add(time.Second * bracket.ElimMatchSpacingSec)
-			if err = web.arena.UpdatePlayoffBracket(&nextMatchTime); err != nil {
-				return err
+		if match.Type != "practice" {
+			// Publish asynchronously to all the enabled results publishers.
+			resources := []partner.PublishResource{partner.MatchesResource}
+			if match.ShouldUpdateRankings() {
+				resources = append(resources, partner.RankingsResource)
 			}
-
-			// Generate awards if the tournament is over.
-			if web.arena.PlayoffBracket.IsComplete() {
-				winnerAllianceId := web.arena.PlayoffBracket.Winner()
-				finalistAllianceId := web.arena.PlayoffBracket.Finalist()
-				if err = tournament.CreateOrUpdateWinnerAndFinalistAwards(
-					web.arena.Database, winnerAllianceId, finalistAllianceId,
-				); err != nil {
-					return err
+			if match.ShouldUpdateEliminationMatches() {
+				resources = append(resources, partner.AlliancesResource)
+				if arena.PlayoffBracket.IsComplete() {
+					resources = append(resources, partner.AwardsResource)
 				}
 			}
-		}
-
-		if web.arena.EventSettings.TbaPublishingEnabled && match.Type != "practice" {
-			// Publish asynchronously to The Blue Alliance.
-			go func() {
-				if err = web.arena.TbaClient.PublishMatches(web.arena.Database); err != nil {
-					log.Printf("Failed to publish matches: %s", err.Error())
-				}
-				if match.ShouldUpdateRankings() {
-					if err = web.arena.TbaClient.PublishRankings(web.arena.Database); err != nil {
-						log.Printf("Failed to publish rankings: %s", err.Error())
-					}
-				}
-			}()
+			web.arena.PublishAsync(resources...)
 		}
 
 		// Back up the database, but don't error out if it fails.
@@ -527,23 +578,106 @@ func (web *Web) commitMatchScore(match *model.Match, matchResult *model.MatchRes
 
 	if !isMatchReviewEdit {
 		// Store the result in the buffer to be shown in the audience display.
-		web.arena.SavedMatch = match
-		web.arena.SavedMatchResult = matchResult
-		web.arena.SavedRankings = updatedRankings
-		web.arena.ScorePostedNotifier.Notify()
+		arena.SavedMatch = match
+		arena.SavedMatchResult = matchResult
+		arena.SavedRankings = updatedRankings
+		arena.ScorePostedNotifier.Notify()
 	}
 
 	return nil
 }
 
-func (web *Web) getCurrentMatchResult() *model.MatchResult {
-	return &model.MatchResult{MatchId: web.arena.CurrentMatch.Id, MatchType: web.arena.CurrentMatch.Type,
-		RedScore: web.arena.RedScore, BlueScore: web.arena.BlueScore}
+// Saves the given match result and updates the match, rankings, alliances, playoff bracket and awards accordingly,
+// all through the given database, which is in a transaction. Returns the updated rankings, if they changed.
+func commitMatchScoreInTransaction(
+	database *model.Database,
+	arena *field.Arena,
+	match *model.Match,
+	matchResult *model.MatchResult,
+	isMatchReviewEdit bool,
+) (game.Rankings, error) {
+	if matchResult.PlayNumber == 0 {
+		// Determine the play number for this new match result.
+		prevMatchResult, err := database.GetMatchResultForMatch(match.Id)
+		if err != nil {
+			return nil, err
+		}
+		if prevMatchResult != nil {
+			matchResult.PlayNumber = prevMatchResult.PlayNumber + 1
+		} else {
+			matchResult.PlayNumber = 1
+		}
+
+		// Save the match result record to the database.
+		if err = database.CreateMatchResult(matchResult); err != nil {
+			return nil, err
+		}
+	} else {
+		// We are updating a match result record that already exists.
+		if err := database.UpdateMatchResult(matchResult); err != nil {
+			return nil, err
+		}
+	}
+
+	// Update and save the match record to the database.
+	match.ScoreCommittedAt = time.Now()
+	redScoreSummary := matchResult.RedScoreSummary()
+	blueScoreSummary := matchResult.BlueScoreSummary()
+	match.Status = game.DetermineMatchStatus(redScoreSummary, blueScoreSummary)
+	if err := database.UpdateMatch(match); err != nil {
+		return nil, err
+	}
+
+	var updatedRankings game.Rankings
+	if match.ShouldUpdateRankings() {
+		// Recalculate all the rankings.
+		rankings, err := tournament.CalculateRankings(database, isMatchReviewEdit)
+		if err != nil {
+			return nil, err
+		}
+		updatedRankings = rankings
+	}
+
+	if match.ShouldUpdateEliminationMatches() {
+		if err := database.UpdateAllianceFromMatch(
+			match.ElimRedAlliance, [3]int{match.Red1, match.Red2, match.Red3},
+		); err != nil {
+			return nil, err
+		}
+		if err := database.UpdateAllianceFromMatch(
+			match.ElimBlueAlliance, [3]int{match.Blue1, match.Blue2, match.Blue3},
+		); err != nil {
+			return nil, err
+		}
+
+		// Generate any subsequent elimination matches.
+		nextMatchTime := time.Now().Add(time.Second * bracket.ElimMatchSpacingSec)
+		if err := arena.UpdatePlayoffBracketInTransaction(database, &nextMatchTime); err != nil {
+			return nil, err
+		}
+
+		// Generate awards if the tournament is over.
+		if arena.PlayoffBracket.IsComplete() {
+			winnerAllianceId := arena.PlayoffBracket.Winner()
+			finalistAllianceId := arena.PlayoffBracket.Finalist()
+			if err := tournament.CreateOrUpdateWinnerAndFinalistAwards(
+				database, winnerAllianceId, finalistAllianceId,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return updatedRankings, nil
+}
+
+func (web *Web) getCurrentMatchResult(arena *field.Arena) *model.MatchResult {
+	return &model.MatchResult{MatchId: arena.CurrentMatch.Id, MatchType: arena.CurrentMatch.Type,
+		RedScore: arena.RedScore, BlueScore: arena.BlueScore}
 }
 
-// Saves the realtime result as the final score for the match currently loaded into the arena.
-func (web *Web) commitCurrentMatchScore() error {
-	return web.commitMatchScore(web.arena.CurrentMatch, web.getCurrentMatchResult(), false)
+// Saves the realtime result as the final score for the match currently loaded into the given arena.
+func (web *Web) commitCurrentMatchScore(arena *field.Arena) error {
+	return web.commitMatchScore(arena.CurrentMatch, web.getCurrentMatchResult(arena), false)
 }
 
 // Helper function to implement the required interface for Sort.
@@ -561,15 +695,21 @@ func (list MatchPlayList) Swap(i, j int) {
 	list[i], list[j] = list[j], list[i]
 }
 
-// Constructs the list of matches to display on the side of the match play interface.
-func (web *Web) buildMatchPlayList(matchType string) (MatchPlayList, error) {
-	matches, err := web.arena.Database.GetMatchesByType(matchType)
+// Constructs the list of matches to display on the side of the match play interface for the given arena's field.
+func (web *Web) buildMatchPlayList(arena *field.Arena, matchType string) (MatchPlayList, error) {
+	matches, err := arena.Database.GetMatchesByType(matchType)
 	if err != nil {
 		return MatchPlayList{}, err
 	}
+	var fieldMatches []model.Match
+	for _, match := range matches {
+		if match.IsOnField(arena.FieldId) {
+			fieldMatches = append(fieldMatches, match)
+		}
+	}
 
-	matchPlayList := make(MatchPlayList, len(matches))
-	for i, match := range matches {
+	matchPlayList := make(MatchPlayList, len(fieldMatches))
+	for i, match := range fieldMatches {
 		matchPlayList[i].Id = match.Id
 		matchPlayList[i].DisplayName = match.TypePrefix() + match.DisplayName
 		matchPlayList[i].Time = match.Time.Local().Format("3:04 PM")
@@ -584,7 +724,7 @@ func (web *Web) buildMatchPlayList(matchType string) (MatchPlayList, error) {
 		default:
 			matchPlayList[i].ColorClass = ""
 		}
-		if web.arena.CurrentMatch != nil && matchPlayList[i].Id == web.arena.CurrentMatch.Id {
+		if arena.CurrentMatch != nil && matchPlayList[i].Id == arena.CurrentMatch.Id {
 			matchPlayList[i].ColorClass = "success"
 		}
 	}
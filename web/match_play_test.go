@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"net/http"
 	"testing"
 	"time"
 
@@ -303,6 +304,35 @@ func TestMatchPlayWebsocketCommands(t *testing.T) {
 	assert.Equal(t, "logo", web.arena.AllianceStationDisplayMode)
 }
 
+func TestMatchPlayWebsocketRevokedSession(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.AdminPassword = "admin"
+	user, _ := model.NewUser("bertha", "refpass", model.RoleHeadRef)
+	web.arena.Database.CreateUser(user)
+	recorder := web.postHttpResponse("/login", "username=bertha&password=refpass")
+	cookie := recorder.Header().Get("Set-Cookie")
+
+	server, wsUrl := web.startTestServer()
+	defer server.Close()
+	conn, _, err := gorillawebsocket.DefaultDialer.Dial(
+		wsUrl+"/match_play/websocket", http.Header{"Cookie": []string{cookie}},
+	)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws := websocket.NewTestWebsocket(conn)
+	readWebsocketMultiple(t, ws, 7)
+
+	ws.Write("startMatch", nil)
+	assert.Contains(t, readWebsocketError(t, ws), "isn't allowed to send the 'startMatch' command")
+
+	// Check that the websocket is closed once the session is revoked, rather than keeping its access.
+	web.arena.Database.DeleteUserSessionsByUsername("bertha")
+	ws.Write("discardResults", nil)
+	assert.Contains(t, readWebsocketError(t, ws), "Your session has ended")
+	_, _, err = ws.ReadWithTimeout(time.Second)
+	assert.NotNil(t, err)
+}
+
 func TestMatchPlayWebsocketNotifications(t *testing.T) {
 	web := setupTestWeb(t)
 
@@ -390,3 +420,78 @@ func getStatusMatchTime(t *testing.T, messages map[string]interface{}) (bool, fi
 	}
 	return statusReceived, matchTime
 }
+
+func TestMatchPlayMultipleFields(t *testing.T) {
+	web := setupTestWebWithFields(t, 2)
+	arena2 := web.arena.FieldArena(2)
+
+	match1 := model.Match{Type: "qualification", DisplayName: "1", FieldId: 1}
+	match2 := model.Match{Type: "qualification", DisplayName: "2", FieldId: 2}
+	match3 := model.Match{Type: "qualification", DisplayName: "3", Red1: 101, Red2: 102, Red3: 103, Blue1: 104,
+		Blue2: 105, Blue3: 106}
+	web.arena.Database.CreateMatch(&match1)
+	web.arena.Database.CreateMatch(&match2)
+	web.arena.Database.CreateMatch(&match3)
+
+	// Check that each field only lists the matches that may be played on it.
+	recorder := web.getHttpResponse("/match_play")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Q1")
+	assert.NotContains(t, recorder.Body.String(), "Q2")
+	assert.Contains(t, recorder.Body.String(), "Q3")
+	recorder = web.getHttpResponse("/match_play?field=2")
+	assert.Equal(t, 200, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "Q1")
+	assert.Contains(t, recorder.Body.String(), "Q2")
+	assert.Contains(t, recorder.Body.String(), "/match_play/2/load?field=2")
+
+	// Check that loading a match on the second field leaves the first alone.
+	recorder = web.getHttpResponse("/match_play/2/load?field=2")
+	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, "/match_play?field=2", recorder.Header().Get("Location"))
+	assert.Equal(t, match2.Id, arena2.CurrentMatch.Id)
+	assert.Equal(t, "test", web.arena.CurrentMatch.Type)
+
+	// Check that a match can't be loaded on both fields at once.
+	recorder = web.getHttpResponse("/match_play/3/load?field=2")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.getHttpResponse("/match_play/3/load")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "already loaded on field 2")
+
+	// Check that committing a result on the second field posts it to that field's displays.
+	arena2.RedScore = game.TestScore1()
+	assert.Nil(t, web.commitCurrentMatchScore(arena2))
+	assert.Equal(t, match3.Id, arena2.SavedMatch.Id)
+	assert.Equal(t, 0, web.arena.SavedMatch.Id)
+	match, _ := web.arena.Database.GetMatchById(match3.Id)
+	assert.True(t, match.IsComplete())
+}
+
+func TestCommitMatchRollsBackOnFailure(t *testing.T) {
+	web := setupTestWeb(t)
+
+	// A qualification match with empty stations can't be ranked, so the commit fails after the result is written.
+	match := &model.Match{Type: "qualification", DisplayName: "1", Red1: 254}
+	assert.Nil(t, web.arena.Database.CreateMatch(match))
+	assert.Nil(t, web.arena.Database.CreateRanking(&game.Ranking{TeamId: 1114, Rank: 1}))
+	matchResult := model.NewMatchResult()
+	matchResult.MatchId = match.Id
+	matchResult.RedScore = &game.Score{AutoPoints: 10}
+	err := web.commitMatchScore(match, matchResult, false)
+	assert.NotNil(t, err)
+
+	// Check that none of the writes were applied and that the in-memory objects were restored.
+	assert.Equal(t, 0, matchResult.PlayNumber)
+	assert.Equal(t, 0, matchResult.Id)
+	dbMatchResult, _ := web.arena.Database.GetMatchResultForMatch(match.Id)
+	assert.Nil(t, dbMatchResult)
+	match, _ = web.arena.Database.GetMatchById(match.Id)
+	assert.Equal(t, game.MatchNotPlayed, match.Status)
+	assert.True(t, match.ScoreCommittedAt.IsZero())
+	rankings, _ := web.arena.Database.GetAllRankings()
+	if assert.Equal(t, 1, len(rankings)) {
+		assert.Equal(t, 1114, rankings[0].TeamId)
+	}
+	assert.Equal(t, 0, web.arena.SavedMatch.Id)
+}
@@ -9,10 +9,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/BotDogs4645/da/game"
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 	"github.com/gorilla/mux"
 )
 
@@ -26,6 +29,7 @@ type MatchReviewListItem struct {
 	BlueScore   int
 	ColorClass  string
 	IsComplete  bool
+	VideoLink   string
 }
 
 // Shows the match review interface.
@@ -71,11 +75,11 @@ func (web *Web) matchReviewHandler(w http.ResponseWriter, r *http.Request) {
 
 // Shows the page to edit the results for a match.
 func (web *Web) matchReviewEditGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleHeadRef) {
 		return
 	}
 
-	match, matchResult, _, err := web.getMatchResultFromRequest(r)
+	match, matchResult, isCurrent, err := web.getMatchResultFromRequest(r)
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -95,7 +99,9 @@ func (web *Web) matchReviewEditGetHandler(w http.ResponseWriter, r *http.Request
 		*model.EventSettings
 		Match           *model.Match
 		MatchResultJson string
-	}{web.arena.EventSettings, match, string(matchResultJson)}
+		IsCurrent       bool
+		VodTimestamp    string
+	}{web.arena.EventSettings, match, string(matchResultJson), isCurrent, formatVodTimestamp(match.VodOffsetSec)}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
@@ -105,15 +111,17 @@ func (web *Web) matchReviewEditGetHandler(w http.ResponseWriter, r *http.Request
 
 // Updates the results for a match.
 func (web *Web) matchReviewEditPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleHeadRef) {
 		return
 	}
 
-	match, _, isCurrent, err := web.getMatchResultFromRequest(r)
+	match, previousMatchResult, isCurrent, err := web.getMatchResultFromRequest(r)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
+	// Copy the scores now, since those of the current match are changed in place.
+	previousScores := auditedScores{*previousMatchResult.RedScore, *previousMatchResult.BlueScore}
 
 	var matchResult model.MatchResult
 	if err = json.Unmarshal([]byte(r.PostFormValue("matchResultJson")), &matchResult); err != nil {
@@ -127,28 +135,85 @@ func (web *Web) matchReviewEditPostHandler(w http.ResponseWriter, r *http.Reques
 
 	if isCurrent {
 		// If editing the current match, just save it back to memory.
-		*web.arena.RedScore = *matchResult.RedScore
-		*web.arena.BlueScore = *matchResult.BlueScore
+		arena := web.arenaForRequest(r)
+		*arena.RedScore = *matchResult.RedScore
+		*arena.BlueScore = *matchResult.BlueScore
+		web.audit(
+			r,
+			auditMatchResultEdit,
+			matchPlayAuditTarget(arena, ""),
+			previousScores,
+			auditedScores{*matchResult.RedScore, *matchResult.BlueScore},
+		)
 
-		http.Redirect(w, r, "/match_play", 303)
+		http.Redirect(w, r, "/match_play"+fieldQuery(arena), 303)
 	} else {
 		err = web.commitMatchScore(match, &matchResult, true)
 		if err != nil {
 			handleWebErr(w, err)
 			return
 		}
+		web.audit(
+			r,
+			auditMatchResultEdit,
+			fmt.Sprintf("%s match %s", match.Type, match.DisplayName),
+			previousScores,
+			auditedScores{*matchResult.RedScore, *matchResult.BlueScore},
+		)
 
 		http.Redirect(w, r, "/match_review", 303)
 	}
 }
 
+// Saves the video URL or livestream VOD timestamp for a match and publishes it.
+func (web *Web) matchReviewVideoPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleHeadRef) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	matchId, _ := strconv.Atoi(vars["matchId"])
+	match, err := web.arena.Database.GetMatchById(matchId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if match == nil {
+		http.Error(w, fmt.Sprintf("Error: No such match: %d", matchId), 400)
+		return
+	}
+
+	videoUrl := strings.TrimSpace(r.PostFormValue("videoUrl"))
+	if _, ok := partner.ParseYoutubeVideoId(videoUrl); videoUrl != "" && !ok {
+		http.Error(w, fmt.Sprintf("Error: '%s' is not a YouTube video URL.", videoUrl), 400)
+		return
+	}
+	vodOffsetSec, err := parseVodTimestamp(r.PostFormValue("vodTimestamp"))
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), 400)
+		return
+	}
+	match.VideoUrl = videoUrl
+	match.VodOffsetSec = vodOffsetSec
+	if err = web.arena.Database.UpdateMatch(match); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if match.Type == "qualification" || match.Type == "elimination" {
+		web.arena.PublishAsync(partner.VideosResource)
+	}
+
+	http.Redirect(w, r, "/match_review", 303)
+}
+
 // Load the match result for the match referenced in the HTTP query string.
 func (web *Web) getMatchResultFromRequest(r *http.Request) (*model.Match, *model.MatchResult, bool, error) {
 	vars := mux.Vars(r)
 
 	// If editing the current match, get it from memory instead of the DB.
 	if vars["matchId"] == "current" {
-		return web.arena.CurrentMatch, web.getCurrentMatchResult(), true, nil
+		arena := web.arenaForRequest(r)
+		return arena.CurrentMatch, web.getCurrentMatchResult(arena), true, nil
 	}
 
 	matchId, _ := strconv.Atoi(vars["matchId"])
@@ -195,6 +260,7 @@ func (web *Web) buildMatchReviewList(matchType string) ([]MatchReviewListItem, e
 			matchReviewList[i].RedScore = matchResult.RedScoreSummary().Score
 			matchReviewList[i].BlueScore = matchResult.BlueScoreSummary().Score
 		}
+		matchReviewList[i].VideoLink = getMatchVideoLink(&match, web.arena.EventSettings.LivestreamVodUrl)
 		switch match.Status {
 		case game.RedWonMatch:
 			matchReviewList[i].ColorClass = "danger"
@@ -213,3 +279,51 @@ func (web *Web) buildMatchReviewList(matchType string) ([]MatchReviewListItem, e
 
 	return matchReviewList, nil
 }
+
+// Returns the URL at which the given match can be watched, or an empty string if it doesn't have a video.
+func getMatchVideoLink(match *model.Match, livestreamVodUrl string) string {
+	if match.VideoUrl != "" {
+		return match.VideoUrl
+	}
+	if match.VodOffsetSec > 0 && livestreamVodUrl != "" {
+		vodUrl, err := url.Parse(livestreamVodUrl)
+		if err != nil {
+			return ""
+		}
+		query := vodUrl.Query()
+		query.Set("t", strconv.Itoa(match.VodOffsetSec))
+		vodUrl.RawQuery = query.Encode()
+		return vodUrl.String()
+	}
+	return ""
+}
+
+// Parses a livestream VOD timestamp given as seconds, M:SS or H:MM:SS into a number of seconds. An empty timestamp is
+// treated as zero.
+func parseVodTimestamp(timestamp string) (int, error) {
+	timestamp = strings.TrimSpace(timestamp)
+	if timestamp == "" {
+		return 0, nil
+	}
+	parts := strings.Split(timestamp, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid VOD timestamp '%s'", timestamp)
+	}
+	seconds := 0
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 || (i > 0 && value >= 60) {
+			return 0, fmt.Errorf("invalid VOD timestamp '%s'", timestamp)
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds, nil
+}
+
+// Formats the given number of seconds as an H:MM:SS livestream VOD timestamp, or an empty string if it is zero.
+func formatVodTimestamp(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
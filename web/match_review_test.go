@@ -159,3 +159,61 @@ func TestMatchReviewEditCurrentMatch(t *testing.T) {
 	assert.Equal(t, 50, web.arena.BlueScore.TeleopPoints)
 	assert.Equal(t, 60, web.arena.BlueScore.EndgamePoints)
 }
+
+func TestMatchReviewVideo(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.LivestreamVodUrl = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+
+	match := model.Match{Type: "qualification", DisplayName: "12"}
+	assert.Nil(t, web.arena.Database.CreateMatch(&match))
+	recorder := web.getHttpResponse(fmt.Sprintf("/match_review/%d/edit", match.Id))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Match Video")
+	recorder = web.getHttpResponse("/match_review/current/edit")
+	assert.NotContains(t, recorder.Body.String(), "Match Video")
+
+	// Set a livestream VOD timestamp.
+	recorder = web.postHttpResponse(fmt.Sprintf("/match_review/%d/video", match.Id), "vodTimestamp=1:02:05")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	match2, _ := web.arena.Database.GetMatchById(match.Id)
+	assert.Equal(t, 3725, match2.VodOffsetSec)
+	assert.Equal(t, "", match2.VideoUrl)
+	recorder = web.getHttpResponse("/match_review")
+	assert.Contains(t, recorder.Body.String(), "https://www.youtube.com/watch?t=3725&v=dQw4w9WgXcQ")
+	recorder = web.getHttpResponse(fmt.Sprintf("/match_review/%d/edit", match.Id))
+	assert.Contains(t, recorder.Body.String(), "value=\"1:02:05\"")
+
+	// Set a video URL.
+	recorder = web.postHttpResponse(
+		fmt.Sprintf("/match_review/%d/video", match.Id), "videoUrl=https://youtu.be/abcdefghijk&vodTimestamp=",
+	)
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	match2, _ = web.arena.Database.GetMatchById(match.Id)
+	assert.Equal(t, 0, match2.VodOffsetSec)
+	assert.Equal(t, "https://youtu.be/abcdefghijk", match2.VideoUrl)
+
+	// Check invalid input.
+	recorder = web.postHttpResponse(fmt.Sprintf("/match_review/%d/video", match.Id), "videoUrl=https://twitch.tv/x")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "is not a YouTube video URL")
+	recorder = web.postHttpResponse(fmt.Sprintf("/match_review/%d/video", match.Id), "vodTimestamp=1:75")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid VOD timestamp")
+	recorder = web.postHttpResponse("/match_review/12345/video", "")
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestVodTimestamps(t *testing.T) {
+	for timestamp, seconds := range map[string]int{"": 0, "90": 90, "1:30": 90, "1:02:05": 3725, " 0:00:07 ": 7} {
+		parsedSeconds, err := parseVodTimestamp(timestamp)
+		assert.Nil(t, err, timestamp)
+		assert.Equal(t, seconds, parsedSeconds, timestamp)
+	}
+	for _, timestamp := range []string{"abc", "1:60", "-5", "1:2:3:4"} {
+		_, err := parseVodTimestamp(timestamp)
+		assert.NotNil(t, err, timestamp)
+	}
+	assert.Equal(t, "", formatVodTimestamp(0))
+	assert.Equal(t, "0:01:30", formatVodTimestamp(90))
+	assert.Equal(t, "1:02:05", formatVodTimestamp(3725))
+}
@@ -0,0 +1,218 @@
+// Web handlers for the per-team pit display.
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/websocket"
+)
+
+const numPitMatchesToShow = 3
+
+// PitDisplayMatch An upcoming match as seen from the perspective of the pit display's team.
+type PitDisplayMatch struct {
+	model.Match
+	MatchName   string
+	Station     string
+	IsRed       bool
+	Partners    []int
+	Opponents   []int
+	MatchesAway int
+	QueueTime   time.Time
+}
+
+// PitDisplayResult The outcome of the pit display team's most recently played match.
+type PitDisplayResult struct {
+	MatchName     string
+	IsRed         bool
+	OwnScore      int
+	OpponentScore int
+	Outcome       string
+}
+
+// Renders the pit display that shows the upcoming matches and standing of a single team.
+func (web *Web) pitDisplayHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.enforceDisplayConfiguration(w, r, map[string]string{"teamId": "0", "queueLeadMin": "10"}) {
+		return
+	}
+
+	teamId, _ := strconv.Atoi(r.URL.Query().Get("teamId"))
+	queueLeadMin, _ := strconv.Atoi(r.URL.Query().Get("queueLeadMin"))
+	team, err := web.arena.Database.GetTeamById(teamId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	var upcomingMatches []PitDisplayMatch
+	var ranking *game.Ranking
+	var lastResult *PitDisplayResult
+	if team != nil {
+		if upcomingMatches, err = web.getPitDisplayMatches(teamId, time.Duration(queueLeadMin)*time.Minute); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if ranking, err = web.arena.Database.GetRankingForTeam(teamId); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if lastResult, err = web.getPitDisplayLastResult(teamId); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+
+	template, err := web.parseFiles("templates/pit_display.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Team       *model.Team
+		TeamId     int
+		Matches    []PitDisplayMatch
+		Ranking    *game.Ranking
+		LastResult *PitDisplayResult
+	}{web.arena.EventSettings, team, teamId, upcomingMatches, ranking, lastResult}
+	err = template.ExecuteTemplate(w, "pit_display.html", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// The websocket endpoint for the pit display to receive updates.
+func (web *Web) pitDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	display, err := web.registerDisplay(r)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer web.arena.MarkDisplayDisconnected(display.DisplayConfiguration.Id)
+
+	ws, err := websocket.NewWebsocket(w, r)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer ws.Close()
+
+	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
+	ws.HandleNotifiers(display.Notifier, web.arena.MatchLoadNotifier, web.arena.ScorePostedNotifier,
+		web.arena.EventStatusNotifier, web.arena.ReloadDisplaysNotifier)
+}
+
+// Returns the given team's next few unplayed matches of the type currently being played.
+func (web *Web) getPitDisplayMatches(teamId int, queueLead time.Duration) ([]PitDisplayMatch, error) {
+	matchType := web.arena.CurrentMatch.Type
+	if matchType == "test" {
+		matchType = "qualification"
+	}
+	matches, err := web.arena.Database.GetMatchesByType(matchType)
+	if err != nil {
+		return nil, err
+	}
+
+	var pitMatches []PitDisplayMatch
+	matchesAway := 0
+	for _, match := range matches {
+		if match.IsComplete() {
+			continue
+		}
+		if station := getTeamStation(&match, teamId); station != "" {
+			pitMatch := PitDisplayMatch{
+				Match:       match,
+				MatchName:   match.TypePrefix() + match.DisplayName,
+				Station:     station,
+				IsRed:       station[0] == 'R',
+				MatchesAway: matchesAway,
+				QueueTime:   match.Time.Add(-queueLead),
+			}
+			allianceTeams := []int{match.Red1, match.Red2, match.Red3}
+			opposingTeams := []int{match.Blue1, match.Blue2, match.Blue3}
+			if !pitMatch.IsRed {
+				allianceTeams, opposingTeams = opposingTeams, allianceTeams
+			}
+			for _, allianceTeamId := range allianceTeams {
+				if allianceTeamId != 0 && allianceTeamId != teamId {
+					pitMatch.Partners = append(pitMatch.Partners, allianceTeamId)
+				}
+			}
+			for _, opposingTeamId := range opposingTeams {
+				if opposingTeamId != 0 {
+					pitMatch.Opponents = append(pitMatch.Opponents, opposingTeamId)
+				}
+			}
+			pitMatches = append(pitMatches, pitMatch)
+			if len(pitMatches) == numPitMatchesToShow {
+				break
+			}
+		}
+		matchesAway++
+	}
+	return pitMatches, nil
+}
+
+// Returns the result of the given team's most recently committed match, or nil if it hasn't played any.
+func (web *Web) getPitDisplayLastResult(teamId int) (*PitDisplayResult, error) {
+	var lastMatch *model.Match
+	for _, matchType := range []string{"practice", "qualification", "elimination"} {
+		matches, err := web.arena.Database.GetMatchesByType(matchType)
+		if err != nil {
+			return nil, err
+		}
+		for i, match := range matches {
+			if match.IsComplete() && getTeamStation(&match, teamId) != "" &&
+				(lastMatch == nil || match.ScoreCommittedAt.After(lastMatch.ScoreCommittedAt)) {
+				lastMatch = &matches[i]
+			}
+		}
+	}
+	if lastMatch == nil {
+		return nil, nil
+	}
+	matchResult, err := web.arena.Database.GetMatchResultForMatch(lastMatch.Id)
+	if err != nil {
+		return nil, err
+	}
+	if matchResult == nil {
+		return nil, nil
+	}
+
+	result := PitDisplayResult{
+		MatchName:     lastMatch.TypePrefix() + lastMatch.DisplayName,
+		IsRed:         getTeamStation(lastMatch, teamId)[0] == 'R',
+		OwnScore:      matchResult.RedScoreSummary().Score,
+		OpponentScore: matchResult.BlueScoreSummary().Score,
+	}
+	if !result.IsRed {
+		result.OwnScore, result.OpponentScore = result.OpponentScore, result.OwnScore
+	}
+	if result.OwnScore > result.OpponentScore {
+		result.Outcome = "Win"
+	} else if result.OwnScore < result.OpponentScore {
+		result.Outcome = "Loss"
+	} else {
+		result.Outcome = "Tie"
+	}
+	return &result, nil
+}
+
+// Returns the alliance station (e.g. "R1") of the given team in the given match, or an empty string if the team isn't
+// in the match.
+func getTeamStation(match *model.Match, teamId int) string {
+	for station, stationTeamId := range map[string]int{
+		"R1": match.Red1, "R2": match.Red2, "R3": match.Red3, "B1": match.Blue1, "B2": match.Blue2, "B3": match.Blue3,
+	} {
+		if teamId != 0 && stationTeamId == teamId {
+			return station
+		}
+	}
+	return ""
+}
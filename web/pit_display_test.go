@@ -0,0 +1,97 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/websocket"
+	gorillawebsocket "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPitDisplay(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/displays/pit?displayId=1&queueLeadMin=10&teamId=254")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Pit Display - Untitled Event - Cheesy Arena")
+	assert.Contains(t, recorder.Body.String(), "Team 254 is not at this event")
+
+	recorder = web.getHttpResponse("/displays/pit?displayId=1")
+	assert.Equal(t, 302, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Location"), "teamId=0")
+	assert.Contains(t, recorder.Header().Get("Location"), "queueLeadMin=10")
+}
+
+func TestPitDisplayMatches(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, Nickname: "The Cheesy Poofs"})
+	matchTime := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+	match1 := model.Match{Type: "qualification", DisplayName: "1", Time: matchTime, Red1: 254, Red2: 1114,
+		Red3: 2056, Blue1: 1678, Blue2: 971, Blue3: 604, Status: game.RedWonMatch, ScoreCommittedAt: matchTime}
+	web.arena.Database.CreateMatch(&match1)
+	matchResult := model.NewMatchResult()
+	matchResult.MatchId = match1.Id
+	matchResult.RedScore = &game.Score{TeleopPoints: 60}
+	matchResult.BlueScore = &game.Score{TeleopPoints: 40}
+	web.arena.Database.CreateMatchResult(matchResult)
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2",
+		Time: matchTime.Add(10 * time.Minute), Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5, Blue3: 6})
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "3",
+		Time: matchTime.Add(20 * time.Minute), Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 254, Blue3: 6})
+	web.arena.Database.CreateRanking(&game.Ranking{TeamId: 254, Rank: 3,
+		RankingFields: game.RankingFields{Wins: 1, Losses: 0, Ties: 0, Played: 1}})
+
+	recorder := web.getHttpResponse("/displays/pit?displayId=1&queueLeadMin=15&teamId=254")
+	assert.Equal(t, 200, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "Team 254 The Cheesy Poofs")
+	assert.Contains(t, body, "<h1>3</h1>")
+	assert.Contains(t, body, "1-0-0")
+	assert.Contains(t, body, "Q1:")
+	assert.Contains(t, body, "Win")
+	assert.Contains(t, body, "<h1>Q3</h1>")
+	assert.Contains(t, body, "On Deck")
+	assert.Contains(t, body, ">B2</h1>")
+
+	matches, err := web.getPitDisplayMatches(254, 15*time.Minute)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(matches)) {
+		assert.Equal(t, "Q3", matches[0].MatchName)
+		assert.Equal(t, "B2", matches[0].Station)
+		assert.False(t, matches[0].IsRed)
+		assert.Equal(t, []int{4, 6}, matches[0].Partners)
+		assert.Equal(t, []int{1, 2, 3}, matches[0].Opponents)
+		assert.Equal(t, 1, matches[0].MatchesAway)
+		assert.Equal(t, matchTime.Add(5*time.Minute), matches[0].QueueTime)
+	}
+
+	result, err := web.getPitDisplayLastResult(254)
+	assert.Nil(t, err)
+	if assert.NotNil(t, result) {
+		assert.Equal(t, PitDisplayResult{"Q1", true, 60, 40, "Win"}, *result)
+	}
+	result, err = web.getPitDisplayLastResult(1)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestPitDisplayWebsocket(t *testing.T) {
+	web := setupTestWeb(t)
+
+	server, wsUrl := web.startTestServer()
+	defer server.Close()
+	conn, _, err := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/displays/pit/websocket?displayId=1&teamId=254", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws := websocket.NewTestWebsocket(conn)
+
+	// Should get a few status updates right after connection.
+	readWebsocketType(t, ws, "displayConfiguration")
+	readWebsocketType(t, ws, "matchLoad")
+	readWebsocketType(t, ws, "scorePosted")
+	readWebsocketType(t, ws, "eventStatus")
+}
@@ -0,0 +1,254 @@
+// Web handlers for the read-only public spectator portal. None of these require authentication, and none of the pages
+// link to anything outside of the /public section.
+
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/websocket"
+	"github.com/gorilla/mux"
+)
+
+// PublicMatch A match and its result, if any, as shown in the public schedule.
+type PublicMatch struct {
+	model.Match
+	MatchName   string
+	RedTeamIds  []int
+	BlueTeamIds []int
+	RedScore    int
+	BlueScore   int
+}
+
+var publicMatchTypes = map[string]string{
+	"practice":      "Practice",
+	"qualification": "Qualification",
+	"elimination":   "Playoff",
+}
+
+// ServePublicInterface Starts a webserver that serves only the public portal and static resources, and blocks. This
+// allows the portal to be exposed on an untrusted network (e.g. venue Wi-Fi) without exposing any admin routes.
+func (web *Web) ServePublicInterface(port int) {
+	serveMux := http.NewServeMux()
+	serveMux.Handle("/static/", http.StripPrefix("/static/", addNoCacheHeader(http.FileServer(http.Dir("static/")))))
+	serveMux.Handle("/", web.newPublicHandler())
+	log.Printf("Serving public HTTP requests on port %d", port)
+
+	err := http.ListenAndServe(fmt.Sprintf(":%d", port), serveMux)
+	if err != nil {
+		log.Printf("Failed to serve public HTTP requests: %v", err)
+	}
+}
+
+// Sets up the mapping between URLs and handlers for the public portal only.
+func (web *Web) newPublicHandler() http.Handler {
+	router := mux.NewRouter()
+	router.Handle("/", http.RedirectHandler("/public", 302)).Methods("GET")
+	web.addPublicRoutes(router)
+	return router
+}
+
+// Adds the public portal routes to the given router.
+func (web *Web) addPublicRoutes(router *mux.Router) {
+	router.HandleFunc("/public", web.publicIndexHandler).Methods("GET")
+	router.HandleFunc("/public/bracket", web.publicBracketHandler).Methods("GET")
+	router.HandleFunc("/public/bracket/svg", web.bracketSvgApiHandler).Methods("GET")
+	router.HandleFunc("/public/rankings", web.publicRankingsHandler).Methods("GET")
+	router.HandleFunc("/public/schedule/{type}", web.publicScheduleHandler).Methods("GET")
+	router.HandleFunc("/public/teams", web.publicTeamsHandler).Methods("GET")
+	router.HandleFunc("/public/teams/{teamId}", web.publicTeamHandler).Methods("GET")
+	router.HandleFunc("/public/teams/{teamId}/avatar", web.teamAvatarsApiHandler).Methods("GET")
+	router.HandleFunc("/public/websocket", web.publicWebsocketHandler).Methods("GET")
+}
+
+// Shows the public portal landing page with the live score of the current match.
+func (web *Web) publicIndexHandler(w http.ResponseWriter, r *http.Request) {
+	var currentMatch *PublicMatch
+	if web.arena.CurrentMatch.Type != "test" {
+		currentMatch = newPublicMatch(web.arena.CurrentMatch)
+	}
+	data := struct {
+		*model.EventSettings
+		CurrentMatch *PublicMatch
+	}{web.arena.EventSettings, currentMatch}
+	web.renderPublicPage(w, "templates/public_index.html", data)
+}
+
+// Shows the schedule and results for the given match type.
+func (web *Web) publicScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	matchType := mux.Vars(r)["type"]
+	matchTypeName, ok := publicMatchTypes[matchType]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Error: Invalid match type '%s'.", matchType), 404)
+		return
+	}
+	matches, err := web.getPublicMatches(matchType, 0)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		MatchType     string
+		MatchTypeName string
+		Matches       []PublicMatch
+	}{web.arena.EventSettings, matchType, matchTypeName, matches}
+	web.renderPublicPage(w, "templates/public_schedule.html", data)
+}
+
+// Shows the current qualification rankings.
+func (web *Web) publicRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	rankings, err := web.arena.Database.GetAllRankings()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	teamNicknames := make(map[int]string)
+	for _, team := range teams {
+		teamNicknames[team.Id] = team.Nickname
+	}
+	rankingsWithNicknames := make([]RankingWithNickname, len(rankings))
+	for i, ranking := range rankings {
+		rankingsWithNicknames[i] = RankingWithNickname{ranking, teamNicknames[ranking.TeamId]}
+	}
+	data := struct {
+		*model.EventSettings
+		Rankings []RankingWithNickname
+	}{web.arena.EventSettings, rankingsWithNicknames}
+	web.renderPublicPage(w, "templates/public_rankings.html", data)
+}
+
+// Shows the playoff bracket.
+func (web *Web) publicBracketHandler(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		*model.EventSettings
+	}{web.arena.EventSettings}
+	web.renderPublicPage(w, "templates/public_bracket.html", data)
+}
+
+// Shows the list of teams at the event.
+func (web *Web) publicTeamsHandler(w http.ResponseWriter, r *http.Request) {
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Teams []model.Team
+	}{web.arena.EventSettings, teams}
+	web.renderPublicPage(w, "templates/public_teams.html", data)
+}
+
+// Shows the details, ranking and matches of a single team.
+func (web *Web) publicTeamHandler(w http.ResponseWriter, r *http.Request) {
+	teamId, _ := strconv.Atoi(mux.Vars(r)["teamId"])
+	team, err := web.arena.Database.GetTeamById(teamId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if team == nil {
+		http.Error(w, fmt.Sprintf("Error: No such team: %d", teamId), 404)
+		return
+	}
+	ranking, err := web.arena.Database.GetRankingForTeam(teamId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	var matches []PublicMatch
+	for _, matchType := range []string{"practice", "qualification", "elimination"} {
+		typeMatches, err := web.getPublicMatches(matchType, teamId)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		matches = append(matches, typeMatches...)
+	}
+
+	data := struct {
+		*model.EventSettings
+		Team    *model.Team
+		Ranking *game.Ranking
+		Matches []PublicMatch
+	}{web.arena.EventSettings, team, ranking, matches}
+	web.renderPublicPage(w, "templates/public_team.html", data)
+}
+
+// The websocket endpoint for the public portal to receive live match updates. It deliberately doesn't subscribe to
+// the full match load notifier, since that includes sensitive team details.
+func (web *Web) publicWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	ws, err := websocket.NewWebsocket(w, r)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer ws.Close()
+
+	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
+	ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.PublicMatchLoadNotifier, web.arena.MatchTimeNotifier,
+		web.arena.RealtimeScoreNotifier, web.arena.ScorePostedNotifier)
+}
+
+// Returns the matches of the given type along with their scores, filtered to those involving the given team if it is
+// non-zero.
+func (web *Web) getPublicMatches(matchType string, teamId int) ([]PublicMatch, error) {
+	matches, err := web.arena.Database.GetMatchesByType(matchType)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicMatches []PublicMatch
+	for _, match := range matches {
+		if teamId != 0 && getTeamStation(&match, teamId) == "" {
+			continue
+		}
+		publicMatch := newPublicMatch(&match)
+		if match.IsComplete() {
+			matchResult, err := web.arena.Database.GetMatchResultForMatch(match.Id)
+			if err != nil {
+				return nil, err
+			}
+			if matchResult != nil {
+				publicMatch.RedScore = matchResult.RedScoreSummary().Score
+				publicMatch.BlueScore = matchResult.BlueScoreSummary().Score
+			}
+		}
+		publicMatches = append(publicMatches, *publicMatch)
+	}
+	return publicMatches, nil
+}
+
+func newPublicMatch(match *model.Match) *PublicMatch {
+	return &PublicMatch{
+		Match:       *match,
+		MatchName:   match.TypePrefix() + match.DisplayName,
+		RedTeamIds:  []int{match.Red1, match.Red2, match.Red3},
+		BlueTeamIds: []int{match.Blue1, match.Blue2, match.Blue3},
+	}
+}
+
+// Renders the given public page template inside the public base layout.
+func (web *Web) renderPublicPage(w http.ResponseWriter, templateFile string, data interface{}) {
+	template, err := web.parseFiles(templateFile, "templates/public_base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	err = template.ExecuteTemplate(w, "public_base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/websocket"
+	gorillawebsocket "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicPages(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.AdminPassword = "secret"
+
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, Nickname: "The Cheesy Poofs", City: "San Jose",
+		WpaKey: "supersecretkey", ContactEmail: "queue@team254.com"})
+	web.arena.Database.CreateTeam(&model.Team{Id: 1114, Nickname: "Simbotics"})
+	match := model.Match{Type: "qualification", DisplayName: "1", Red1: 254, Blue1: 1114, Status: game.RedWonMatch}
+	web.arena.Database.CreateMatch(&match)
+	matchResult := model.NewMatchResult()
+	matchResult.MatchId = match.Id
+	matchResult.RedScore = &game.Score{AutoPoints: 12, TeleopPoints: 30}
+	matchResult.BlueScore = &game.Score{TeleopPoints: 17}
+	web.arena.Database.CreateMatchResult(matchResult)
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2", Red1: 1114, Blue2: 254})
+	web.arena.Database.CreateRanking(&game.Ranking{TeamId: 254, Rank: 1,
+		RankingFields: game.RankingFields{RankingPoints: 2, Wins: 1, Played: 1}})
+
+	for path, expectedContents := range map[string][]string{
+		"/public":                        {"No match is currently on the field", "/static/js/public.js"},
+		"/public/schedule/qualification": {"Qualification Schedule", "Q1", "Q2", "42", "17", "/public/teams/254"},
+		"/public/schedule/practice":      {"There is no Practice schedule yet"},
+		"/public/rankings":               {"The Cheesy Poofs", "1-0-0", "/public/teams/254"},
+		"/public/bracket":                {"/public/bracket/svg"},
+		"/public/teams":                  {"The Cheesy Poofs", "Simbotics", "San Jose"},
+		"/public/teams/254":              {"Team 254", "The Cheesy Poofs", "1-0-0", "Q1", "Q2"},
+	} {
+		recorder := web.getHttpResponse(path)
+		assert.Equal(t, 200, recorder.Code, path)
+		body := recorder.Body.String()
+		for _, expectedContent := range expectedContents {
+			assert.Contains(t, body, expectedContent, path)
+		}
+		assert.NotContains(t, body, "supersecretkey", path)
+		assert.NotContains(t, body, "queue@team254.com", path)
+
+		// Check that the page doesn't link anywhere outside of the public portal.
+		for _, link := range regexp.MustCompile(`(?:href|src)="(/[^"]*)"`).FindAllStringSubmatch(body, -1) {
+			assert.True(t, strings.HasPrefix(link[1], "/public") || strings.HasPrefix(link[1], "/static/"),
+				"%s links to %s", path, link[1])
+		}
+	}
+
+	recorder := web.getHttpResponse("/public/schedule/test")
+	assert.Equal(t, 404, recorder.Code)
+	recorder = web.getHttpResponse("/public/teams/9999")
+	assert.Equal(t, 404, recorder.Code)
+
+	// Check the live match view once a match is loaded.
+	assert.Nil(t, web.arena.LoadMatch(&match))
+	recorder = web.getHttpResponse("/public")
+	assert.Contains(t, recorder.Body.String(), "<span id=\"matchName\">Q1</span>")
+	assert.Contains(t, recorder.Body.String(), "254")
+}
+
+func TestPublicHandler(t *testing.T) {
+	web := setupTestWeb(t)
+	handler := web.newPublicHandler()
+	getResponse := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := getResponse("/")
+	assert.Equal(t, 302, recorder.Code)
+	assert.Equal(t, "/public", recorder.Header().Get("Location"))
+	assert.Equal(t, 200, getResponse("/public").Code)
+	assert.Equal(t, 200, getResponse("/public/rankings").Code)
+
+	// None of the admin or API routes should be reachable.
+	for _, path := range []string{"/setup/settings", "/match_play", "/login", "/api/scores", "/reports/csv/wpa_keys",
+		"/api/arena/websocket", "/displays/audience"} {
+		assert.Equal(t, 404, getResponse(path).Code, path)
+	}
+}
+
+func TestPublicWebsocket(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, WpaKey: "supersecretkey"})
+	match := model.Match{Type: "qualification", DisplayName: "7", Red1: 254}
+	web.arena.Database.CreateMatch(&match)
+	assert.Nil(t, web.arena.LoadMatch(&match))
+
+	server, wsUrl := web.startTestServer()
+	defer server.Close()
+	conn, _, err := gorillawebsocket.DefaultDialer.Dial(wsUrl+"/public/websocket", nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	ws := websocket.NewTestWebsocket(conn)
+
+	// Should get a few status updates right after connection.
+	readWebsocketType(t, ws, "matchTiming")
+	matchLoad := readWebsocketType(t, ws, "matchLoad")
+	readWebsocketType(t, ws, "matchTime")
+	readWebsocketType(t, ws, "realtimeScore")
+	readWebsocketType(t, ws, "scorePosted")
+
+	// The match load message shouldn't include the team details.
+	if assert.NotNil(t, matchLoad) {
+		message := matchLoad.(map[string]interface{})
+		assert.Equal(t, "Q7", message["MatchName"])
+		assert.NotContains(t, message, "Teams")
+	}
+}
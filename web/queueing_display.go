@@ -21,18 +21,20 @@ const (
 
 // Renders the queueing display that shows upcoming matches and timing information.
 func (web *Web) queueingDisplayHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.enforceDisplayConfiguration(w, r, nil) {
+	if !web.enforceDisplayConfiguration(w, r, web.withFieldDefault(nil)) {
 		return
 	}
 
-	matches, err := web.arena.Database.GetMatchesByType(web.arena.CurrentMatch.Type)
+	arena := web.arenaForRequest(r)
+
+	matches, err := arena.Database.GetMatchesByType(arena.CurrentMatch.Type)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
 
 	numMatchesToShow := numNonElimMatchesToShow
-	if web.arena.CurrentMatch.Type == "elimination" {
+	if arena.CurrentMatch.Type == "elimination" {
 		numMatchesToShow = numElimMatchesToShow
 	}
 
@@ -43,11 +45,11 @@ func (web *Web) queueingDisplayHandler(w http.ResponseWriter, r *http.Request) {
 	//	return
 	//}
 	for i, match := range matches {
-		if match.IsComplete() {
+		if match.IsComplete() || !match.IsOnField(arena.FieldId) {
 			continue
 		}
 		upcomingMatches = append(upcomingMatches, match)
-		redOffFieldTeams, blueOffFieldTeams, err := web.arena.Database.GetOffFieldTeamIds(&match)
+		redOffFieldTeams, blueOffFieldTeams, err := arena.Database.GetOffFieldTeamIds(&match)
 		if err != nil {
 			handleWebErr(w, err)
 			return
@@ -78,8 +80,8 @@ func (web *Web) queueingDisplayHandler(w http.ResponseWriter, r *http.Request) {
 		RedOffFieldTeams  [][]int
 		BlueOffFieldTeams [][]int
 	}{
-		web.arena.EventSettings,
-		web.arena.CurrentMatch.TypePrefix(),
+		arena.EventSettings,
+		arena.CurrentMatch.TypePrefix(),
 		upcomingMatches,
 		redOffFieldTeamsByMatch,
 		blueOffFieldTeamsByMatch,
@@ -93,6 +95,8 @@ func (web *Web) queueingDisplayHandler(w http.ResponseWriter, r *http.Request) {
 
 // The websocket endpoint for the queueing display to receive updates.
 func (web *Web) queueingDisplayWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	arena := web.arenaForRequest(r)
+
 	display, err := web.registerDisplay(r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -108,6 +112,6 @@ func (web *Web) queueingDisplayWebsocketHandler(w http.ResponseWriter, r *http.R
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client.
-	ws.HandleNotifiers(display.Notifier, web.arena.MatchTimingNotifier, web.arena.MatchLoadNotifier,
-		web.arena.MatchTimeNotifier, web.arena.EventStatusNotifier, web.arena.ReloadDisplaysNotifier)
+	ws.HandleNotifiers(display.Notifier, arena.MatchTimingNotifier, arena.MatchLoadNotifier,
+		arena.MatchTimeNotifier, arena.EventStatusNotifier, arena.ReloadDisplaysNotifier)
 }
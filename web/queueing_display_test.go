@@ -6,6 +6,7 @@ package web
 import (
 	"testing"
 
+	"github.com/BotDogs4645/da/model"
 	"github.com/BotDogs4645/da/websocket"
 	gorillawebsocket "github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
@@ -19,6 +20,27 @@ func TestQueueingDisplay(t *testing.T) {
 	assert.Contains(t, recorder.Body.String(), "Queueing Display - Untitled Event - Cheesy Arena")
 }
 
+func TestQueueingDisplayMultipleFields(t *testing.T) {
+	web := setupTestWebWithFields(t, 2)
+
+	// Check that the display is configured to follow the first field by default.
+	recorder := web.getHttpResponse("/displays/queueing")
+	assert.Equal(t, 302, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Location"), "field=1")
+
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1", FieldId: 1})
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "2", FieldId: 2})
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "3", FieldId: 1})
+	for _, arena := range web.arena.FieldArenas() {
+		arena.CurrentMatch = &model.Match{Type: "qualification"}
+	}
+	recorder = web.getHttpResponse("/displays/queueing?displayId=1&field=2")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Q2")
+	assert.NotContains(t, recorder.Body.String(), "Q1")
+	assert.NotContains(t, recorder.Body.String(), "Q3")
+}
+
 func TestQueueingDisplayWebsocket(t *testing.T) {
 	web := setupTestWeb(t)
 
@@ -7,6 +7,7 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -119,7 +120,7 @@ func (web *Web) findBackupTeams(rankings game.Rankings) (game.Rankings, map[int]
 	for _, alliance := range alliances {
 		for i, allianceTeamId := range alliance.TeamIds {
 			// Teams in third in an alliance are backups at events that use 3 team alliances.
-			if i == 3 {
+			if i == 3 || alliance.IsBackupTeam(allianceTeamId) {
 				pickedBackups[allianceTeamId] = true
 				continue
 			}
@@ -484,23 +485,30 @@ func (web *Web) schedulePdfReportHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// Generates a CSV-formatted report of the team list.
+// Generates a report of the team list in the same CSV (or JSON, if requested) roster format that can be imported on
+// the team list page.
 func (web *Web) teamsCsvReportHandler(w http.ResponseWriter, r *http.Request) {
 	teams, err := web.arena.Database.GetAllTeams()
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
+	roster := make([]rosterTeam, 0, len(teams))
+	for _, team := range teams {
+		roster = append(roster, newRosterTeam(&team))
+	}
 
-	// Don't set the content type as "text/csv", as that will trigger an automatic download in the browser.
-	w.Header().Set("Content-Type", "text/plain")
-	template, err := web.parseFiles("templates/teams.csv")
-	if err != nil {
-		handleWebErr(w, err)
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(roster); err != nil {
+			handleWebErr(w, err)
+		}
 		return
 	}
-	err = template.ExecuteTemplate(w, "teams.csv", teams)
-	if err != nil {
+
+	// Don't set the content type as "text/csv", as that will trigger an automatic download in the browser.
+	w.Header().Set("Content-Type", "text/plain")
+	if err = writeRosterCsv(w, roster); err != nil {
 		handleWebErr(w, err)
 		return
 	}
@@ -569,9 +577,62 @@ func (web *Web) teamsPdfReportHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Generates a PDF-formatted report of each team's check-in and robot inspection status.
+func (web *Web) inspectionPdfReportHandler(w http.ResponseWriter, r *http.Request) {
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// The widths of the table columns in mm, stored here so that they can be referenced for each row.
+	colWidths := map[string]float64{"Id": 12, "Name": 50, "CheckedIn": 20, "Weight": 20, "Status": 40, "Notes": 53}
+	rowHeight := 6.5
+
+	pdf := gofpdf.New("P", "mm", "Letter", "font")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(220, 220, 220)
+
+	// Render table header row.
+	pdf.CellFormat(195, rowHeight, "Inspection Status - "+web.arena.EventSettings.Name, "", 1, "C", false, 0, "")
+	pdf.CellFormat(colWidths["Id"], rowHeight, "Team", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths["Name"], rowHeight, "Name", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths["CheckedIn"], rowHeight, "Checked In", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths["Weight"], rowHeight, "Weight (lb)", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths["Status"], rowHeight, "Inspection", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(colWidths["Notes"], rowHeight, "Notes", "1", 1, "C", true, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	for _, team := range teams {
+		var checkedIn, weight string
+		if team.CheckedIn {
+			checkedIn = "Yes"
+		}
+		if team.RobotWeightLb > 0 {
+			weight = strconv.FormatFloat(team.RobotWeightLb, 'f', 1, 64)
+		}
+
+		// Render team info row.
+		pdf.CellFormat(colWidths["Id"], rowHeight, strconv.Itoa(team.Id), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths["Name"], rowHeight, team.Nickname, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths["CheckedIn"], rowHeight, checkedIn, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths["Weight"], rowHeight, weight, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths["Status"], rowHeight, team.InspectionStatusName(), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths["Notes"], rowHeight, team.InspectionNotes, "1", 1, "L", false, 0, "")
+	}
+
+	// Write out the PDF file as the HTTP response.
+	w.Header().Set("Content-Type", "application/pdf")
+	err = pdf.Output(w)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
 // Generates a CSV-formatted report of the WPA keys, for import into the radio kiosk.
 func (web *Web) wpaKeysCsvReportHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -663,7 +724,11 @@ func (web *Web) alliancesPdfReportHandler(w http.ResponseWriter, r *http.Request
 			pdf.SetX(xStart + colWidths["Alliance"])
 			team := teamsMap[teamId]
 			pdf.CellFormat(colWidths["Id"], rowHeight, strconv.Itoa(team.Id), "1", 0, "L", false, 0, "")
-			pdf.CellFormat(colWidths["Name"], rowHeight, team.Nickname, "1", 0, "L", false, 0, "")
+			name := team.Nickname
+			if alliance.IsBackupTeam(teamId) {
+				name = fmt.Sprintf("%s (backup for %d)", name, alliance.Backup.ReplacedTeamId)
+			}
+			pdf.CellFormat(colWidths["Name"], rowHeight, name, "1", 0, "L", false, 0, "")
 			location := fmt.Sprintf("%s, %s, %s", team.City, team.StateProv, team.Country)
 			pdf.CellFormat(colWidths["Location"], rowHeight, location, "1", 1, "L", false, 0, "")
 		}
@@ -97,10 +97,15 @@ func TestTeamsCsvReport(t *testing.T) {
 	recorder := web.getHttpResponse("/reports/csv/teams")
 	assert.Equal(t, 200, recorder.Code)
 	assert.Equal(t, "text/plain", recorder.Header()["Content-Type"][0])
-	expectedBody := "Number,Name,Nickname,City,StateProv,Country,RookieYear,RobotName,HasConnected\n254,\"NASA\"," +
-		"\"The Cheesy Poofs\",\"San Jose\",\"CA\",\"USA\",1999,\"Barrage\",false\n1114,\"GM\",\"Simbotics\"," +
-		"\"St. Catharines\",\"ON\",\"Canada\",2003,\"Simbot Evolution\",false\n\n"
+	expectedBody := "Number,Name,Nickname,City,StateProv,Country,RookieYear,RobotName,Accomplishments,Avatar\n" +
+		"254,NASA,The Cheesy Poofs,San Jose,CA,USA,1999,Barrage,,\n" +
+		"1114,GM,Simbotics,St. Catharines,ON,Canada,2003,Simbot Evolution,,\n"
 	assert.Equal(t, expectedBody, recorder.Body.String())
+
+	recorder = web.getHttpResponse("/reports/csv/teams?format=json")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header()["Content-Type"][0])
+	assert.Contains(t, recorder.Body.String(), "\"Nickname\":\"Simbotics\"")
 }
 
 func TestTeamsPdfReport(t *testing.T) {
@@ -116,6 +121,19 @@ func TestTeamsPdfReport(t *testing.T) {
 	assert.Equal(t, "application/pdf", recorder.Header()["Content-Type"][0])
 }
 
+func TestInspectionPdfReport(t *testing.T) {
+	web := setupTestWeb(t)
+
+	team := model.Team{Id: 254, Nickname: "The Cheesy Poofs", CheckedIn: true, RobotWeightLb: 118.5,
+		Inspection: model.InspectionFailed, InspectionNotes: "Bumpers too low"}
+	web.arena.Database.CreateTeam(&team)
+
+	// Can't really parse the PDF content and check it, so just check that what's sent back is a PDF.
+	recorder := web.getHttpResponse("/reports/pdf/inspection")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/pdf", recorder.Header()["Content-Type"][0])
+}
+
 func TestWpaKeysCsvReport(t *testing.T) {
 	web := setupTestWeb(t)
 
@@ -14,21 +14,23 @@ import (
 )
 
 func (web *Web) scoringPanelHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleScorer) {
 		return
 	}
 
-	practiceMatches, err := web.buildMatchPlayList("practice")
+	arena := web.arenaForRequest(r)
+
+	practiceMatches, err := web.buildMatchPlayList(arena, "practice")
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	qualificationMatches, err := web.buildMatchPlayList("qualification")
+	qualificationMatches, err := web.buildMatchPlayList(arena, "qualification")
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	eliminationMatches, err := web.buildMatchPlayList("elimination")
+	eliminationMatches, err := web.buildMatchPlayList(arena, "elimination")
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -41,16 +43,16 @@ func (web *Web) scoringPanelHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	matchesByType := map[string]MatchPlayList{"practice": practiceMatches,
 		"qualification": qualificationMatches, "elimination": eliminationMatches}
-	currentMatchType := web.arena.CurrentMatch.Type
+	currentMatchType := arena.CurrentMatch.Type
 	if currentMatchType == "test" {
 		currentMatchType = "practice"
 	}
-	redOffFieldTeams, blueOffFieldTeams, err := web.arena.Database.GetOffFieldTeamIds(web.arena.CurrentMatch)
+	redOffFieldTeams, blueOffFieldTeams, err := arena.Database.GetOffFieldTeamIds(arena.CurrentMatch)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	matchResult, err := web.arena.Database.GetMatchResultForMatch(web.arena.CurrentMatch.Id)
+	matchResult, err := arena.Database.GetMatchResultForMatch(arena.CurrentMatch.Id)
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -72,20 +74,20 @@ func (web *Web) scoringPanelHandler(w http.ResponseWriter, r *http.Request) {
 		SavedMatch            *model.Match
 		PlcArmorBlockStatuses map[string]bool
 	}{
-		web.arena.EventSettings,
-		web.arena.Plc.IsEnabled(),
+		arena.EventSettings,
+		arena.Plc.IsEnabled(),
 		matchesByType,
 		currentMatchType,
-		web.arena.CurrentMatch,
+		arena.CurrentMatch,
 		redOffFieldTeams,
 		blueOffFieldTeams,
-		web.arena.RedScore,
-		web.arena.BlueScore,
-		web.arena.CurrentMatch.ShouldAllowSubstitution(),
+		arena.RedScore,
+		arena.BlueScore,
+		arena.CurrentMatch.ShouldAllowSubstitution(),
 		isReplay,
-		web.arena.SavedMatch.CapitalizedType(),
-		web.arena.SavedMatch,
-		web.arena.Plc.GetArmorBlockStatuses(),
+		arena.SavedMatch.CapitalizedType(),
+		arena.SavedMatch,
+		arena.Plc.GetArmorBlockStatuses(),
 	}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
@@ -95,10 +97,12 @@ func (web *Web) scoringPanelHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleScorer) {
 		return
 	}
 
+	arena := web.arenaForRequest(r)
+
 	ws, err := websocket.NewWebsocket(w, r)
 	if err != nil {
 		handleWebErr(w, err)
@@ -107,9 +111,9 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 	defer ws.Close()
 
 	// Subscribe the websocket to the notifiers whose messages will be passed on to the client, in a separate goroutine.
-	go ws.HandleNotifiers(web.arena.MatchTimingNotifier, web.arena.ArenaStatusNotifier, web.arena.MatchTimeNotifier,
-		web.arena.RealtimeScoreNotifier, web.arena.AudienceDisplayModeNotifier,
-		web.arena.AllianceStationDisplayModeNotifier, web.arena.EventStatusNotifier)
+	go ws.HandleNotifiers(arena.MatchTimingNotifier, arena.ArenaStatusNotifier, arena.MatchTimeNotifier,
+		arena.RealtimeScoreNotifier, arena.AudienceDisplayModeNotifier,
+		arena.AllianceStationDisplayModeNotifier, arena.EventStatusNotifier)
 
 	// Loop, waiting for commands and responding to them, until the client closes the connection.
 	for {
@@ -123,6 +127,10 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
+		if !web.websocketUserHasRole(ws, r, model.RoleScorer) {
+			return
+		}
+
 		switch messageType {
 		case "substituteTeam":
 			args := struct {
@@ -134,7 +142,7 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.SubstituteTeam(args.Team, args.Position)
+			err = arena.SubstituteTeam(args.Team, args.Position)
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
@@ -145,11 +153,11 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			if _, ok := web.arena.AllianceStations[station]; !ok {
+			if _, ok := arena.AllianceStations[station]; !ok {
 				ws.WriteError(fmt.Sprintf("Invalid alliance station '%s'.", station))
 				continue
 			}
-			web.arena.AllianceStations[station].Bypass = !web.arena.AllianceStations[station].Bypass
+			arena.AllianceStations[station].Bypass = !arena.AllianceStations[station].Bypass
 		case "startMatch":
 			args := struct {
 				MuteMatchSounds bool
@@ -159,68 +167,68 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(err.Error())
 				continue
 			}
-			web.arena.MuteMatchSounds = args.MuteMatchSounds
-			err = web.arena.StartMatch()
+			arena.MuteMatchSounds = args.MuteMatchSounds
+			err = arena.StartMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
 		case "abortMatch":
-			err = web.arena.AbortMatch()
+			err = arena.AbortMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
 		case "signalVolunteers":
-			if web.arena.MatchState != field.PostMatch {
+			if arena.MatchState != field.PostMatch {
 				// Don't allow clearing the field until the match is over.
 				continue
 			}
-			web.arena.FieldVolunteers = true
+			arena.FieldVolunteers = true
 			continue // Don't reload.
 		case "signalReset":
-			if web.arena.MatchState != field.PostMatch {
+			if arena.MatchState != field.PostMatch {
 				// Don't allow clearing the field until the match is over.
 				continue
 			}
-			web.arena.FieldReset = true
-			web.arena.AllianceStationDisplayMode = "fieldReset"
-			web.arena.AllianceStationDisplayModeNotifier.Notify()
+			arena.FieldReset = true
+			arena.AllianceStationDisplayMode = "fieldReset"
+			arena.AllianceStationDisplayModeNotifier.Notify()
 			continue // Don't reload.
 		case "commitResults":
-			err = web.commitCurrentMatchScore()
+			err = web.commitCurrentMatchScore(arena)
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.ResetMatch()
+			err = arena.ResetMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.LoadNextMatch()
+			err = arena.LoadNextMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = ws.WriteNotifier(web.arena.ReloadDisplaysNotifier)
+			err = ws.WriteNotifier(arena.ReloadDisplaysNotifier)
 			if err != nil {
 				log.Println(err)
 				return
 			}
 			continue // Skip sending the status update, as the client is about to terminate and reload.
 		case "discardResults":
-			err = web.arena.ResetMatch()
+			err = arena.ResetMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = web.arena.LoadNextMatch()
+			err = arena.LoadNextMatch()
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
-			err = ws.WriteNotifier(web.arena.ReloadDisplaysNotifier)
+			err = ws.WriteNotifier(arena.ReloadDisplaysNotifier)
 			if err != nil {
 				log.Println(err)
 				return
@@ -232,7 +240,7 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			web.arena.SetAudienceDisplayMode(mode)
+			arena.SetAudienceDisplayMode(mode)
 			continue
 		case "setAllianceStationDisplay":
 			mode, ok := data.(string)
@@ -240,7 +248,7 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			web.arena.SetAllianceStationDisplayMode(mode)
+			arena.SetAllianceStationDisplayMode(mode)
 			continue
 		case "startTimeout":
 			durationSec, ok := data.(float64)
@@ -248,13 +256,13 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			err = web.arena.StartTimeout(int(durationSec))
+			err = arena.StartTimeout(int(durationSec), "")
 			if err != nil {
 				ws.WriteError(err.Error())
 				continue
 			}
 		case "setTestMatchName":
-			if web.arena.CurrentMatch.Type != "test" {
+			if arena.CurrentMatch.Type != "test" {
 				// Don't allow changing the name of a non-test match.
 				continue
 			}
@@ -263,25 +271,25 @@ func (web *Web) scoringPanelWebSocketHandler(w http.ResponseWriter, r *http.Requ
 				ws.WriteError(fmt.Sprintf("Failed to parse '%s' message.", messageType))
 				continue
 			}
-			web.arena.CurrentMatch.DisplayName = name
-			web.arena.MatchLoadNotifier.Notify()
+			arena.CurrentMatch.DisplayName = name
+			arena.MatchLoadNotifier.Notify()
 			continue
 		case "updateRealtimeScore":
 			args := data.(map[string]interface{})
-			web.arena.BlueScore.AutoPoints = int(args["blueAuto"].(float64))
-			web.arena.RedScore.AutoPoints = int(args["redAuto"].(float64))
-			web.arena.BlueScore.TeleopPoints = int(args["blueTeleop"].(float64))
-			web.arena.RedScore.TeleopPoints = int(args["redTeleop"].(float64))
-			web.arena.BlueScore.EndgamePoints = int(args["blueEndgame"].(float64))
-			web.arena.RedScore.EndgamePoints = int(args["redEndgame"].(float64))
-			web.arena.RealtimeScoreNotifier.Notify()
+			arena.BlueScore.AutoPoints = int(args["blueAuto"].(float64))
+			arena.RedScore.AutoPoints = int(args["redAuto"].(float64))
+			arena.BlueScore.TeleopPoints = int(args["blueTeleop"].(float64))
+			arena.RedScore.TeleopPoints = int(args["redTeleop"].(float64))
+			arena.BlueScore.EndgamePoints = int(args["blueEndgame"].(float64))
+			arena.RedScore.EndgamePoints = int(args["redEndgame"].(float64))
+			arena.RealtimeScoreNotifier.Notify()
 		default:
 			ws.WriteError(fmt.Sprintf("Invalid message type '%s'.", messageType))
 			continue
 		}
 
 		// Send out the status again after handling the command, as it most likely changed as a result.
-		err = ws.WriteNotifier(web.arena.ArenaStatusNotifier)
+		err = ws.WriteNotifier(arena.ArenaStatusNotifier)
 		if err != nil {
 			log.Println(err)
 			return
@@ -0,0 +1,213 @@
+// Web routes for recording administrative actions in the audit log and reviewing them.
+
+package web
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BotDogs4645/da/field"
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+)
+
+const auditDateLayout = "2006-01-02"
+
+// The administrative actions that are recorded in the audit log.
+const (
+	auditBackupInvoke     = "backup_invoke"
+	auditDbClear          = "db_clear"
+	auditDbRepair         = "db_repair"
+	auditDbRestore        = "db_restore"
+	auditMatchAbort       = "match_abort"
+	auditMatchResultEdit  = "match_result_edit"
+	auditScheduleGenerate = "schedule_generate"
+	auditScheduleSave     = "schedule_save"
+	auditSessionRevoke    = "session_revoke"
+	auditSettingsUpdate   = "settings_update"
+	auditStationBypass    = "station_bypass"
+	auditTeamAdd          = "team_add"
+	auditTeamDelete       = "team_delete"
+	auditTeamEdit         = "team_edit"
+	auditTeamSubstitute   = "team_substitute"
+	auditTeamsClear       = "teams_clear"
+	auditTeamsImport      = "teams_import"
+	auditUserAdd          = "user_add"
+	auditUserDelete       = "user_delete"
+	auditUserEdit         = "user_edit"
+)
+
+var auditActions = []string{
+	auditBackupInvoke, auditDbClear, auditDbRepair, auditDbRestore, auditMatchAbort, auditMatchResultEdit,
+	auditScheduleGenerate, auditScheduleSave, auditSessionRevoke, auditSettingsUpdate, auditStationBypass,
+	auditTeamAdd, auditTeamDelete, auditTeamEdit, auditTeamSubstitute, auditTeamsClear, auditTeamsImport,
+	auditUserAdd, auditUserDelete, auditUserEdit,
+}
+
+// The scores of both alliances in a match, as recorded in the audit log when they are edited.
+type auditedScores struct {
+	RedScore  game.Score
+	BlueScore game.Score
+}
+
+// Shows the audit log, filtered according to the query parameters.
+func (web *Web) auditGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	filter, err := getAuditFilter(r)
+	if err != nil {
+		web.renderAudit(w, r, nil, err.Error())
+		return
+	}
+	entries, err := web.arena.Database.GetAuditEntries(filter)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	web.renderAudit(w, r, entries, "")
+}
+
+// Generates a CSV-formatted export of the audit log, filtered according to the query parameters.
+func (web *Web) auditCsvHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	filter, err := getAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	entries, err := web.arena.Database.GetAuditEntries(filter)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit_log.csv")
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	writer.Write([]string{
+		"Id", "Timestamp", "Username", "Session", "RemoteAddress", "Action", "Target", "Before", "After",
+	})
+	for _, entry := range entries {
+		writer.Write([]string{
+			strconv.Itoa(entry.Id), entry.Timestamp.Format(time.RFC3339), entry.Username, entry.Session,
+			entry.RemoteAddress, entry.Action, entry.Target, entry.Before, entry.After,
+		})
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+func (web *Web) renderAudit(w http.ResponseWriter, r *http.Request, entries []model.AuditEntry, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_audit.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	query := r.URL.Query()
+	data := struct {
+		*model.EventSettings
+		Actions      []string
+		Action       string
+		Username     string
+		Start        string
+		End          string
+		Entries      []model.AuditEntry
+		ErrorMessage string
+	}{
+		web.arena.EventSettings,
+		auditActions,
+		query.Get("action"),
+		query.Get("username"),
+		query.Get("start"),
+		query.Get("end"),
+		entries,
+		errorMessage,
+	}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Builds the audit log filter given by the query parameters, in which the start and end are dates in local time and
+// both are inclusive.
+func getAuditFilter(r *http.Request) (model.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := model.AuditFilter{Action: query.Get("action"), Username: query.Get("username")}
+	if start := query.Get("start"); start != "" {
+		startDate, err := time.ParseInLocation(auditDateLayout, start, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid start date '%s'", start)
+		}
+		filter.Start = startDate
+	}
+	if end := query.Get("end"); end != "" {
+		endDate, err := time.ParseInLocation(auditDateLayout, end, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid end date '%s'", end)
+		}
+		filter.End = endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return filter, nil
+}
+
+// Records the given administrative action in the audit log, along with the values of what it changed before and after.
+// Failures are logged rather than returned, since the action itself has already taken effect by this point.
+func (web *Web) audit(r *http.Request, action, target string, before, after any) {
+	web.writeAuditEntry(web.newAuditEntry(r, action, target), before, after)
+}
+
+// Returns an audit log entry for the given action identifying who made the given request, to be written later in the
+// case of an action that replaces the database holding the session.
+func (web *Web) newAuditEntry(r *http.Request, action, target string) model.AuditEntry {
+	entry := model.AuditEntry{Timestamp: time.Now(), Action: action, Target: target}
+	if session := web.getUserSessionFromCookie(r); session != nil {
+		entry.Username = session.Username
+		entry.Session = fmt.Sprintf("session %d", session.Id)
+	}
+	entry.RemoteAddress = remoteAddress(r)
+	return entry
+}
+
+// Returns the address of the client that made the given request, without the port.
+func remoteAddress(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func (web *Web) writeAuditEntry(entry model.AuditEntry, before, after any) {
+	var err error
+	if entry.Before, entry.After, err = model.AuditValues(before, after); err == nil {
+		err = web.arena.Database.CreateAuditEntry(&entry)
+	}
+	if err != nil {
+		log.Printf("Failed to record '%s' action in the audit log: %v", entry.Action, err)
+	}
+}
+
+// Returns the description of the given alliance station in the current match on the given arena's field, for
+// identifying the target of match play actions in the audit log.
+func matchPlayAuditTarget(arena *field.Arena, station string) string {
+	target := fmt.Sprintf("Field %d match %s", arena.FieldId, arena.CurrentMatch.DisplayName)
+	if station != "" {
+		target += " station " + station
+	}
+	return target
+}
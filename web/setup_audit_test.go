@@ -0,0 +1,88 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupAudit(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.TBADownloadEnabled = false
+
+	recorder := web.getHttpResponse("/setup/audit")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "No matching actions have been recorded.")
+
+	web.postHttpResponse("/setup/teams", "teamNumbers=254")
+	recorder = web.postHttpResponse("/setup/teams/254/edit", "nickname=The Cheesy Poofs")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{})
+	if assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, auditTeamEdit, entries[0].Action)
+		assert.Equal(t, "Team 254", entries[0].Target)
+		assert.Equal(t, `{"Nickname":""}`, entries[0].Before)
+		assert.Equal(t, `{"Nickname":"The Cheesy Poofs"}`, entries[0].After)
+		assert.Equal(t, auditTeamAdd, entries[1].Action)
+	}
+
+	recorder = web.getHttpResponse("/setup/audit")
+	assert.Contains(t, recorder.Body.String(), "team_add")
+	assert.Contains(t, recorder.Body.String(), "The Cheesy Poofs")
+	recorder = web.getHttpResponse("/setup/audit?action=team_add")
+	assert.NotContains(t, recorder.Body.String(), "The Cheesy Poofs")
+	today := time.Now().Format(auditDateLayout)
+	recorder = web.getHttpResponse("/setup/audit?start=" + today + "&end=" + today)
+	assert.Contains(t, recorder.Body.String(), "The Cheesy Poofs")
+	recorder = web.getHttpResponse("/setup/audit?start=yesterday")
+	assert.Contains(t, recorder.Body.String(), "invalid start date")
+
+	recorder = web.getHttpResponse("/setup/audit/csv?action=team_edit")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Contains(
+		t, recorder.Body.String(), "Id,Timestamp,Username,Session,RemoteAddress,Action,Target,Before,After\r\n",
+	)
+	assert.Contains(
+		t, recorder.Body.String(), `team_edit,Team 254,"{""Nickname"":""""}","{""Nickname"":""The Cheesy Poofs""}"`,
+	)
+	assert.NotContains(t, recorder.Body.String(), "team_add")
+}
+
+func TestSetupAuditSettingsRedacted(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postHttpResponse("/setup/settings", "name=Chezy Champs&elimType=single&numElimAlliances=8"+
+		"&tbaSecret=supersecret")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{Action: auditSettingsUpdate})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Contains(t, entries[0].After, `"Name":"Chezy Champs"`)
+		assert.Contains(t, entries[0].After, `"TbaSecret":"[redacted]"`)
+		assert.NotContains(t, entries[0].After, "supersecret")
+	}
+}
+
+func TestSetupAuditRestoreDb(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.TBADownloadEnabled = false
+
+	recorder := web.getHttpResponse("/setup/db/save")
+	assert.Equal(t, 200, recorder.Code)
+	backupBody := recorder.Body
+	web.postHttpResponse("/setup/teams", "teamNumbers=254")
+
+	// Check that the restored database keeps the actions taken since the backup, plus the restore itself.
+	recorder = web.postFileHttpResponse("/setup/db/restore", "databaseFile", backupBody)
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.Nil(t, team)
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{})
+	if assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, auditDbRestore, entries[0].Action)
+		assert.Equal(t, "file.ext", entries[0].Target)
+		assert.Equal(t, auditTeamAdd, entries[1].Action)
+	}
+}
@@ -10,12 +10,13 @@ import (
 	"strconv"
 
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 	"github.com/BotDogs4645/da/tournament"
 )
 
 // Shows the awards configuration page.
 func (web *Web) awardsGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -52,7 +53,7 @@ func (web *Web) awardsGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // Saves the new or modified awards to the database.
 func (web *Web) awardsPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -77,11 +78,11 @@ func (web *Web) awardsPostHandler(w http.ResponseWriter, r *http.Request) {
 
 // Publishes the awards to the web.
 func (web *Web) awardsPublishHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
-	err := web.arena.TbaClient.PublishAwards(web.arena.Database)
+	err := web.arena.Publish(partner.AwardsResource)
 	if err != nil {
 		http.Error(w, "Failed to publish awards: "+err.Error(), 500)
 		return
@@ -0,0 +1,108 @@
+// Web routes for browsing the automatic backups of the event database and restoring them.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/gorilla/mux"
+)
+
+// Shows the list of backups, along with a preview of the contents of one of them if requested.
+func (web *Web) backupsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	web.renderBackups(w, r.URL.Query().Get("preview"), "")
+}
+
+// Sends a backup to the client as a download.
+func (web *Web) backupDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	filename := mux.Vars(r)["filename"]
+	path, err := model.BackupPath(filename)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	http.ServeFile(w, r, path)
+}
+
+// Replaces the current database with the given backup.
+func (web *Web) backupRestorePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	filename := mux.Vars(r)["filename"]
+	path, err := model.BackupPath(filename)
+	if err != nil {
+		web.renderBackups(w, "", fmt.Sprintf("Failed to restore backup: %v", err))
+		return
+	}
+	backupFile, err := os.Open(path)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer backupFile.Close()
+
+	// Restore from a copy so that the backup itself is kept.
+	tempFilePath, err := writeTempDatabase(backupFile)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	defer os.Remove(tempFilePath)
+	rejection, err := web.replaceDatabase(r, tempFilePath, filename, fmt.Sprintf("backup '%s'", filename))
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if rejection != "" {
+		web.renderBackups(w, "", rejection)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/backups", 303)
+}
+
+func (web *Web) renderBackups(w http.ResponseWriter, previewFilename, errorMessage string) {
+	backups, err := model.ListBackups()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	var preview *model.BackupSummary
+	if previewFilename != "" {
+		if preview, err = model.ReadBackupSummary(previewFilename); err != nil {
+			errorMessage = fmt.Sprintf("Failed to preview backup: %v", err)
+		}
+	}
+
+	template, err := web.parseFiles("templates/setup_backups.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Backups         []model.BackupInfo
+		PreviewFilename string
+		Preview         *model.BackupSummary
+		ErrorMessage    string
+	}{web.arena.EventSettings, backups, previewFilename, preview, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
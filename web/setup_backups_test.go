@@ -0,0 +1,100 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/BotDogs4645/da/field"
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupBackups(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.EventSettings.Name = "Backup Test"
+	assert.Nil(t, web.arena.Database.UpdateEventSettings(web.arena.EventSettings))
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+	assert.Nil(t, web.arena.Database.Backup(web.arena.EventSettings.Name, "post_test"))
+	var filename string
+	backups, _ := model.ListBackups()
+	for _, backup := range backups {
+		if backup.EventName == "Backup_Test" && backup.Reason == "post_test" {
+			filename = backup.Filename
+			break
+		}
+	}
+	assert.NotEqual(t, "", filename)
+
+	recorder := web.getHttpResponse("/setup/backups")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "/setup/backups/"+filename+"/restore")
+	assert.NotContains(t, recorder.Body.String(), "Matches played")
+	recorder = web.getHttpResponse("/setup/backups?preview=" + filename)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "<tr><td>Teams</td><td>1</td></tr>")
+	assert.Contains(t, recorder.Body.String(), "<tr><td>Matches played</td><td>0 of 0</td></tr>")
+	recorder = web.getHttpResponse("/setup/backups?preview=nonexistent")
+	assert.Contains(t, recorder.Body.String(), "Failed to preview backup")
+
+	recorder = web.getHttpResponse("/setup/backups/" + filename + "/download")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Disposition"), filename)
+	assert.Greater(t, recorder.Body.Len(), 0)
+	recorder = web.getHttpResponse("/setup/backups/nonexistent/download")
+	assert.Equal(t, 404, recorder.Code)
+
+	// Check that restoring the backup replaces the current database, other than the saved display configurations.
+	web.arena.Database.DeleteTeam(254)
+	web.arena.RegisterDisplay(&field.DisplayConfiguration{Id: "100", Nickname: "Pit TV", Type: field.PitDisplay,
+		Configuration: map[string]string{}}, "1.2.3.4")
+	recorder = web.postHttpResponse("/setup/backups/"+filename+"/restore", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.NotNil(t, team)
+	assert.Equal(t, "Backup Test", web.arena.EventSettings.Name)
+	savedDisplay, _ := web.arena.Database.GetDisplayByDisplayId("100")
+	if assert.NotNil(t, savedDisplay) {
+		assert.Equal(t, "Pit TV", savedDisplay.Nickname)
+	}
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{Action: auditDbRestore})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, filename, entries[0].Target)
+	}
+	_, err := model.BackupPath(filename)
+	assert.Nil(t, err)
+
+	recorder = web.postHttpResponse("/setup/backups/nonexistent/restore", "")
+	assert.Contains(t, recorder.Body.String(), "Failed to restore backup")
+}
+
+func TestSetupBackupsRestoreInconsistent(t *testing.T) {
+	web := setupTestWeb(t)
+
+	web.arena.EventSettings.Name = "Inconsistent Backup Test"
+	assert.Nil(t, web.arena.Database.UpdateEventSettings(web.arena.EventSettings))
+	web.arena.Database.CreateMatch(&model.Match{Type: "practice", DisplayName: "1", Status: game.RedWonMatch})
+	assert.Nil(t, web.arena.Database.Backup(web.arena.EventSettings.Name, "post_test"))
+	var filename string
+	backups, _ := model.ListBackups()
+	for _, backup := range backups {
+		if backup.EventName == "Inconsistent_Backup_Test" && backup.Reason == "post_test" {
+			filename = backup.Filename
+			break
+		}
+	}
+	assert.NotEqual(t, "", filename)
+	web.arena.Database.DeleteMatch(1)
+
+	// Check that an inconsistent backup is only restored if asked for.
+	recorder := web.postHttpResponse("/setup/backups/"+filename+"/restore", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "loaded since it has consistency problems")
+	assert.Contains(t, recorder.Body.String(), "Practice match 1 is marked as played but has no result.")
+	match, _ := web.arena.Database.GetMatchById(1)
+	assert.Nil(t, match)
+	recorder = web.postHttpResponse("/setup/backups/"+filename+"/restore", "ignoreProblems=on")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	match, _ = web.arena.Database.GetMatchById(1)
+	assert.NotNil(t, match)
+}
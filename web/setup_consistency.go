@@ -0,0 +1,89 @@
+// Web routes for checking the event database for inconsistencies and repairing them.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BotDogs4645/da/consistency"
+	"github.com/BotDogs4645/da/model"
+)
+
+// Shows the inconsistencies found in the event database.
+func (web *Web) consistencyGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	web.renderConsistency(w, "")
+}
+
+// Applies the automatic fix for the given problem, or for all fixable problems if none is given.
+func (web *Web) consistencyFixPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	// Back up the database.
+	err := web.arena.Database.Backup(web.arena.EventSettings.Name, "pre_repair")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	key := r.PostFormValue("key")
+	if key == "" {
+		var numFixes int
+		numFixes, err = consistency.FixAll(web.arena.Database)
+		web.audit(r, auditDbRepair, "all", nil, fmt.Sprintf("%d fixes", numFixes))
+	} else {
+		err = consistency.Fix(web.arena.Database, key)
+		if err == nil {
+			web.audit(r, auditDbRepair, key, nil, nil)
+		}
+	}
+	if err != nil {
+		web.renderConsistency(w, fmt.Sprintf("Failed to fix problem: %v", err))
+		return
+	}
+
+	// Rebuild the in-memory state derived from the repaired records.
+	cachedRankedTeams = []*RankedTeam{}
+	if err = web.syncPlayoffBrackets(nil); err != nil {
+		web.renderConsistency(w, fmt.Sprintf("Failed to update the playoff bracket: %v", err))
+		return
+	}
+
+	http.Redirect(w, r, "/setup/consistency", 303)
+}
+
+func (web *Web) renderConsistency(w http.ResponseWriter, errorMessage string) {
+	problems, err := consistency.Check(web.arena.Database)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	numFixable := 0
+	for _, problem := range problems {
+		if problem.FixDescription != "" {
+			numFixable++
+		}
+	}
+	template, err := web.parseFiles("templates/setup_consistency.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Problems     []consistency.Problem
+		NumFixable   int
+		ErrorMessage string
+	}{web.arena.EventSettings, problems, numFixable, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
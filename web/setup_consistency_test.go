@@ -0,0 +1,84 @@
+package web
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BotDogs4645/da/game"
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupConsistency(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/consistency")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "No problems found.")
+
+	match := model.Match{Type: "practice", DisplayName: "1", Status: game.RedWonMatch}
+	web.arena.Database.CreateMatch(&match)
+	recorder = web.getHttpResponse("/setup/consistency")
+	assert.Contains(t, recorder.Body.String(), "Practice match 1 is marked as played but has no result.")
+	assert.Contains(t, recorder.Body.String(), "value=\"missing_result_1\"")
+
+	recorder = web.postHttpResponse("/setup/consistency/fix", "key=missing_result_1")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	matchAfter, _ := web.arena.Database.GetMatchById(match.Id)
+	assert.False(t, matchAfter.IsComplete())
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{Action: auditDbRepair})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, "missing_result_1", entries[0].Target)
+	}
+	recorder = web.getHttpResponse("/setup/consistency")
+	assert.Contains(t, recorder.Body.String(), "No problems found.")
+
+	recorder = web.postHttpResponse("/setup/consistency/fix", "key=missing_result_1")
+	assert.Contains(
+		t, recorder.Body.String(), "Failed to fix problem: problem &#39;missing_result_1&#39; no longer exists",
+	)
+
+	web.arena.Database.CreateMatchResult(model.BuildTestMatchResult(99, 1))
+	web.arena.Database.CreateMatchResult(model.BuildTestMatchResult(100, 1))
+	recorder = web.postHttpResponse("/setup/consistency/fix", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	recorder = web.getHttpResponse("/setup/consistency")
+	assert.Contains(t, recorder.Body.String(), "No problems found.")
+}
+
+func TestSetupConsistencyRestoreDb(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.Name = "Chezy Champs"
+	assert.Nil(t, web.arena.Database.UpdateEventSettings(web.arena.EventSettings))
+	web.arena.Database.CreateMatch(&model.Match{Type: "practice", DisplayName: "1", Status: game.RedWonMatch})
+	backup := web.getHttpResponse("/setup/db/save").Body.Bytes()
+
+	web = setupTestWeb(t)
+	restoreDb := func(ignoreProblems bool) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("databaseFile", "file.ext")
+		part.Write(backup)
+		if ignoreProblems {
+			writer.WriteField("ignoreProblems", "on")
+		}
+		writer.Close()
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/setup/db/restore", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		web.newHandler().ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Check that an inconsistent database is only loaded if asked for.
+	recorder := restoreDb(false)
+	assert.Contains(t, recorder.Body.String(), "loaded since it has consistency problems")
+	assert.Contains(t, recorder.Body.String(), "Practice match 1 is marked as played but has no result.")
+	assert.NotEqual(t, "Chezy Champs", web.arena.EventSettings.Name)
+	recorder = restoreDb(true)
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "Chezy Champs", web.arena.EventSettings.Name)
+}
@@ -19,7 +19,7 @@ import (
 
 // Shows the displays configuration page.
 func (web *Web) displaysGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -41,7 +41,7 @@ func (web *Web) displaysGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // The websocket endpoint for the display configuration page to send control commands and receive status updates.
 func (web *Web) displaysWebsocketHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -67,6 +67,10 @@ func (web *Web) displaysWebsocketHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if !web.websocketUserHasRole(ws, r, model.RoleAdmin) {
+			return
+		}
+
 		switch messageType {
 		case "configureDisplay":
 			var displayConfig field.DisplayConfiguration
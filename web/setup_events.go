@@ -0,0 +1,143 @@
+// Web routes for managing the library of events on this installation and switching between them.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+	"github.com/gorilla/mux"
+)
+
+// Shows the event library page.
+func (web *Web) eventsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	web.renderEvents(w, "")
+}
+
+// Creates a new empty event in the library.
+func (web *Web) eventsCreatePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	if _, err := web.eventLibrary.CreateEvent(name); err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to create event: %v", err))
+		return
+	}
+	http.Redirect(w, r, "/setup/events", 303)
+}
+
+// Creates a new event in the library as a copy of the setup of an existing one.
+func (web *Web) eventsClonePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	if _, err := web.eventLibrary.CloneEvent(key, name, web.arena.Database); err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to clone event: %v", err))
+		return
+	}
+	http.Redirect(w, r, "/setup/events", 303)
+}
+
+// Creates a new event in the library from an uploaded event archive, such as one saved from another installation.
+func (web *Web) eventsImportPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("archiveFile")
+	if err != nil {
+		web.renderEvents(w, "No event archive file was specified.")
+		return
+	}
+	defer file.Close()
+	if _, err = web.eventLibrary.ImportArchive(file, fileHeader.Size, partner.AvatarsDir); err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to import event: %v", err))
+		return
+	}
+	http.Redirect(w, r, "/setup/events", 303)
+}
+
+// Moves an event in the library to the archive.
+func (web *Web) eventsArchivePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	if err := web.eventLibrary.ArchiveEvent(mux.Vars(r)["key"], web.arena.Database); err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to archive event: %v", err))
+		return
+	}
+	http.Redirect(w, r, "/setup/events", 303)
+}
+
+// Returns an archived event to the library.
+func (web *Web) eventsUnarchivePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	if err := web.eventLibrary.UnarchiveEvent(mux.Vars(r)["key"]); err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to unarchive event: %v", err))
+		return
+	}
+	http.Redirect(w, r, "/setup/events", 303)
+}
+
+// Closes the current event and opens the given one in its place, making it the one that is opened on startup.
+func (web *Web) eventsSwitchPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	dbPath, err := web.eventLibrary.EventPath(key)
+	if err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to switch event: %v", err))
+		return
+	}
+	if err = web.arena.SwitchDatabase(dbPath); err != nil {
+		web.renderEvents(w, fmt.Sprintf("Failed to switch event: %v", err))
+		return
+	}
+	cachedRankedTeams = []*RankedTeam{}
+	if err = web.eventLibrary.SetCurrentEvent(key); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	http.Redirect(w, r, "/setup/settings", 303)
+}
+
+func (web *Web) renderEvents(w http.ResponseWriter, errorMessage string) {
+	events, err := web.eventLibrary.ListEvents(web.arena.Database)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	template, err := web.parseFiles("templates/setup_events.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Events       []model.EventInfo
+		ErrorMessage string
+	}{web.arena.EventSettings, events, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BotDogs4645/da/field"
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupEvents(t *testing.T) {
+	web := setupTestWeb(t)
+	web.eventLibrary = model.NewEventLibrary(t.TempDir())
+
+	recorder := web.getHttpResponse("/setup/events")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Event Library")
+
+	recorder = web.postHttpResponse("/setup/events/create", "name=Week 1")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	recorder = web.postHttpResponse("/setup/events/week_1/clone", "name=Week 2")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	recorder = web.getHttpResponse("/setup/events")
+	assert.Contains(t, recorder.Body.String(), "Week 1")
+	assert.Contains(t, recorder.Body.String(), "/setup/events/week_2/switch")
+
+	// Check that switching events reopens the arena's database and reloads its settings.
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+	recorder = web.postHttpResponse("/setup/events/week_2/switch", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "Week 2", web.arena.EventSettings.Name)
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.Nil(t, team)
+	key, _ := web.eventLibrary.CurrentEventKey()
+	assert.Equal(t, "week_2", key)
+	recorder = web.getHttpResponse("/setup/events")
+	assert.Contains(t, recorder.Body.String(), "Week 2 <b>(current)</b>")
+
+	recorder = web.postHttpResponse("/setup/events/week_1/archive", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	recorder = web.getHttpResponse("/setup/events")
+	assert.Contains(t, recorder.Body.String(), "Week 1 (archived)")
+	recorder = web.postHttpResponse("/setup/events/week_1/unarchive", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+}
+
+func TestSetupEventsErrors(t *testing.T) {
+	web := setupTestWeb(t)
+	web.eventLibrary = model.NewEventLibrary(t.TempDir())
+
+	recorder := web.postHttpResponse("/setup/events/create", "name=")
+	assert.Contains(t, recorder.Body.String(), "Failed to create event")
+	recorder = web.postHttpResponse("/setup/events/nonexistent/switch", "")
+	assert.Contains(t, recorder.Body.String(), "Failed to switch event: event &#39;nonexistent&#39; doesn&#39;t exist")
+
+	web.postHttpResponse("/setup/events/create", "name=Week 1")
+	web.arena.MatchState = field.AutoPeriod
+	recorder = web.postHttpResponse("/setup/events/week_1/switch", "")
+	assert.Contains(t, recorder.Body.String(), "has a match still in progress")
+	web.arena.MatchState = field.PreMatch
+	recorder = web.postHttpResponse("/setup/events/week_1/switch", "")
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.postHttpResponse("/setup/events/week_1/archive", "")
+	assert.Contains(t, recorder.Body.String(), "can&#39;t archive the current event")
+}
+
+func TestSetupEventsImportArchive(t *testing.T) {
+	web := setupTestWeb(t)
+	web.eventLibrary = model.NewEventLibrary(t.TempDir())
+	web.arena.EventSettings.Name = "Chezy Champs"
+	web.arena.EventSettings.TbaSecret = "tbasecret"
+	assert.Nil(t, web.arena.Database.UpdateEventSettings(web.arena.EventSettings))
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1", Red1: 254})
+
+	recorder := web.getHttpResponse("/setup/db/archive")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Header().Get("Content-Disposition"), "Chezy_Champs-")
+	assert.NotContains(t, recorder.Body.String(), "tbasecret")
+
+	recorder = web.postFileHttpResponse("/setup/events/import", "archiveFile", recorder.Body)
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	path, err := web.eventLibrary.EventPath("chezy_champs")
+	assert.Nil(t, err)
+	database, err := model.OpenDatabase(path)
+	assert.Nil(t, err)
+	match, _ := database.GetMatchById(1)
+	if assert.NotNil(t, match) {
+		assert.Equal(t, 254, match.Red1)
+	}
+	eventSettings, _ := database.GetEventSettings()
+	assert.Equal(t, "", eventSettings.TbaSecret)
+	database.Close()
+
+	recorder = web.postFileHttpResponse("/setup/events/import", "archiveFile", bytes.NewBufferString("not a zip"))
+	assert.Contains(t, recorder.Body.String(), "Failed to import event: not a valid event archive")
+	recorder = web.postHttpResponse("/setup/events/import", "")
+	assert.Contains(t, recorder.Body.String(), "No event archive file was specified.")
+}
@@ -18,7 +18,7 @@ import (
 
 // Shows the Field Testing page.
 func (web *Web) fieldTestingGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta) {
 		return
 	}
 
@@ -44,7 +44,7 @@ func (web *Web) fieldTestingGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // The websocket endpoint for sending realtime updates to the Field Testing page.
 func (web *Web) fieldTestingWebsocketHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleFta) {
 		return
 	}
 
@@ -70,6 +70,10 @@ func (web *Web) fieldTestingWebsocketHandler(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
+		if !web.websocketUserHasRole(ws, r, model.RoleFta) {
+			return
+		}
+
 		switch messageType {
 		case "playSound":
 			sound, ok := data.(string)
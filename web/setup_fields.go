@@ -0,0 +1,138 @@
+// Web routes for configuring the number of fields at the event and the hardware of each additional field.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/network"
+)
+
+const maxNumFields = 4
+
+var apTeamChannels = []int{36, 40, 44, 48, 149, 153, 157, 161}
+
+// Shows the field configuration page.
+func (web *Web) fieldsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	web.renderFields(w, "")
+}
+
+// Saves the number of fields and the hardware configuration of each additional field.
+func (web *Web) fieldsPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	numFields, _ := strconv.Atoi(r.PostFormValue("numFields"))
+	if numFields < 1 || numFields > maxNumFields {
+		web.renderFields(w, fmt.Sprintf("Number of fields must be between 1 and %d.", maxNumFields))
+		return
+	}
+
+	var allFieldSettings []model.FieldSettings
+	dsListenAddresses := map[string]int{network.ServerIpAddress: 1}
+	for fieldId := 2; fieldId <= numFields; fieldId++ {
+		formValue := func(name string) string {
+			return strings.TrimSpace(r.PostFormValue(fmt.Sprintf("%s%d", name, fieldId)))
+		}
+		fieldSettings := model.FieldSettings{
+			Id:              fieldId,
+			DsListenAddress: formValue("dsListenAddress"),
+			ApAddress:       formValue("apAddress"),
+			ApUsername:      formValue("apUsername"),
+			ApPassword:      formValue("apPassword"),
+			Ap2Address:      formValue("ap2Address"),
+			Ap2Username:     formValue("ap2Username"),
+			Ap2Password:     formValue("ap2Password"),
+			SwitchAddress:   formValue("switchAddress"),
+			SwitchPassword:  formValue("switchPassword"),
+			PlcAddress:      formValue("plcAddress"),
+		}
+		fieldSettings.ApTeamChannel, _ = strconv.Atoi(formValue("apTeamChannel"))
+		fieldSettings.Ap2TeamChannel, _ = strconv.Atoi(formValue("ap2TeamChannel"))
+
+		if fieldSettings.DsListenAddress == "" {
+			web.renderFields(w, fmt.Sprintf("Field %d needs a driver station listen address.", fieldId))
+			return
+		}
+		if otherFieldId, ok := dsListenAddresses[fieldSettings.DsListenAddress]; ok {
+			web.renderFields(w, fmt.Sprintf("Field %d can't use the same driver station address as field %d.",
+				fieldId, otherFieldId))
+			return
+		}
+		dsListenAddresses[fieldSettings.DsListenAddress] = fieldId
+		if fieldSettings.Ap2TeamChannel != 0 && fieldSettings.Ap2TeamChannel == fieldSettings.ApTeamChannel {
+			web.renderFields(w, fmt.Sprintf("Cannot use same channel for both access points on field %d.", fieldId))
+			return
+		}
+		allFieldSettings = append(allFieldSettings, fieldSettings)
+	}
+
+	for _, fieldSettings := range allFieldSettings {
+		if err := web.arena.Database.SaveFieldSettings(&fieldSettings); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	}
+	web.arena.EventSettings.NumFields = numFields
+	if err := web.arena.Database.UpdateEventSettings(web.arena.EventSettings); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// Refresh the arenas in case any of their hardware settings changed.
+	if err := web.loadArenaSettings(); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/fields", 303)
+}
+
+func (web *Web) renderFields(w http.ResponseWriter, errorMessage string) {
+	var allFieldSettings []model.FieldSettings
+	for fieldId := 2; fieldId <= web.arena.EventSettings.NumFields; fieldId++ {
+		fieldSettings, err := web.arena.Database.GetFieldSettings(fieldId)
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		allFieldSettings = append(allFieldSettings, *fieldSettings)
+	}
+
+	template, err := web.parseFiles("templates/setup_fields.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		NumRunningFields int
+		MaxNumFields     int
+		ServerIpAddress  string
+		ApChannels       []int
+		FieldSettings    []model.FieldSettings
+		ErrorMessage     string
+	}{
+		web.arena.EventSettings,
+		len(web.arena.FieldArenas()),
+		maxNumFields,
+		network.ServerIpAddress,
+		apTeamChannels,
+		allFieldSettings,
+		errorMessage,
+	}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
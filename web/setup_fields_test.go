@@ -0,0 +1,50 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupFields(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/fields")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Number of Fields")
+	assert.NotContains(t, recorder.Body.String(), "Field 2")
+
+	// Check that adding a field saves its settings and flags that a restart is needed.
+	recorder = web.postHttpResponse("/setup/fields", "numFields=2&dsListenAddress2=10.0.200.5&plcAddress2=10.0.200.10&"+
+		"apTeamChannel2=149&ap2TeamChannel2=0")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	assert.Equal(t, 2, web.arena.EventSettings.NumFields)
+	fieldSettings, _ := web.arena.Database.GetFieldSettingsById(2)
+	if assert.NotNil(t, fieldSettings) {
+		assert.Equal(t, "10.0.200.5", fieldSettings.DsListenAddress)
+		assert.Equal(t, "10.0.200.10", fieldSettings.PlcAddress)
+		assert.Equal(t, 149, fieldSettings.ApTeamChannel)
+	}
+	recorder = web.getHttpResponse("/setup/fields")
+	assert.Contains(t, recorder.Body.String(), "Field 2")
+	assert.Contains(t, recorder.Body.String(), "10.0.200.5")
+	assert.Contains(t, recorder.Body.String(), "Restart Cheesy Arena")
+}
+
+func TestSetupFieldsErrors(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postHttpResponse("/setup/fields", "numFields=5")
+	assert.Contains(t, recorder.Body.String(), "Number of fields must be between 1 and 4.")
+	recorder = web.postHttpResponse("/setup/fields", "numFields=2&dsListenAddress2=10.0.100.5")
+	assert.Contains(t, recorder.Body.String(), "Field 2 can't use the same driver station address as field 1.")
+	recorder = web.postHttpResponse("/setup/fields",
+		"numFields=3&dsListenAddress2=10.0.200.5&dsListenAddress3=10.0.200.5")
+	assert.Contains(t, recorder.Body.String(), "Field 3 can't use the same driver station address as field 2.")
+	recorder = web.postHttpResponse("/setup/fields", "numFields=2&dsListenAddress2=+")
+	assert.Contains(t, recorder.Body.String(), "Field 2 needs a driver station listen address.")
+	recorder = web.postHttpResponse("/setup/fields",
+		"numFields=2&dsListenAddress2=10.0.200.5&apTeamChannel2=157&ap2TeamChannel2=157")
+	assert.Contains(t, recorder.Body.String(), "Cannot use same channel for both access points on field 2.")
+	assert.Equal(t, 1, web.arena.EventSettings.NumFields)
+}
@@ -18,7 +18,7 @@ import (
 
 // Shows the lower third configuration page.
 func (web *Web) lowerThirdsGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -45,7 +45,7 @@ func (web *Web) lowerThirdsGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // The websocket endpoint for the lower thirds client to send control commands.
 func (web *Web) lowerThirdsWebsocketHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleEmcee) {
 		return
 	}
 
@@ -68,6 +68,10 @@ func (web *Web) lowerThirdsWebsocketHandler(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
+		if !web.websocketUserHasRole(ws, r, model.RoleEmcee) {
+			return
+		}
+
 		switch messageType {
 		case "saveLowerThird":
 			var lowerThird model.LowerThird
@@ -0,0 +1,62 @@
+// Web routes for monitoring the results publishing outbox.
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+)
+
+// Shows the state of every entry in the publishing outbox.
+func (web *Web) publishingGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	operations, err := web.arena.Database.GetAllPublishOperations()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	var publisherNames []string
+	for _, publisher := range web.arena.Publishers() {
+		publisherNames = append(publisherNames, publisher.Name())
+	}
+
+	template, err := web.parseFiles("templates/setup_publishing.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Operations     []model.PublishOperation
+		PublisherNames []string
+	}{web.arena.EventSettings, operations, publisherNames}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Re-sends a single outbox entry if an ID is given, or else every resource to every enabled publisher.
+func (web *Web) publishingRepublishPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	if idString := r.PostFormValue("id"); idString != "" {
+		id, _ := strconv.Atoi(idString)
+		if err := web.arena.RetryPublishOperation(id); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+	} else {
+		web.arena.PublishAsync(partner.PublishResources...)
+	}
+	http.Redirect(w, r, "/setup/publishing", 303)
+}
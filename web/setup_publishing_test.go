@@ -0,0 +1,52 @@
+package web
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/partner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupPublishing(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.getHttpResponse("/setup/publishing")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Results publishing is not enabled")
+
+	web.arena.TbaClient.BaseUrl = "fakeUrl"
+	web.arena.EventSettings.TbaPublishingEnabled = true
+	recorder = web.postHttpResponse("/setup/publishing/republish", "")
+	assert.Equal(t, 303, recorder.Code)
+	assert.Eventually(t, func() bool {
+		operations, _ := web.arena.Database.GetAllPublishOperations()
+		for _, operation := range operations {
+			if operation.Pending && operation.Attempts == 0 {
+				return false
+			}
+		}
+		return len(operations) == len(partner.PublishResources)
+	}, time.Second, 10*time.Millisecond)
+	recorder = web.getHttpResponse("/setup/publishing")
+	assert.Contains(t, recorder.Body.String(), "Publishing to: <b>TBA</b>")
+	assert.Contains(t, recorder.Body.String(), "rankings")
+	assert.Contains(t, recorder.Body.String(), "failed")
+	assert.Contains(t, recorder.Body.String(), "Never")
+
+	// Republish a single entry.
+	operation, _ := web.arena.Database.GetPublishOperation("TBA", "awards")
+	operation.NextAttemptAt = time.Now().Add(time.Hour)
+	assert.Nil(t, web.arena.Database.UpdatePublishOperation(operation))
+	recorder = web.postHttpResponse("/setup/publishing/republish", fmt.Sprintf("id=%d", operation.Id))
+	assert.Equal(t, 303, recorder.Code)
+	assert.Eventually(t, func() bool {
+		operation, _ := web.arena.Database.GetPublishOperation("TBA", "awards")
+		return operation.NextAttemptAt.Before(time.Now().Add(time.Minute))
+	}, time.Second, 10*time.Millisecond)
+
+	recorder = web.postHttpResponse("/setup/publishing/republish", "id=12345")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "doesn't exist")
+}
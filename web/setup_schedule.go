@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 	"github.com/BotDogs4645/da/tournament"
 )
 
@@ -21,7 +22,7 @@ var cachedTeamFirstMatches = make(map[string]map[int]string)
 
 // Shows the schedule editing page.
 func (web *Web) scheduleGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -41,7 +42,7 @@ func (web *Web) scheduleGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // Generates the schedule, presents it for review without saving it, and saves the schedule blocks to the database.
 func (web *Web) scheduleGeneratePostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -87,6 +88,7 @@ func (web *Web) scheduleGeneratePostHandler(w http.ResponseWriter, r *http.Reque
 		web.renderSchedule(w, r, fmt.Sprintf("Error generating schedule: %s.", err.Error()))
 		return
 	}
+	tournament.AssignFields(matches, web.arena.EventSettings.NumFields)
 	cachedMatches[matchType] = matches
 
 	// Determine each team's first match.
@@ -106,26 +108,35 @@ func (web *Web) scheduleGeneratePostHandler(w http.ResponseWriter, r *http.Reque
 		checkTeam(match.Blue3)
 	}
 	cachedTeamFirstMatches[matchType] = teamFirstMatches
+	web.audit(
+		r,
+		auditScheduleGenerate,
+		matchType,
+		nil,
+		struct {
+			ScheduleBlocks []model.ScheduleBlock
+			NumMatches     int
+		}{scheduleBlocks, len(matches)},
+	)
 
 	http.Redirect(w, r, "/setup/schedule?matchType="+matchType, 303)
 }
 
-// Publishes the schedule in the database to TBA
+// Publishes the schedule in the database to all the enabled results publishers.
 func (web *Web) scheduleRepublishPostHandler(w http.ResponseWriter, r *http.Request) {
-	if web.arena.EventSettings.TbaPublishingEnabled {
-		// Publish schedule to The Blue Alliance.
-		err := web.arena.TbaClient.DeletePublishedMatches()
+	if web.arena.EventSettings.PublishingEnabled() {
+		err := web.arena.DeletePublishedMatches()
 		if err != nil {
 			http.Error(w, "Failed to delete published matches: "+err.Error(), 500)
 			return
 		}
-		err = web.arena.TbaClient.PublishMatches(web.arena.Database)
+		err = web.arena.Publish(partner.MatchesResource)
 		if err != nil {
 			http.Error(w, "Failed to publish matches: "+err.Error(), 500)
 			return
 		}
 	} else {
-		http.Error(w, "TBA publishing is not enabled", 500)
+		http.Error(w, "Results publishing is not enabled", 500)
 		return
 	}
 
@@ -134,7 +145,7 @@ func (web *Web) scheduleRepublishPostHandler(w http.ResponseWriter, r *http.Requ
 
 // Saves the generated schedule to the database.
 func (web *Web) scheduleSavePostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -157,6 +168,7 @@ func (web *Web) scheduleSavePostHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 	}
+	web.audit(r, auditScheduleSave, matchType, nil, struct{ NumMatches int }{len(cachedMatches[matchType])})
 
 	// Back up the database.
 	err = web.arena.Database.Backup(web.arena.EventSettings.Name, "post_scheduling")
@@ -165,14 +177,14 @@ func (web *Web) scheduleSavePostHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if web.arena.EventSettings.TbaPublishingEnabled && matchType != "practice" {
-		// Publish schedule to The Blue Alliance.
-		err = web.arena.TbaClient.DeletePublishedMatches()
+	if matchType != "practice" {
+		// Publish schedule to all the enabled results publishers.
+		err = web.arena.DeletePublishedMatches()
 		if err != nil {
 			http.Error(w, "Failed to delete published matches: "+err.Error(), 500)
 			return
 		}
-		err = web.arena.TbaClient.PublishMatches(web.arena.Database)
+		err = web.arena.Publish(partner.MatchesResource)
 		if err != nil {
 			http.Error(w, "Failed to publish matches: "+err.Error(), 500)
 			return
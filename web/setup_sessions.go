@@ -0,0 +1,76 @@
+// Web routes for reviewing and revoking user login sessions.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/gorilla/mux"
+)
+
+// Shows the active login sessions.
+func (web *Web) sessionsGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	idleTimeout, maxAge := web.arena.EventSettings.SessionLimits()
+	if err := web.arena.Database.DeleteExpiredUserSessions(time.Now(), idleTimeout, maxAge); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	sessions, err := web.arena.Database.GetAllUserSessions()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	currentSessionId := 0
+	if session := web.getUserSessionFromCookie(r); session != nil {
+		currentSessionId = session.Id
+	}
+
+	template, err := web.parseFiles("templates/setup_sessions.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Sessions         []model.UserSession
+		CurrentSessionId int
+	}{web.arena.EventSettings, sessions, currentSessionId}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Ends the given login session, logging out whoever was using it.
+func (web *Web) sessionRevokePostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	sessionId, _ := strconv.Atoi(mux.Vars(r)["id"])
+	session, err := web.arena.Database.GetUserSessionById(sessionId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	if session == nil {
+		handleWebErr(w, fmt.Errorf("session %d doesn't exist", sessionId))
+		return
+	}
+	if err = web.arena.Database.DeleteUserSession(session.Id); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	web.audit(r, auditSessionRevoke, fmt.Sprintf("session %d", session.Id), session.Username, nil)
+
+	http.Redirect(w, r, "/setup/sessions", 303)
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupSessions(t *testing.T) {
+	web := setupTestWeb(t)
+	now := time.Now()
+	web.arena.Database.CreateUserSession(&model.UserSession{
+		Token: "token1", Username: "bertha", CreatedAt: now, RemoteAddress: "10.0.100.5", UserAgent: "Blorpy/1.0",
+	})
+	web.arena.Database.CreateUserSession(&model.UserSession{Token: "token2", CreatedAt: now.Add(-48 * time.Hour)})
+
+	// Check that expired sessions are removed and active ones are listed.
+	recorder := web.getHttpResponse("/setup/sessions")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "bertha")
+	assert.Contains(t, recorder.Body.String(), "10.0.100.5")
+	assert.Contains(t, recorder.Body.String(), "Blorpy/1.0")
+	session, _ := web.arena.Database.GetUserSessionByToken("token2")
+	assert.Nil(t, session)
+
+	recorder = web.postHttpResponse("/setup/sessions/1/revoke", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	session, _ = web.arena.Database.GetUserSessionByToken("token1")
+	assert.Nil(t, session)
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{Action: auditSessionRevoke})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, "session 1", entries[0].Target)
+	}
+
+	recorder = web.postHttpResponse("/setup/sessions/1/revoke", "")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "session 1 doesn't exist")
+}
+
+func TestSessionExpiry(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.AdminPassword = "admin"
+	web.arena.EventSettings.SessionIdleTimeoutMin = 30
+	web.arena.EventSettings.SessionMaxAgeHours = 8
+	now := time.Now()
+	web.arena.Database.CreateUserSession(
+		&model.UserSession{Token: "idle", Username: "admin", CreatedAt: now.Add(-time.Hour)},
+	)
+	web.arena.Database.CreateUserSession(&model.UserSession{
+		Token: "old", Username: "admin", CreatedAt: now.Add(-9 * time.Hour), LastActiveAt: now.Add(-time.Minute),
+	})
+	web.arena.Database.CreateUserSession(&model.UserSession{
+		Token: "active", Username: "admin", CreatedAt: now.Add(-time.Hour), LastActiveAt: now.Add(-10 * time.Minute),
+	})
+
+	recorder := web.getHttpResponseWithHeaders("/setup/settings", map[string]string{"Cookie": "session_token=idle"})
+	assert.Equal(t, 307, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/setup/settings", map[string]string{"Cookie": "session_token=old"})
+	assert.Equal(t, 307, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/setup/settings", map[string]string{"Cookie": "session_token=active"})
+	assert.Equal(t, 200, recorder.Code)
+	session, _ := web.arena.Database.GetUserSessionByToken("active")
+	if assert.NotNil(t, session) {
+		assert.True(t, now.Sub(session.LastActiveAt) < time.Minute)
+	}
+	sessions, _ := web.arena.Database.GetAllUserSessions()
+	assert.Equal(t, 1, len(sessions))
+}
+
+func TestLogout(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.EventSettings.AdminPassword = "admin"
+	login := func() string {
+		recorder := web.postHttpResponse("/login", "username=admin&password=admin")
+		return recorder.Header().Get("Set-Cookie")
+	}
+	cookie1 := login()
+	cookie2 := login()
+
+	recorder := web.getHttpResponseWithHeaders("/login", map[string]string{"Cookie": cookie1})
+	assert.Contains(t, recorder.Body.String(), "You are logged in as <b>admin</b>.")
+
+	recorder = web.postHttpResponseWithHeaders("/logout", "", map[string]string{"Cookie": cookie1})
+	assert.Equal(t, 303, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Set-Cookie"), "session_token=;")
+	recorder = web.getHttpResponseWithHeaders("/setup/settings", map[string]string{"Cookie": cookie1})
+	assert.Equal(t, 307, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/setup/settings", map[string]string{"Cookie": cookie2})
+	assert.Equal(t, 200, recorder.Code)
+
+	cookie3 := login()
+	recorder = web.postHttpResponseWithHeaders("/logout", "everywhere=true", map[string]string{"Cookie": cookie3})
+	assert.Equal(t, 303, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/setup/settings", map[string]string{"Cookie": cookie2})
+	assert.Equal(t, 307, recorder.Code)
+}
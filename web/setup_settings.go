@@ -14,12 +14,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/BotDogs4645/da/consistency"
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 )
 
 // Shows the event settings editing page.
 func (web *Web) settingsGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -28,11 +30,12 @@ func (web *Web) settingsGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // Saves the event settings.
 func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
 	eventSettings := web.arena.EventSettings
+	previousEventSettings := *eventSettings
 
 	previousEventName := eventSettings.Name
 	eventSettings.Name = r.PostFormValue("name")
@@ -56,11 +59,30 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 	eventSettings.NumElimAlliances = numAlliances
 	eventSettings.SelectionRound2Order = r.PostFormValue("selectionRound2Order")
 	eventSettings.SelectionRound3Order = r.PostFormValue("selectionRound3Order")
+	eventSettings.ElimTurnaroundMin, _ = strconv.Atoi(r.PostFormValue("elimTurnaroundMin"))
+	eventSettings.ElimTurnaroundEnforced = r.PostFormValue("elimTurnaroundEnforced") == "on"
 	eventSettings.TBADownloadEnabled = r.PostFormValue("TBADownloadEnabled") == "on"
+	eventSettings.InspectionRequired = r.PostFormValue("inspectionRequired") == "on"
 	eventSettings.TbaPublishingEnabled = r.PostFormValue("tbaPublishingEnabled") == "on"
 	eventSettings.TbaEventCode = r.PostFormValue("tbaEventCode")
 	eventSettings.TbaSecretId = r.PostFormValue("tbaSecretId")
 	eventSettings.TbaSecret = r.PostFormValue("tbaSecret")
+	eventSettings.LivestreamVodUrl = strings.TrimSpace(r.PostFormValue("livestreamVodUrl"))
+	eventSettings.WebhookPublishingEnabled = r.PostFormValue("webhookPublishingEnabled") == "on"
+	eventSettings.WebhookUrl = r.PostFormValue("webhookUrl")
+	eventSettings.FileExportEnabled = r.PostFormValue("fileExportEnabled") == "on"
+	eventSettings.FileExportDir = r.PostFormValue("fileExportDir")
+	eventSettings.TeamNotificationsEnabled = r.PostFormValue("teamNotificationsEnabled") == "on"
+	eventSettings.NotificationMatchesAhead, _ = strconv.Atoi(r.PostFormValue("notificationMatchesAhead"))
+	eventSettings.NotificationWebhookUrl = strings.TrimSpace(r.PostFormValue("notificationWebhookUrl"))
+	eventSettings.SmtpHost = strings.TrimSpace(r.PostFormValue("smtpHost"))
+	eventSettings.SmtpPort, _ = strconv.Atoi(r.PostFormValue("smtpPort"))
+	eventSettings.SmtpUsername = r.PostFormValue("smtpUsername")
+	eventSettings.SmtpPassword = r.PostFormValue("smtpPassword")
+	eventSettings.SmtpFrom = strings.TrimSpace(r.PostFormValue("smtpFrom"))
+	eventSettings.NotificationLogEnabled = r.PostFormValue("notificationLogEnabled") == "on"
+	eventSettings.BackupRetentionCount, _ = strconv.Atoi(r.PostFormValue("backupRetentionCount"))
+	eventSettings.BackupMirrorDir = strings.TrimSpace(r.PostFormValue("backupMirrorDir"))
 	eventSettings.NetworkSecurityEnabled = r.PostFormValue("networkSecurityEnabled") == "on"
 	eventSettings.ApAddress = r.PostFormValue("apAddress")
 	eventSettings.ApUsername = r.PostFormValue("apUsername")
@@ -76,12 +98,60 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 	eventSettings.SwitchPassword = r.PostFormValue("switchPassword")
 	eventSettings.PlcAddress = r.PostFormValue("plcAddress")
 	eventSettings.AdminPassword = r.PostFormValue("adminPassword")
+	eventSettings.SessionIdleTimeoutMin, _ = strconv.Atoi(r.PostFormValue("sessionIdleTimeoutMin"))
+	eventSettings.SessionMaxAgeHours, _ = strconv.Atoi(r.PostFormValue("sessionMaxAgeHours"))
+	eventSettings.HttpsEnabled = r.PostFormValue("httpsEnabled") == "on"
 	eventSettings.WarmupDurationSec, _ = strconv.Atoi(r.PostFormValue("warmupDurationSec"))
 	eventSettings.AutoDurationSec, _ = strconv.Atoi(r.PostFormValue("autoDurationSec"))
 	eventSettings.PauseDurationSec, _ = strconv.Atoi(r.PostFormValue("pauseDurationSec"))
 	eventSettings.TeleopDurationSec, _ = strconv.Atoi(r.PostFormValue("teleopDurationSec"))
 	eventSettings.WarningRemainingDurationSec, _ = strconv.Atoi(r.PostFormValue("warningRemainingDurationSec"))
 
+	if _, ok := partner.ParseYoutubeVideoId(eventSettings.LivestreamVodUrl); eventSettings.LivestreamVodUrl != "" && !ok {
+		web.renderSettings(w, "Livestream VOD URL must be a YouTube video link.")
+		return
+	}
+	if eventSettings.WebhookPublishingEnabled && eventSettings.WebhookUrl == "" {
+		web.renderSettings(w, "A webhook URL must be given when webhook publishing is enabled.")
+		return
+	}
+	if eventSettings.FileExportEnabled && eventSettings.FileExportDir == "" {
+		web.renderSettings(w, "An export directory must be given when local file export is enabled.")
+		return
+	}
+
+	if eventSettings.NotificationMatchesAhead < 0 || eventSettings.NotificationMatchesAhead > 10 {
+		web.renderSettings(w, "Teams must be notified between 0 and 10 matches ahead.")
+		return
+	}
+	if eventSettings.SmtpHost != "" && (eventSettings.SmtpPort <= 0 || eventSettings.SmtpFrom == "") {
+		web.renderSettings(w, "A valid port and sender address must be given when an SMTP server is configured.")
+		return
+	}
+
+	if eventSettings.BackupRetentionCount < 0 {
+		web.renderSettings(w, "The number of backups to keep can't be negative.")
+		return
+	}
+
+	if eventSettings.AdminPassword == "" {
+		// Without an admin password every request is treated as coming from an admin, which would bypass the accounts.
+		users, err := web.arena.Database.GetAllUsers()
+		if err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		if len(users) > 0 {
+			web.renderSettings(w, "The admin password can't be cleared while user accounts exist.")
+			return
+		}
+	}
+
+	if eventSettings.SessionIdleTimeoutMin < 0 || eventSettings.SessionMaxAgeHours < 0 {
+		web.renderSettings(w, "Session expiry times can't be negative.")
+		return
+	}
+
 	if eventSettings.Ap2TeamChannel != 0 && eventSettings.Ap2TeamChannel == eventSettings.ApTeamChannel {
 		web.renderSettings(w, "Cannot use same channel for both access points.")
 		return
@@ -92,9 +162,10 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 		handleWebErr(w, err)
 		return
 	}
+	web.audit(r, auditSettingsUpdate, "", previousEventSettings, *eventSettings)
 
 	// Refresh the arena in case any of the settings changed.
-	err = web.arena.LoadSettings()
+	err = web.loadArenaSettings()
 	if err != nil {
 		handleWebErr(w, err)
 		return
@@ -113,7 +184,7 @@ func (web *Web) settingsPostHandler(w http.ResponseWriter, r *http.Request) {
 
 // Sends a copy of the event database file to the client as a download.
 func (web *Web) saveDbHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -127,77 +198,161 @@ func (web *Web) saveDbHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Sends a portable archive of the event in JSON form, for importing into another installation or another version.
+func (web *Web) saveArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.zip", strings.Replace(web.arena.EventSettings.Name, " ", "_", -1),
+		time.Now().Format("20060102150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if err := web.arena.Database.WriteArchive(w, partner.AvatarsDir); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
 // Accepts an event database file as an upload and loads it.
 func (web *Web) restoreDbHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
-	file, _, err := r.FormFile("databaseFile")
+	file, fileHeader, err := r.FormFile("databaseFile")
 	if err != nil {
 		web.renderSettings(w, "No database backup file was specified.")
 		return
 	}
 
-	// Write the file to a temporary location on disk and verify that it can be opened as a database.
-	tempFile, err := os.CreateTemp(".", "uploaded-db-")
+	tempFilePath, err := writeTempDatabase(file)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	defer tempFile.Close()
-	tempFilePath := tempFile.Name()
 	defer os.Remove(tempFilePath)
-	_, err = io.Copy(tempFile, file)
+	rejection, err := web.replaceDatabase(r, tempFilePath, fileHeader.Filename, "uploaded database backup file")
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
-	tempFile.Close()
-	tempDb, err := model.OpenDatabase(tempFilePath)
-	if err != nil {
-		web.renderSettings(w, "Could not read uploaded database backup file. Please verify that it a valid "+
-			"database file.")
+	if rejection != "" {
+		web.renderSettings(w, rejection)
 		return
 	}
-	tempDb.Close()
+
+	http.Redirect(w, r, "/setup/settings", 303)
+}
+
+// Writes the given database file to a temporary location on disk and returns its path.
+func writeTempDatabase(source io.Reader) (string, error) {
+	tempFile, err := os.CreateTemp(".", "uploaded-db-")
+	if err != nil {
+		return "", err
+	}
+	defer tempFile.Close()
+	if _, err = io.Copy(tempFile, source); err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+	return tempFile.Name(), nil
+}
+
+// Replaces the current database with the one at the given path, after backing up the current one. The audit log, the
+// saved display configurations and the user accounts and sessions are carried over, with the restore recorded as being
+// from the given source. The new database is first verified to be readable, which also migrates one from an older
+// version to the current schema, and to be consistent unless the request asks to ignore its problems; if not, it isn't
+// loaded and a message explaining why is returned, referring to it using the given description.
+func (web *Web) replaceDatabase(r *http.Request, path, source, description string) (string, error) {
+	newDb, err := model.OpenDatabase(path)
+	if err != nil {
+		return fmt.Sprintf("Could not read %s: %v", description, err), nil
+	}
+
+	// Refuse to load an inconsistent database unless asked to, since it can cause trouble once loaded.
+	problems, err := consistency.Check(newDb)
+	newDb.Close()
+	if err != nil {
+		return fmt.Sprintf("Could not check %s: %v", description, err), nil
+	}
+	if len(problems) > 0 && r.PostFormValue("ignoreProblems") != "on" {
+		var descriptions []string
+		for _, problem := range problems {
+			descriptions = append(descriptions, problem.Description)
+		}
+		return fmt.Sprintf(
+			"The %s wasn't loaded since it has consistency problems: %s Load it anyway to repair them from the "+
+				"Database Consistency page.",
+			description, strings.Join(descriptions, " "),
+		), nil
+	}
+
+	web.arena.LockPublishOutbox()
+	defer web.arena.UnlockPublishOutbox()
 
 	// Back up the current database.
 	err = web.arena.Database.Backup(web.arena.EventSettings.Name, "pre_restore")
 	if err != nil {
-		handleWebErr(w, err)
-		return
+		return "", err
 	}
 
-	// Replace the current database with the new one.
-	web.arena.Database.Close()
-	err = os.Remove(web.arena.Database.Path)
+	// Hold on to the audit log so that it can be carried over, since the backup won't contain its latest entries.
+	auditEntry := web.newAuditEntry(r, auditDbRestore, source)
+	auditEntries, err := web.arena.Database.GetAuditEntries(model.AuditFilter{})
 	if err != nil {
-		handleWebErr(w, err)
-		return
+		return "", err
 	}
-	err = os.Rename(tempFilePath, web.arena.Database.Path)
+
+	// Likewise for the saved display configurations, which belong to the displays rather than to the event.
+	savedDisplays, err := web.arena.Database.GetAllDisplays()
 	if err != nil {
-		handleWebErr(w, err)
-		return
+		return "", err
 	}
-	web.arena.Database, err = model.OpenDatabase(web.arena.Database.Path)
+
+	// And for the user accounts and their sessions, so that a backup neither brings back revoked access nor loses
+	// accounts created since it was taken.
+	users, err := web.arena.Database.GetAllUsers()
 	if err != nil {
-		handleWebErr(w, err)
-		return
+		return "", err
 	}
-	err = web.arena.LoadSettings()
+	userSessions, err := web.arena.Database.GetAllUserSessions()
 	if err != nil {
-		handleWebErr(w, err)
-		return
+		return "", err
 	}
 
-	http.Redirect(w, r, "/setup/settings", 303)
+	// Replace the current database with the new one.
+	web.arena.Database.Close()
+	if err = os.Remove(web.arena.Database.Path); err != nil {
+		return "", err
+	}
+	if err = os.Rename(path, web.arena.Database.Path); err != nil {
+		return "", err
+	}
+	if web.arena.Database, err = model.OpenDatabase(web.arena.Database.Path); err != nil {
+		return "", err
+	}
+	if err = web.arena.Database.ImportAuditEntries(auditEntries); err != nil {
+		return "", err
+	}
+	if err = web.arena.RestoreDisplays(savedDisplays); err != nil {
+		return "", err
+	}
+	if err = web.arena.Database.ImportUsers(users); err != nil {
+		return "", err
+	}
+	if err = web.arena.Database.ImportUserSessions(userSessions); err != nil {
+		return "", err
+	}
+	web.writeAuditEntry(auditEntry, nil, nil)
+	cachedRankedTeams = []*RankedTeam{}
+	return "", web.loadArenaSettings()
 }
 
 // Deletes all data except for the team list.
 func (web *Web) clearDbHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -230,6 +385,7 @@ func (web *Web) clearDbHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	web.arena.AllianceSelectionAlliances = []model.Alliance{}
 	cachedRankedTeams = []*RankedTeam{}
+	web.audit(r, auditDbClear, "", nil, nil)
 
 	http.Redirect(w, r, "/setup/settings", 303)
 }
@@ -28,9 +28,22 @@ func TestSetupSettings(t *testing.T) {
 	assert.NotContains(t, recorder.Body.String(), "tbaPublishingEnabled\" checked")
 
 	// Change the settings and check the response.
+	mirrorDir := t.TempDir()
 	recorder = web.postHttpResponse("/setup/settings", "name=Chezy Champs&code=CC&elimType=single&numElimAlliances=16&"+
-		"tbaPublishingEnabled=on&tbaEventCode=2014cc&tbaSecretId=secretId&tbaSecret=tbasec")
+		"tbaPublishingEnabled=on&tbaEventCode=2014cc&tbaSecretId=secretId&tbaSecret=tbasec&elimTurnaroundMin=8&"+
+		"elimTurnaroundEnforced=on&inspectionRequired=on&webhookPublishingEnabled=on&webhookUrl=http://example.com/hook&"+
+		"fileExportEnabled=on&fileExportDir=/tmp/results&teamNotificationsEnabled=on&notificationMatchesAhead=3&"+
+		"notificationWebhookUrl=http://example.com/notify&smtpHost=localhost&smtpPort=2525&smtpFrom=fms@example.com&"+
+		"backupRetentionCount=20&backupMirrorDir="+mirrorDir)
 	assert.Equal(t, 303, recorder.Code)
+	assert.Equal(t, 8, web.arena.EventSettings.ElimTurnaroundMin)
+	assert.True(t, web.arena.EventSettings.ElimTurnaroundEnforced)
+	assert.True(t, web.arena.EventSettings.InspectionRequired)
+	assert.Equal(t, 3, len(web.arena.Publishers()))
+	assert.Equal(t, 3, web.arena.EventSettings.NotificationMatchesAhead)
+	assert.Equal(t, 2, len(web.arena.NotificationChannels()))
+	assert.Equal(t, 20, web.arena.EventSettings.BackupRetentionCount)
+	assert.Equal(t, mirrorDir, web.arena.EventSettings.BackupMirrorDir)
 	recorder = web.getHttpResponse("/setup/settings")
 	assert.Contains(t, recorder.Body.String(), "Chezy Champs")
 	assert.Contains(t, recorder.Body.String(), "16")
@@ -38,6 +51,12 @@ func TestSetupSettings(t *testing.T) {
 	assert.Contains(t, recorder.Body.String(), "2014cc")
 	assert.Contains(t, recorder.Body.String(), "secretId")
 	assert.Contains(t, recorder.Body.String(), "tbasec")
+	assert.Contains(t, recorder.Body.String(), "webhookPublishingEnabled\" checked")
+	assert.Contains(t, recorder.Body.String(), "http://example.com/hook")
+	assert.Contains(t, recorder.Body.String(), "/tmp/results")
+	assert.Contains(t, recorder.Body.String(), "teamNotificationsEnabled\" checked")
+	assert.Contains(t, recorder.Body.String(), "http://example.com/notify")
+	assert.Contains(t, recorder.Body.String(), "fms@example.com")
 }
 
 func TestSetupSettingsDoubleElimination(t *testing.T) {
@@ -55,6 +74,24 @@ func TestSetupSettingsInvalidValues(t *testing.T) {
 	// Invalid number of alliances.
 	recorder := web.postHttpResponse("/setup/settings", "numAlliances=1")
 	assert.Contains(t, recorder.Body.String(), "must be between 2 and 16")
+
+	// Publishing enabled without a destination.
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&webhookPublishingEnabled=on")
+	assert.Contains(t, recorder.Body.String(), "A webhook URL must be given")
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&fileExportEnabled=on")
+	assert.Contains(t, recorder.Body.String(), "An export directory must be given")
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&notificationMatchesAhead=11")
+	assert.Contains(t, recorder.Body.String(), "Teams must be notified between 0 and 10 matches ahead")
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&backupRetentionCount=-1")
+	assert.Contains(t, recorder.Body.String(), "The number of backups to keep can't be negative")
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8&smtpHost=localhost&smtpPort=25")
+	assert.Contains(t, recorder.Body.String(), "A valid port and sender address must be given")
+
+	// Admin password cleared while user accounts exist.
+	user, _ := model.NewUser("bertha", "pass1", model.RoleScorer)
+	web.arena.Database.CreateUser(user)
+	recorder = web.postHttpResponse("/setup/settings", "numElimAlliances=8")
+	assert.Contains(t, recorder.Body.String(), "The admin password can't be cleared while user accounts exist")
 }
 
 func TestSetupSettingsClearDb(t *testing.T) {
@@ -87,6 +124,7 @@ func TestSetupSettingsBackupRestoreDb(t *testing.T) {
 	// Modify a parameter so that we know when the database has been restored.
 	web.arena.EventSettings.Name = "Chezy Champs"
 	assert.Nil(t, web.arena.Database.UpdateEventSettings(web.arena.EventSettings))
+	web.arena.Database.CreateUserSession(&model.UserSession{Token: "revoked", Username: "bertha"})
 
 	// Back up the database.
 	recorder := web.getHttpResponse("/setup/db/save")
@@ -111,8 +149,19 @@ func TestSetupSettingsBackupRestoreDb(t *testing.T) {
 	assert.NotEqual(t, "Chezy Champs", web.arena.EventSettings.Name)
 
 	// Check restoring with the backup retrieved before.
+	user, _ := model.NewUser("bertha", "password", model.RoleScorer)
+	web.arena.Database.CreateUser(user)
+	web.arena.Database.CreateUserSession(&model.UserSession{Token: "current", Username: "bertha"})
 	recorder = web.postFileHttpResponse("/setup/db/restore", "databaseFile", backupBody)
 	assert.Equal(t, "Chezy Champs", web.arena.EventSettings.Name)
+
+	// Check that the user accounts and sessions are carried over rather than rolled back to those in the backup.
+	user, _ = web.arena.Database.GetUserByUsername("bertha")
+	assert.NotNil(t, user)
+	session, _ := web.arena.Database.GetUserSessionByToken("current")
+	assert.NotNil(t, session)
+	session, _ = web.arena.Database.GetUserSessionByToken("revoked")
+	assert.Nil(t, session)
 }
 
 func (web *Web) postFileHttpResponse(path string, paramName string, file *bytes.Buffer) *httptest.ResponseRecorder {
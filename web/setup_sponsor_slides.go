@@ -14,7 +14,7 @@ import (
 
 // Shows the sponsor slides configuration page.
 func (web *Web) sponsorSlidesGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -45,7 +45,7 @@ func (web *Web) sponsorSlidesGetHandler(w http.ResponseWriter, r *http.Request)
 
 // Saves the new or modified sponsor slides to the database.
 func (web *Web) sponsorSlidesPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -7,22 +7,31 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
 	"github.com/dchest/uniuri"
 	"github.com/gorilla/mux"
 )
 
 const wpaKeyLength = 8
 
+// Describes when a team's data was last fetched from TBA into the local cache.
+type tbaCacheStatus struct {
+	FetchedAt string
+	IsStale   bool
+}
+
 // Shows the team list.
 func (web *Web) teamsGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -31,7 +40,7 @@ func (web *Web) teamsGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // Adds teams to the team list.
 func (web *Web) teamsPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -60,13 +69,123 @@ func (web *Web) teamsPostHandler(w http.ResponseWriter, r *http.Request) {
 			handleWebErr(w, err)
 			return
 		}
+		web.audit(r, auditTeamAdd, fmt.Sprintf("Team %d", team.Id), nil, team)
+	}
+	http.Redirect(w, r, "/setup/teams", 303)
+}
+
+// Parses an uploaded roster file and shows a preview of the changes it would make to the team list.
+func (web *Web) teamsImportPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	file, _, err := r.FormFile("rosterFile")
+	if err != nil {
+		web.renderTeamsImport(w, nil, []string{"No roster file was specified."})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	teams, err := parseRoster(data)
+	if err != nil {
+		web.renderTeamsImport(w, nil, []string{err.Error()})
+		return
+	}
+	existingTeams, err := web.getTeamsById()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	web.renderTeamsImport(
+		w, diffRoster(teams, existingTeams), validateRoster(teams, existingTeams, web.canModifyTeamList()),
+	)
+}
+
+// Applies a previously previewed roster to the team list.
+func (web *Web) teamsImportApplyHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
 	}
+
+	teams, err := parseRoster([]byte(r.PostFormValue("roster")))
+	if err != nil {
+		web.renderTeamsImport(w, nil, []string{err.Error()})
+		return
+	}
+	existingTeams, err := web.getTeamsById()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	// Validate again in case anything has changed since the preview was generated.
+	if problems := validateRoster(teams, existingTeams, web.canModifyTeamList()); len(problems) > 0 {
+		web.renderTeamsImport(w, diffRoster(teams, existingTeams), problems)
+		return
+	}
+	if err = applyRoster(web.arena.Database, teams); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	var teamNumbers []int
+	for _, team := range teams {
+		teamNumbers = append(teamNumbers, team.Number)
+	}
+	web.audit(r, auditTeamsImport, "", nil, teamNumbers)
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
+func (web *Web) renderTeamsImport(w http.ResponseWriter, changes []rosterChange, problems []string) {
+	var roster []rosterTeam
+	for _, change := range changes {
+		roster = append(roster, change.rosterTeam)
+	}
+	rosterJson, err := json.Marshal(roster)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	template, err := web.parseFiles("templates/setup_teams_import.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Changes    []rosterChange
+		Problems   []string
+		RosterJson string
+	}{web.arena.EventSettings, changes, problems, string(rosterJson)}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
+
+// Returns a map of all existing teams keyed by team number.
+func (web *Web) getTeamsById() (map[int]model.Team, error) {
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	teamsById := make(map[int]model.Team, len(teams))
+	for _, team := range teams {
+		teamsById[team.Id] = team
+	}
+	return teamsById, nil
+}
+
 // Re-downloads the data for all teams from TBA and overwrites any local edits.
 func (web *Web) teamsRefreshHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -90,9 +209,49 @@ func (web *Web) teamsRefreshHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
+// Downloads TBA data for every team registered for the event or already in the team list into the local cache, so
+// that teams can still be added and refreshed at a venue without internet access.
+func (web *Web) teamsPrefetchHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	teams, err := web.arena.Database.GetAllTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	var teamNumbers []int
+	for _, team := range teams {
+		teamNumbers = append(teamNumbers, team.Id)
+	}
+	if web.arena.EventSettings.TbaEventCode != "" {
+		eventTeamNumbers, err := web.arena.TbaClient.GetEventTeamNumbers()
+		if err != nil {
+			http.Error(w, "Failed to get event team list from TBA: "+err.Error(), 500)
+			return
+		}
+		teamNumbers = append(teamNumbers, eventTeamNumbers...)
+	}
+
+	prefetched := make(map[int]bool)
+	for _, teamNumber := range teamNumbers {
+		if prefetched[teamNumber] {
+			continue
+		}
+		if err = web.arena.TbaClient.PrefetchTeam(teamNumber, time.Now().Year()); err != nil {
+			http.Error(w, "Failed to prefetch team data from TBA: "+err.Error(), 500)
+			return
+		}
+		prefetched[teamNumber] = true
+	}
+
+	http.Redirect(w, r, "/setup/teams", 303)
+}
+
 // Clears the team list.
 func (web *Web) teamsClearHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -101,17 +260,27 @@ func (web *Web) teamsClearHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := web.arena.Database.TruncateTeams()
+	teams, err := web.arena.Database.GetAllTeams()
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
+	err = web.arena.Database.TruncateTeams()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	var teamNumbers []int
+	for _, team := range teams {
+		teamNumbers = append(teamNumbers, team.Id)
+	}
+	web.audit(r, auditTeamsClear, "", teamNumbers, nil)
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
 // Shows the page to edit a team's fields.
 func (web *Web) teamEditGetHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -145,7 +314,7 @@ func (web *Web) teamEditGetHandler(w http.ResponseWriter, r *http.Request) {
 
 // Updates a team's fields.
 func (web *Web) teamEditPostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -160,6 +329,7 @@ func (web *Web) teamEditPostHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Error: No such team: %d", teamId), 400)
 		return
 	}
+	previousTeam := *team
 
 	team.Name = r.PostFormValue("name")
 	team.Nickname = r.PostFormValue("nickname")
@@ -177,17 +347,20 @@ func (web *Web) teamEditPostHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	team.HasConnected = r.PostFormValue("hasConnected") == "on"
+	team.ContactEmail = strings.TrimSpace(r.PostFormValue("contactEmail"))
+	team.ContactWebhookUrl = strings.TrimSpace(r.PostFormValue("contactWebhookUrl"))
 	err = web.arena.Database.UpdateTeam(team)
 	if err != nil {
 		handleWebErr(w, err)
 		return
 	}
+	web.audit(r, auditTeamEdit, fmt.Sprintf("Team %d", team.Id), previousTeam, *team)
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
 // Removes a team from the team list.
 func (web *Web) teamDeletePostHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -212,16 +385,17 @@ func (web *Web) teamDeletePostHandler(w http.ResponseWriter, r *http.Request) {
 		handleWebErr(w, err)
 		return
 	}
+	web.audit(r, auditTeamDelete, fmt.Sprintf("Team %d", team.Id), *team, nil)
 	http.Redirect(w, r, "/setup/teams", 303)
 }
 
 // Publishes the team list to the web.
 func (web *Web) teamsPublishHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
-	err := web.arena.TbaClient.PublishTeams(web.arena.Database)
+	err := web.arena.Publish(partner.TeamsResource)
 	if err != nil {
 		http.Error(w, "Failed to publish teams: "+err.Error(), 500)
 		return
@@ -231,7 +405,7 @@ func (web *Web) teamsPublishHandler(w http.ResponseWriter, r *http.Request) {
 
 // Generates random WPA keys and saves them to the team models.
 func (web *Web) teamsGenerateWpaKeysHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.userIsAdmin(w, r) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
 		return
 	}
 
@@ -267,11 +441,24 @@ func (web *Web) renderTeams(w http.ResponseWriter, showErrorMessage bool) {
 		handleWebErr(w, err)
 		return
 	}
+	// Look up how old each team's cached TBA data is, so that the operator can tell when it needs refreshing.
+	tbaCacheStatuses := make(map[int]*tbaCacheStatus)
+	for _, team := range teams {
+		if fetchedAt, ok := web.arena.TbaCache.TeamFetchedAt(team.Id); ok {
+			tbaCacheStatuses[team.Id] = &tbaCacheStatus{
+				FetchedAt: fetchedAt.Local().Format("2006-01-02 15:04"),
+				IsStale:   time.Since(fetchedAt) > partner.TbaCacheStaleAge,
+			}
+		}
+	}
+
 	data := struct {
 		*model.EventSettings
 		Teams            []model.Team
 		ShowErrorMessage bool
-	}{web.arena.EventSettings, teams, showErrorMessage}
+		TbaCacheStatuses map[int]*tbaCacheStatus
+		TbaOffline       bool
+	}{web.arena.EventSettings, teams, showErrorMessage, tbaCacheStatuses, web.arena.TbaClient.IsOffline()}
 	err = template.ExecuteTemplate(w, "base", data)
 	if err != nil {
 		handleWebErr(w, err)
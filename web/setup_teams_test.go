@@ -4,9 +4,11 @@
 package web
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -103,10 +105,14 @@ func TestSetupTeams(t *testing.T) {
 	recorder = web.getHttpResponse("/setup/teams/254/edit")
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "The Cheesy Poofs")
-	recorder = web.postHttpResponse("/setup/teams/254/edit", "nickname=Teh Chezy Pofs")
+	recorder = web.postHttpResponse("/setup/teams/254/edit",
+		"nickname=Teh Chezy Pofs&contactEmail=queue@team254.com&contactWebhookUrl=http://example.com/254")
 	assert.Equal(t, 303, recorder.Code)
 	recorder = web.getHttpResponse("/setup/teams")
 	assert.Contains(t, recorder.Body.String(), "Teh Chezy Pofs")
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.Equal(t, "queue@team254.com", team.ContactEmail)
+	assert.Equal(t, "http://example.com/254", team.ContactWebhookUrl)
 
 	// Re-download team info from TBA.
 	recorder = web.getHttpResponse("/setup/teams/refresh")
@@ -217,3 +223,97 @@ func TestSetupTeamsPublish(t *testing.T) {
 	assert.Equal(t, 500, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "Failed to publish teams")
 }
+
+func TestSetupTeamsTbaCache(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.Database.CreateTeam(&model.Team{Id: 254})
+	web.arena.EventSettings.TbaEventCode = "2023cmptx"
+
+	tbaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.RequestURI, "teams/keys") {
+			fmt.Fprintln(w, `["frc254", "frc1114"]`)
+		} else if strings.Contains(r.RequestURI, "robots") || strings.Contains(r.RequestURI, "awards") ||
+			strings.Contains(r.RequestURI, "media") {
+			fmt.Fprintln(w, `[]`)
+		} else if strings.Contains(r.RequestURI, "frc1114") {
+			fmt.Fprintln(w, `{"team_number": 1114, "nickname": "Simbotics"}`)
+		} else {
+			fmt.Fprintln(w, `{"team_number": 254, "nickname": "The Cheesy Poofs"}`)
+		}
+	}))
+	web.arena.TbaClient.BaseUrl = tbaServer.URL
+
+	recorder := web.getHttpResponse("/setup/teams")
+	assert.Contains(t, recorder.Body.String(), "0 of 1 teams cached")
+	assert.Contains(t, recorder.Body.String(), "Not cached")
+
+	// Prefetch the event's team list and check that it is cached.
+	recorder = web.postHttpResponse("/setup/teams/prefetch", "")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	recorder = web.getHttpResponse("/setup/teams")
+	assert.Contains(t, recorder.Body.String(), "1 of 1 teams cached")
+	assert.NotContains(t, recorder.Body.String(), "(stale)")
+	_, ok := web.arena.TbaCache.TeamFetchedAt(1114)
+	assert.True(t, ok)
+
+	// Take TBA offline and check that teams can still be added from the cache.
+	tbaServer.Close()
+	recorder = web.postHttpResponse("/setup/teams", "teamNumbers=1114")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	team, _ := web.arena.Database.GetTeamById(1114)
+	if assert.NotNil(t, team) {
+		assert.Equal(t, "Simbotics", team.Nickname)
+	}
+	recorder = web.getHttpResponse("/setup/teams")
+	assert.Contains(t, recorder.Body.String(), "2 of 2 teams cached")
+	assert.Contains(t, recorder.Body.String(), "team data was loaded from the local cache")
+
+	recorder = web.postHttpResponse("/setup/teams/prefetch", "")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Failed to prefetch team data from TBA")
+}
+
+func TestSetupTeamsImport(t *testing.T) {
+	web := setupTestWeb(t)
+	web.arena.Database.CreateTeam(&model.Team{Id: 254, Nickname: "Poofs", WpaKey: "12345678"})
+
+	// Check that the preview shows the changes without applying them.
+	roster := "Number,Nickname,City,RookieYear\n254,The Cheesy Poofs,San Jose,1999\n1114,Simbotics,St. Catharines,2003\n"
+	recorder := web.postFileHttpResponse("/setup/teams/import", "rosterFile", bytes.NewBufferString(roster))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "New team")
+	assert.Contains(t, recorder.Body.String(), "Updates Nickname, City, RookieYear")
+	assert.Contains(t, recorder.Body.String(), "Import 2 Teams")
+	assert.Contains(t, recorder.Body.String(), "&#34;Nickname&#34;:&#34;Simbotics&#34;")
+	team, _ := web.arena.Database.GetTeamById(1114)
+	assert.Nil(t, team)
+
+	recorder = web.postHttpResponse("/setup/teams/import/apply", "roster="+url.QueryEscape(
+		`[{"Number":254,"Nickname":"The Cheesy Poofs","City":"San Jose","RookieYear":1999},`+
+			`{"Number":1114,"Nickname":"Simbotics","City":"St. Catharines","RookieYear":2003}]`,
+	))
+	assert.Equal(t, 303, recorder.Code)
+	teams, _ := web.arena.Database.GetAllTeams()
+	if assert.Equal(t, 2, len(teams)) {
+		assert.Equal(t, "The Cheesy Poofs", teams[0].Nickname)
+		assert.Equal(t, "12345678", teams[0].WpaKey)
+		assert.Equal(t, 2003, teams[1].RookieYear)
+	}
+}
+
+func TestSetupTeamsImportErrors(t *testing.T) {
+	web := setupTestWeb(t)
+
+	recorder := web.postFileHttpResponse("/setup/teams/import", "rosterFile", bytes.NewBufferString("Number\n254\n254\n"))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Team 254 appears more than once.")
+	assert.NotContains(t, recorder.Body.String(), "Import 2 Teams")
+
+	// Check that new teams can't be added once the schedule exists, even if the preview was generated before that.
+	web.arena.Database.CreateMatch(&model.Match{Type: "qualification", DisplayName: "1"})
+	recorder = web.postHttpResponse("/setup/teams/import/apply", "roster="+url.QueryEscape(`[{"Number":254}]`))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "can't be added once the qualification schedule")
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.Nil(t, team)
+}
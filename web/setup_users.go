@@ -0,0 +1,125 @@
+// Web routes for managing the user accounts that can log in to the server.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BotDogs4645/da/model"
+)
+
+// Shows the user accounts configuration page.
+func (web *Web) usersGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	web.renderUsers(w, "")
+}
+
+// Creates, modifies or deletes a user account.
+func (web *Web) usersPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.userHasRole(w, r, model.RoleAdmin) {
+		return
+	}
+
+	userId, _ := strconv.Atoi(r.PostFormValue("id"))
+	user, err := web.arena.Database.GetUserById(userId)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	password := r.PostFormValue("password")
+	role := r.PostFormValue("role")
+
+	switch r.PostFormValue("action") {
+	case "delete":
+		if user == nil {
+			web.renderUsers(w, fmt.Sprintf("User %d doesn't exist.", userId))
+			return
+		}
+		if err = web.arena.Database.DeleteUser(user.Id); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		web.audit(r, auditUserDelete, user.Username, *user, nil)
+	case "save":
+		if user == nil {
+			if web.arena.EventSettings.AdminPassword == "" {
+				web.renderUsers(w, "An admin password must be set on the Settings page before adding users.")
+				return
+			}
+			username := strings.TrimSpace(r.PostFormValue("username"))
+			if username == adminUser {
+				web.renderUsers(w, fmt.Sprintf("The username '%s' is reserved for the admin password.", adminUser))
+				return
+			}
+			user, err = model.NewUser(username, password, role)
+			if err == nil {
+				err = web.arena.Database.CreateUser(user)
+			}
+			if err != nil {
+				web.renderUsers(w, fmt.Sprintf("Failed to create user: %s", err.Error()))
+				return
+			}
+			web.audit(r, auditUserAdd, user.Username, nil, *user)
+			break
+		}
+
+		previousUser := *user
+		if err = user.SetRole(role); err != nil {
+			web.renderUsers(w, fmt.Sprintf("Failed to update user: %s", err.Error()))
+			return
+		}
+		if password != "" {
+			if err = user.SetPassword(password); err != nil {
+				web.renderUsers(w, fmt.Sprintf("Failed to update user: %s", err.Error()))
+				return
+			}
+		}
+		if err = web.arena.Database.UpdateUser(user); err != nil {
+			handleWebErr(w, err)
+			return
+		}
+		web.audit(r, auditUserEdit, user.Username, previousUser, *user)
+	default:
+		web.renderUsers(w, fmt.Sprintf("Invalid action '%s'.", r.PostFormValue("action")))
+		return
+	}
+
+	// Log the user out everywhere so that a deleted account or an old password or role can't continue to be used.
+	if err = web.arena.Database.DeleteUserSessionsByUsername(user.Username); err != nil {
+		handleWebErr(w, err)
+		return
+	}
+
+	http.Redirect(w, r, "/setup/users", 303)
+}
+
+func (web *Web) renderUsers(w http.ResponseWriter, errorMessage string) {
+	template, err := web.parseFiles("templates/setup_users.html", "templates/base.html")
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	users, err := web.arena.Database.GetAllUsers()
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+	data := struct {
+		*model.EventSettings
+		Users        []model.User
+		Roles        []string
+		RoleNames    map[string]string
+		ErrorMessage string
+	}{web.arena.EventSettings, users, model.Roles, model.RoleNames, errorMessage}
+	err = template.ExecuteTemplate(w, "base", data)
+	if err != nil {
+		handleWebErr(w, err)
+		return
+	}
+}
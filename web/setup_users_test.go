@@ -0,0 +1,77 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupUsers(t *testing.T) {
+	web := setupTestWeb(t)
+
+	// Check that users can't be added while there is no admin password, since the accounts would have no effect.
+	recorder := web.getHttpResponse("/setup/users")
+	assert.Contains(t, recorder.Body.String(), "No admin password is set")
+	recorder = web.postHttpResponse("/setup/users", "id=0&action=save&username=bertha&password=pass1&role=scorer")
+	assert.Contains(t, recorder.Body.String(), "An admin password must be set")
+	user, _ := web.arena.Database.GetUserByUsername("bertha")
+	assert.Nil(t, user)
+
+	web.arena.EventSettings.AdminPassword = "admin"
+	recorder = web.postHttpResponse("/login", "username=admin&password=admin")
+	headers := map[string]string{"Cookie": recorder.Header().Get("Set-Cookie")}
+	postUsers := func(body string) *httptest.ResponseRecorder {
+		return web.postHttpResponseWithHeaders("/setup/users", body, headers)
+	}
+
+	recorder = postUsers("id=0&action=save&username=bertha&password=pass1&role=scorer")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	user, _ = web.arena.Database.GetUserByUsername("bertha")
+	if assert.NotNil(t, user) {
+		assert.Equal(t, model.RoleScorer, user.Role)
+		assert.True(t, user.CheckPassword("pass1"))
+	}
+	recorder = web.getHttpResponseWithHeaders("/setup/users", headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "bertha")
+	assert.NotContains(t, recorder.Body.String(), "No admin password is set")
+	entries, _ := web.arena.Database.GetAuditEntries(model.AuditFilter{Action: auditUserAdd})
+	if assert.Equal(t, 1, len(entries)) {
+		assert.NotContains(t, entries[0].After, user.PasswordHash)
+	}
+
+	// Check that invalid accounts are rejected.
+	recorder = postUsers("id=0&action=save&username=bertha&password=pass2&role=fta")
+	assert.Contains(t, recorder.Body.String(), "Failed to create user: user &#39;bertha&#39; already exists")
+	recorder = postUsers("id=0&action=save&username=admin&password=pass2&role=fta")
+	assert.Contains(t, recorder.Body.String(), "is reserved for the admin password")
+	recorder = postUsers("id=0&action=save&username=+admin+&password=pass2&role=fta")
+	assert.Contains(t, recorder.Body.String(), "is reserved for the admin password")
+	recorder = postUsers("id=0&action=save&username=alfred&password=pass2&role=janitor")
+	assert.Contains(t, recorder.Body.String(), "Failed to create user: invalid role &#39;janitor&#39;")
+
+	// Check that editing an account changes its role, keeps the password if none is given and logs it out.
+	web.arena.Database.CreateUserSession(&model.UserSession{Token: "token1", Username: "bertha", CreatedAt: time.Now()})
+	recorder = postUsers("id=1&action=save&password=&role=head_ref")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	user, _ = web.arena.Database.GetUserById(1)
+	assert.Equal(t, model.RoleHeadRef, user.Role)
+	assert.True(t, user.CheckPassword("pass1"))
+	session, _ := web.arena.Database.GetUserSessionByToken("token1")
+	assert.Nil(t, session)
+
+	recorder = postUsers("id=1&action=save&password=pass3&role=head_ref")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	user, _ = web.arena.Database.GetUserById(1)
+	assert.True(t, user.CheckPassword("pass3"))
+
+	recorder = postUsers("id=1&action=delete")
+	assert.Equal(t, 303, recorder.Code, recorder.Body.String())
+	user, _ = web.arena.Database.GetUserById(1)
+	assert.Nil(t, user)
+	entries, _ = web.arena.Database.GetAuditEntries(model.AuditFilter{Action: auditUserDelete})
+	assert.Equal(t, 1, len(entries))
+}
@@ -0,0 +1,299 @@
+// Reading, validating and writing offline team roster files in CSV or JSON format.
+
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+)
+
+// Earliest rookie year that is considered valid for an FRC team.
+const minRookieYear = 1992
+
+// Column headers of the CSV roster format, which are also the field names of the JSON format.
+var rosterCsvHeaders = []string{
+	"Number", "Name", "Nickname", "City", "StateProv", "Country", "RookieYear", "RobotName", "Accomplishments", "Avatar",
+}
+
+// Represents a single team's entry in a roster file. The avatar is a base64-encoded PNG, as provided by TBA.
+type rosterTeam struct {
+	Number          int
+	Name            string
+	Nickname        string
+	City            string
+	StateProv       string
+	Country         string
+	RookieYear      int
+	RobotName       string
+	Accomplishments string
+	Avatar          string
+}
+
+// Describes the effect that importing a roster entry would have on the existing team list.
+type rosterChange struct {
+	rosterTeam
+	IsNew         bool
+	ChangedFields []string
+}
+
+// Parses the given roster file contents, detecting whether it is in JSON or CSV format.
+func parseRoster(data []byte) ([]rosterTeam, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("roster file is empty")
+	}
+	if trimmed[0] == '[' {
+		var teams []rosterTeam
+		if err := json.Unmarshal(trimmed, &teams); err != nil {
+			return nil, fmt.Errorf("could not parse JSON roster: %v", err)
+		}
+		return teams, nil
+	}
+	return parseRosterCsv(bytes.NewReader(trimmed))
+}
+
+// Parses a CSV roster, whose first row must consist of column headers from rosterCsvHeaders in any order.
+func parseRosterCsv(reader io.Reader) ([]rosterTeam, error) {
+	csvReader := csv.NewReader(reader)
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse CSV roster: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		header = strings.TrimSpace(header)
+		if !isRosterCsvHeader(header) {
+			return nil, fmt.Errorf("unknown column '%s' in CSV roster", header)
+		}
+		columns[header] = i
+	}
+	if _, ok := columns["Number"]; !ok {
+		return nil, fmt.Errorf("CSV roster is missing the 'Number' column")
+	}
+
+	var teams []rosterTeam
+	for i, record := range records[1:] {
+		value := func(header string) string {
+			if column, ok := columns[header]; ok {
+				return strings.TrimSpace(record[column])
+			}
+			return ""
+		}
+		var team rosterTeam
+		if team.Number, err = strconv.Atoi(value("Number")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid team number '%s'", i+2, value("Number"))
+		}
+		if rookieYear := value("RookieYear"); rookieYear != "" {
+			if team.RookieYear, err = strconv.Atoi(rookieYear); err != nil {
+				return nil, fmt.Errorf("line %d: invalid rookie year '%s'", i+2, rookieYear)
+			}
+		}
+		team.Name = value("Name")
+		team.Nickname = value("Nickname")
+		team.City = value("City")
+		team.StateProv = value("StateProv")
+		team.Country = value("Country")
+		team.RobotName = value("RobotName")
+		team.Accomplishments = value("Accomplishments")
+		team.Avatar = value("Avatar")
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func isRosterCsvHeader(header string) bool {
+	for _, rosterHeader := range rosterCsvHeaders {
+		if header == rosterHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the given roster entries for problems, returning a list of human-readable errors (empty if it is valid). New
+// teams are only allowed if canAddTeams is true.
+func validateRoster(teams []rosterTeam, existingTeams map[int]model.Team, canAddTeams bool) []string {
+	var problems []string
+	if len(teams) == 0 {
+		problems = append(problems, "Roster does not contain any teams.")
+	}
+	seenNumbers := make(map[int]bool)
+	for _, team := range teams {
+		if team.Number <= 0 {
+			problems = append(problems, fmt.Sprintf("Invalid team number %d.", team.Number))
+			continue
+		}
+		if seenNumbers[team.Number] {
+			problems = append(problems, fmt.Sprintf("Team %d appears more than once.", team.Number))
+		}
+		seenNumbers[team.Number] = true
+		if _, ok := existingTeams[team.Number]; !ok && !canAddTeams {
+			problems = append(problems, fmt.Sprintf("Team %d can't be added once the qualification schedule has "+
+				"been generated.", team.Number))
+		}
+		if team.RookieYear != 0 && (team.RookieYear < minRookieYear || team.RookieYear > time.Now().Year()+1) {
+			problems = append(
+				problems, fmt.Sprintf("Team %d has invalid rookie year %d.", team.Number, team.RookieYear),
+			)
+		}
+		if team.Avatar != "" {
+			if _, err := decodeRosterAvatar(team.Avatar); err != nil {
+				problems = append(problems, fmt.Sprintf("Team %d has an invalid avatar: %v.", team.Number, err))
+			}
+		}
+	}
+	return problems
+}
+
+// Decodes the given base64 avatar and verifies that it is a PNG image.
+func decodeRosterAvatar(avatar string) ([]byte, error) {
+	avatarBytes, err := base64.StdEncoding.DecodeString(avatar)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = png.DecodeConfig(bytes.NewReader(avatarBytes)); err != nil {
+		return nil, err
+	}
+	return avatarBytes, nil
+}
+
+// Works out which teams would be created and which fields would be changed by importing the given roster.
+func diffRoster(teams []rosterTeam, existingTeams map[int]model.Team) []rosterChange {
+	changes := make([]rosterChange, 0, len(teams))
+	for _, team := range teams {
+		change := rosterChange{rosterTeam: team}
+		existingTeam, ok := existingTeams[team.Number]
+		if !ok {
+			change.IsNew = true
+		} else {
+			existing := newRosterTeam(&existingTeam)
+			fields := []struct {
+				name               string
+				oldValue, newValue interface{}
+			}{
+				{"Name", existing.Name, team.Name},
+				{"Nickname", existing.Nickname, team.Nickname},
+				{"City", existing.City, team.City},
+				{"StateProv", existing.StateProv, team.StateProv},
+				{"Country", existing.Country, team.Country},
+				{"RookieYear", existing.RookieYear, team.RookieYear},
+				{"RobotName", existing.RobotName, team.RobotName},
+				{"Accomplishments", existing.Accomplishments, team.Accomplishments},
+				{"Avatar", existing.Avatar, team.Avatar},
+			}
+			for _, field := range fields {
+				if field.oldValue != field.newValue && !(field.name == "Avatar" && field.newValue == "") {
+					change.ChangedFields = append(change.ChangedFields, field.name)
+				}
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Creates or updates the teams in the given (already validated) roster, leaving fields that aren't part of the roster
+// format untouched.
+func applyRoster(database *model.Database, teams []rosterTeam) error {
+	for _, rosterTeam := range teams {
+		team, err := database.GetTeamById(rosterTeam.Number)
+		if err != nil {
+			return err
+		}
+		isNew := team == nil
+		if isNew {
+			team = &model.Team{Id: rosterTeam.Number}
+		}
+		team.Name = rosterTeam.Name
+		team.Nickname = rosterTeam.Nickname
+		team.City = rosterTeam.City
+		team.StateProv = rosterTeam.StateProv
+		team.Country = rosterTeam.Country
+		team.RookieYear = rosterTeam.RookieYear
+		team.RobotName = rosterTeam.RobotName
+		team.Accomplishments = rosterTeam.Accomplishments
+		if isNew {
+			err = database.CreateTeam(team)
+		} else {
+			err = database.UpdateTeam(team)
+		}
+		if err != nil {
+			return err
+		}
+
+		if rosterTeam.Avatar != "" {
+			avatarBytes, err := decodeRosterAvatar(rosterTeam.Avatar)
+			if err != nil {
+				return err
+			}
+			avatarPath := fmt.Sprintf("%s/%d.png", partner.AvatarsDir, team.Id)
+			if err = os.WriteFile(avatarPath, avatarBytes, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Converts the given team into a roster entry, including its avatar if one has been stored.
+func newRosterTeam(team *model.Team) rosterTeam {
+	rosterTeam := rosterTeam{
+		Number:          team.Id,
+		Name:            team.Name,
+		Nickname:        team.Nickname,
+		City:            team.City,
+		StateProv:       team.StateProv,
+		Country:         team.Country,
+		RookieYear:      team.RookieYear,
+		RobotName:       team.RobotName,
+		Accomplishments: team.Accomplishments,
+	}
+	if avatarBytes, err := os.ReadFile(fmt.Sprintf("%s/%d.png", partner.AvatarsDir, team.Id)); err == nil {
+		rosterTeam.Avatar = base64.StdEncoding.EncodeToString(avatarBytes)
+	}
+	return rosterTeam
+}
+
+// Writes the given roster out in CSV format.
+func writeRosterCsv(w io.Writer, teams []rosterTeam) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(rosterCsvHeaders); err != nil {
+		return err
+	}
+	for _, team := range teams {
+		rookieYear := ""
+		if team.RookieYear != 0 {
+			rookieYear = strconv.Itoa(team.RookieYear)
+		}
+		record := []string{
+			strconv.Itoa(team.Number),
+			team.Name,
+			team.Nickname,
+			team.City,
+			team.StateProv,
+			team.Country,
+			rookieYear,
+			team.RobotName,
+			team.Accomplishments,
+			team.Avatar,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
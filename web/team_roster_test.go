@@ -0,0 +1,133 @@
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/BotDogs4645/da/model"
+	"github.com/BotDogs4645/da/partner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRosterCsv(t *testing.T) {
+	teams, err := parseRoster([]byte("Nickname,Number,RookieYear\n\"Cheesy, Poofs\",254,1999\nSimbotics,1114,\n"))
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]rosterTeam{{Number: 254, Nickname: "Cheesy, Poofs", RookieYear: 1999}, {Number: 1114, Nickname: "Simbotics"}},
+		teams,
+	)
+
+	_, err = parseRoster([]byte("Number,Mascot\n254,Poofy\n"))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "unknown column 'Mascot' in CSV roster", err.Error())
+	}
+	_, err = parseRoster([]byte("Nickname\nThe Cheesy Poofs\n"))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "CSV roster is missing the 'Number' column", err.Error())
+	}
+	_, err = parseRoster([]byte("Number,RookieYear\n254,nineteen\n"))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "line 2: invalid rookie year 'nineteen'", err.Error())
+	}
+	_, err = parseRoster([]byte("  \n"))
+	assert.NotNil(t, err)
+}
+
+func TestParseRosterJson(t *testing.T) {
+	teams, err := parseRoster([]byte(`[{"Number": 254, "Nickname": "The Cheesy Poofs", "RobotName": "Barrage"}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []rosterTeam{{Number: 254, Nickname: "The Cheesy Poofs", RobotName: "Barrage"}}, teams)
+
+	_, err = parseRoster([]byte(`[{"Number": "254"}]`))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "could not parse JSON roster")
+	}
+}
+
+func TestValidateRoster(t *testing.T) {
+	existingTeams := map[int]model.Team{254: {Id: 254}}
+	teams := []rosterTeam{
+		{Number: 254, RookieYear: 1999, Avatar: testAvatar()},
+		{Number: 1114, RookieYear: 1800},
+		{Number: 1114},
+		{Number: -5},
+		{Number: 604, Avatar: "bm90IGEgcG5n"},
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"Team 1114 has invalid rookie year 1800.",
+			"Team 1114 appears more than once.",
+			"Invalid team number -5.",
+			"Team 604 has an invalid avatar: png: invalid format: not a PNG file.",
+		},
+		validateRoster(teams, existingTeams, true),
+	)
+
+	problems := validateRoster([]rosterTeam{{Number: 254}, {Number: 1114}}, existingTeams, false)
+	assert.Equal(t, []string{"Team 1114 can't be added once the qualification schedule has been generated."}, problems)
+	assert.Empty(t, validateRoster([]rosterTeam{{Number: 254}}, existingTeams, false))
+	assert.Equal(t, []string{"Roster does not contain any teams."}, validateRoster(nil, existingTeams, true))
+}
+
+func TestDiffRoster(t *testing.T) {
+	existingTeams := map[int]model.Team{
+		254:  {Id: 254, Nickname: "The Cheesy Poofs", RookieYear: 1999},
+		1114: {Id: 1114, Nickname: "Simbotics", RookieYear: 2003},
+	}
+	changes := diffRoster(
+		[]rosterTeam{
+			{Number: 254, Nickname: "The Cheesy Poofs", RookieYear: 1999},
+			{Number: 1114, Nickname: "Simbotics", RookieYear: 2003, RobotName: "Simbot", Avatar: testAvatar()},
+			{Number: 604, Nickname: "Quixilver"},
+		},
+		existingTeams,
+	)
+	if assert.Equal(t, 3, len(changes)) {
+		assert.False(t, changes[0].IsNew)
+		assert.Empty(t, changes[0].ChangedFields)
+		assert.False(t, changes[1].IsNew)
+		assert.Equal(t, []string{"RobotName", "Avatar"}, changes[1].ChangedFields)
+		assert.True(t, changes[2].IsNew)
+	}
+}
+
+func TestRosterRoundTrip(t *testing.T) {
+	web := setupTestWeb(t)
+	assert.Nil(t, os.MkdirAll(partner.AvatarsDir, 0755))
+	defer os.RemoveAll("static")
+
+	roster := []rosterTeam{
+		{Number: 254, Name: "NASA", Nickname: "The Cheesy Poofs", City: "San Jose", StateProv: "CA", Country: "USA",
+			RookieYear: 1999, RobotName: "Barrage", Accomplishments: "<p>2022 Chezy Champs - Winner</p>",
+			Avatar: testAvatar()},
+		{Number: 1114, Nickname: "Simbotics", City: "St. Catharines", StateProv: "ON", Country: "Canada"},
+	}
+	assert.Nil(t, applyRoster(web.arena.Database, roster))
+	team, _ := web.arena.Database.GetTeamById(254)
+	assert.Equal(t, "Barrage", team.RobotName)
+
+	// Check that exporting and re-parsing the roster yields the same data, avatar included.
+	var buffer bytes.Buffer
+	var exported []rosterTeam
+	teams, _ := web.arena.Database.GetAllTeams()
+	for _, team := range teams {
+		exported = append(exported, newRosterTeam(&team))
+	}
+	assert.Nil(t, writeRosterCsv(&buffer, exported))
+	parsed, err := parseRoster(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, roster, parsed)
+}
+
+// Returns a base64-encoded 1x1 PNG image for use as a team avatar.
+func testAvatar() string {
+	var buffer bytes.Buffer
+	png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	return base64.StdEncoding.EncodeToString(buffer.Bytes())
+}
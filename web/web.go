@@ -25,11 +25,12 @@ const (
 
 type Web struct {
 	arena           *field.Arena
+	eventLibrary    *model.EventLibrary
 	templateHelpers template.FuncMap
 }
 
 func NewWeb(arena *field.Arena) *Web {
-	web := &Web{arena: arena}
+	web := &Web{arena: arena, eventLibrary: model.NewEventLibrary(filepath.Join(model.BaseDir, model.EventsDir))}
 
 	// Helper functions that can be used inside templates.
 	web.templateHelpers = template.FuncMap{
@@ -71,18 +72,24 @@ func NewWeb(arena *field.Arena) *Web {
 
 // ServeWebInterface Starts the webserver and blocks, waiting on requests. Does not return until the application exits.
 func (web *Web) ServeWebInterface(port int) {
-	http.Handle("/static/", http.StripPrefix("/static/", addNoCacheHeader(http.FileServer(http.Dir("static/")))))
-	http.Handle("/", web.newHandler())
 	log.Printf("Serving HTTP requests on port %d", port)
 
 	// Start Server
-	err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	err := http.ListenAndServe(fmt.Sprintf(":%d", port), web.newServeMux())
 	if err != nil {
 		panic("its so jover")
 		return
 	}
 }
 
+// Returns a handler for the static resources and all the pages of the web interface.
+func (web *Web) newServeMux() *http.ServeMux {
+	serveMux := http.NewServeMux()
+	serveMux.Handle("/static/", http.StripPrefix("/static/", addNoCacheHeader(http.FileServer(http.Dir("static/")))))
+	serveMux.Handle("/", web.newHandler())
+	return serveMux
+}
+
 // Serves the root page of Cheesy Arena.
 func (web *Web) indexHandler(w http.ResponseWriter, r *http.Request) {
 	indexTemplate, err := web.parseFiles("templates/index.html", "templates/base.html")
@@ -141,14 +148,21 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/displays/field_monitor/websocket", web.fieldMonitorDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/logo", web.logoDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/logo/websocket", web.logoDisplayWebsocketHandler).Methods("GET")
+	router.HandleFunc("/displays/pit", web.pitDisplayHandler).Methods("GET")
+	router.HandleFunc("/displays/pit/websocket", web.pitDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/queueing", web.queueingDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/queueing/websocket", web.queueingDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/rankings", web.rankingsDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/rankings/websocket", web.rankingsDisplayWebsocketHandler).Methods("GET")
 	router.HandleFunc("/displays/twitch", web.twitchDisplayHandler).Methods("GET")
 	router.HandleFunc("/displays/twitch/websocket", web.twitchDisplayWebsocketHandler).Methods("GET")
+	router.HandleFunc("/https/ca.crt", web.caCertificateHandler).Methods("GET")
+	router.HandleFunc("/inspection", web.inspectionGetHandler).Methods("GET")
+	router.HandleFunc("/inspection/{id}", web.inspectionTeamGetHandler).Methods("GET")
+	router.HandleFunc("/inspection/{id}", web.inspectionTeamPostHandler).Methods("POST")
 	router.HandleFunc("/login", web.loginHandler).Methods("GET")
 	router.HandleFunc("/login", web.loginPostHandler).Methods("POST")
+	router.HandleFunc("/logout", web.logoutPostHandler).Methods("POST")
 	router.HandleFunc("/match_play", web.matchPlayHandler).Methods("GET")
 	router.HandleFunc("/scoring_panel", web.scoringPanelHandler).Methods("GET")
 	router.HandleFunc("/scoring_panel/websocket", web.scoringPanelWebSocketHandler).Methods("GET")
@@ -159,6 +173,8 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/match_review", web.matchReviewHandler).Methods("GET")
 	router.HandleFunc("/match_review/{matchId}/edit", web.matchReviewEditGetHandler).Methods("GET")
 	router.HandleFunc("/match_review/{matchId}/edit", web.matchReviewEditPostHandler).Methods("POST")
+	router.HandleFunc("/match_review/{matchId}/video", web.matchReviewVideoPostHandler).Methods("POST")
+	web.addPublicRoutes(router)
 	router.HandleFunc("/reports/csv/backups", web.backupTeamsCsvReportHandler).Methods("GET")
 	router.HandleFunc("/reports/csv/rankings", web.rankingsCsvReportHandler).Methods("GET")
 	router.HandleFunc("/reports/csv/schedule/{type}", web.scheduleCsvReportHandler).Methods("GET")
@@ -168,25 +184,47 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/reports/pdf/backups", web.backupsPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/bracket", web.bracketPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/coupons", web.couponsPdfReportHandler).Methods("GET")
+	router.HandleFunc("/reports/pdf/inspection", web.inspectionPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/rankings", web.rankingsPdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/schedule/{type}", web.schedulePdfReportHandler).Methods("GET")
 	router.HandleFunc("/reports/pdf/teams", web.teamsPdfReportHandler).Methods("GET")
+	router.HandleFunc("/setup/audit", web.auditGetHandler).Methods("GET")
+	router.HandleFunc("/setup/audit/csv", web.auditCsvHandler).Methods("GET")
 	router.HandleFunc("/setup/awards", web.awardsGetHandler).Methods("GET")
 	router.HandleFunc("/setup/awards", web.awardsPostHandler).Methods("POST")
 	router.HandleFunc("/setup/awards/publish", web.awardsPublishHandler).Methods("POST")
+	router.HandleFunc("/setup/backups", web.backupsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/backups/{filename}/download", web.backupDownloadHandler).Methods("GET")
+	router.HandleFunc("/setup/backups/{filename}/restore", web.backupRestorePostHandler).Methods("POST")
+	router.HandleFunc("/setup/consistency", web.consistencyGetHandler).Methods("GET")
+	router.HandleFunc("/setup/consistency/fix", web.consistencyFixPostHandler).Methods("POST")
+	router.HandleFunc("/setup/db/archive", web.saveArchiveHandler).Methods("GET")
 	router.HandleFunc("/setup/db/clear", web.clearDbHandler).Methods("POST")
 	router.HandleFunc("/setup/db/restore", web.restoreDbHandler).Methods("POST")
 	router.HandleFunc("/setup/db/save", web.saveDbHandler).Methods("GET")
 	router.HandleFunc("/setup/displays", web.displaysGetHandler).Methods("GET")
 	router.HandleFunc("/setup/displays/websocket", web.displaysWebsocketHandler).Methods("GET")
+	router.HandleFunc("/setup/events", web.eventsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/events/create", web.eventsCreatePostHandler).Methods("POST")
+	router.HandleFunc("/setup/events/import", web.eventsImportPostHandler).Methods("POST")
+	router.HandleFunc("/setup/events/{key}/archive", web.eventsArchivePostHandler).Methods("POST")
+	router.HandleFunc("/setup/events/{key}/clone", web.eventsClonePostHandler).Methods("POST")
+	router.HandleFunc("/setup/events/{key}/switch", web.eventsSwitchPostHandler).Methods("POST")
+	router.HandleFunc("/setup/events/{key}/unarchive", web.eventsUnarchivePostHandler).Methods("POST")
 	router.HandleFunc("/setup/field_testing", web.fieldTestingGetHandler).Methods("GET")
 	router.HandleFunc("/setup/field_testing/websocket", web.fieldTestingWebsocketHandler).Methods("GET")
+	router.HandleFunc("/setup/fields", web.fieldsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/fields", web.fieldsPostHandler).Methods("POST")
 	router.HandleFunc("/setup/lower_thirds", web.lowerThirdsGetHandler).Methods("GET")
 	router.HandleFunc("/setup/lower_thirds/websocket", web.lowerThirdsWebsocketHandler).Methods("GET")
+	router.HandleFunc("/setup/publishing", web.publishingGetHandler).Methods("GET")
+	router.HandleFunc("/setup/publishing/republish", web.publishingRepublishPostHandler).Methods("POST")
 	router.HandleFunc("/setup/schedule", web.scheduleGetHandler).Methods("GET")
 	router.HandleFunc("/setup/schedule/generate", web.scheduleGeneratePostHandler).Methods("POST")
 	router.HandleFunc("/setup/schedule/republish", web.scheduleRepublishPostHandler).Methods("POST")
 	router.HandleFunc("/setup/schedule/save", web.scheduleSavePostHandler).Methods("POST")
+	router.HandleFunc("/setup/sessions", web.sessionsGetHandler).Methods("GET")
+	router.HandleFunc("/setup/sessions/{id}/revoke", web.sessionRevokePostHandler).Methods("POST")
 	router.HandleFunc("/setup/settings", web.settingsGetHandler).Methods("GET")
 	router.HandleFunc("/setup/settings", web.settingsPostHandler).Methods("POST")
 	router.HandleFunc("/setup/sponsor_slides", web.sponsorSlidesGetHandler).Methods("GET")
@@ -198,8 +236,13 @@ func (web *Web) newHandler() http.Handler {
 	router.HandleFunc("/setup/teams/{id}/edit", web.teamEditPostHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/clear", web.teamsClearHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/generate_wpa_keys", web.teamsGenerateWpaKeysHandler).Methods("GET")
+	router.HandleFunc("/setup/teams/import", web.teamsImportPostHandler).Methods("POST")
+	router.HandleFunc("/setup/teams/import/apply", web.teamsImportApplyHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/publish", web.teamsPublishHandler).Methods("POST")
 	router.HandleFunc("/setup/teams/refresh", web.teamsRefreshHandler).Methods("GET")
+	router.HandleFunc("/setup/teams/prefetch", web.teamsPrefetchHandler).Methods("POST")
+	router.HandleFunc("/setup/users", web.usersGetHandler).Methods("GET")
+	router.HandleFunc("/setup/users", web.usersPostHandler).Methods("POST")
 	return router
 }
 